@@ -1,20 +1,26 @@
 package main
 
 import (
-	"fmt"
 	"log"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/joho/godotenv"
 	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/config"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/contracts"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/events"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/http/routes"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/prices"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/providers"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/security"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/stream"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/webhooks"
 	"github.com/robfig/cron/v3"
 )
 
@@ -43,6 +49,106 @@ func main() {
 		// Continue without cache if it fails
 	}
 
+	// Supported chains
+	chainRegistry, err := chains.Init(conf.ChainsConfigPath)
+	if err != nil {
+		logger.Fatalf("Error loading chain registry: %v", err)
+	}
+
+	// Known protocol contracts, used to classify transactions
+	contractRegistry, err := contracts.Init(conf.KnownContractsPath)
+	if err != nil {
+		logger.Fatalf("Error loading known contracts: %v", err)
+	}
+
+	// Index the balance history before refreshes start appending to it
+	if err := repositories.NewSnapshotRepository(mongoClient, conf.MongoDBName).EnsureIndexes(); err != nil {
+		logger.Warnf("Could not create balance snapshot indexes: %v", err)
+	}
+
+	if err := repositories.NewTransactionRepository(mongoClient, conf.MongoDBName).EnsureIndexes(); err != nil {
+		logger.Warnf("Could not create transaction indexes: %v", err)
+	}
+
+	alertRepo := repositories.NewAlertRepository(mongoClient, conf.MongoDBName)
+	if err := alertRepo.EnsureIndexes(); err != nil {
+		logger.Warnf("Could not create alert indexes: %v", err)
+	}
+
+	refreshRunRepo := repositories.NewRefreshRunRepository(mongoClient, conf.MongoDBName)
+	if err := refreshRunRepo.EnsureIndexes(); err != nil {
+		logger.Warnf("Could not create refresh run indexes: %v", err)
+	}
+
+	webhookRepo := repositories.NewWebhookRepository(mongoClient, conf.MongoDBName)
+	if err := webhookRepo.EnsureIndexes(); err != nil {
+		logger.Warnf("Could not create webhook indexes: %v", err)
+	}
+
+	streamTicketRepo := repositories.NewStreamTicketRepository(mongoClient, conf.MongoDBName)
+	if err := streamTicketRepo.EnsureIndexes(); err != nil {
+		logger.Warnf("Could not create stream ticket indexes: %v", err)
+	}
+
+	// EVM addresses stored before they were EIP-55 checksummed would miss every lookup
+	var evmChains []string
+	for _, chain := range chainRegistry.All() {
+		if chain.AddressFormat == addresses.FormatEVM {
+			evmChains = append(evmChains, chain.Key)
+		}
+	}
+	if n, err := repositories.NewWalletRepository(mongoClient, conf.MongoDBName).MigrateEVMAddresses(evmChains); err != nil {
+		logger.Warnf("Could not checksum stored wallet addresses: %v", err)
+	} else if n > 0 {
+		logger.Infof("Checksummed %d stored EVM wallet addresses", n)
+	}
+	if n, err := repositories.NewBalanceRepository(mongoClient, conf.MongoDBName).MigrateEVMAddresses(evmChains); err != nil {
+		logger.Warnf("Could not checksum stored balance addresses: %v", err)
+	} else if n > 0 {
+		logger.Infof("Checksummed %d stored EVM balance addresses", n)
+	}
+
+	// Balance providers, routed per chain
+	balanceProvider, err := providers.NewRegistryFromConfig(conf, chainRegistry, logger)
+	if err != nil {
+		logger.Fatalf("Error configuring balance providers: %v", err)
+	}
+
+	// Transaction history providers, routed per chain
+	transactionProvider, err := providers.NewTransactionRegistryFromConfig(conf, chainRegistry, logger)
+	if err != nil {
+		logger.Fatalf("Error configuring transaction providers: %v", err)
+	}
+
+	// Price oracle for USD valuations
+	priceOracle, err := prices.NewOracleFromConfig(conf, redisClient, logger)
+	if err != nil {
+		logger.Fatalf("Error configuring price sources: %v", err)
+	}
+
+	// Balance change events, published by refreshes for alerts, webhooks and streams
+	eventBus := events.NewBus(logger)
+
+	// Scheduled jobs take a lease so only one replica runs each at a time
+	leaseRepo := repositories.NewLeaseRepository(mongoClient, conf.MongoDBName)
+
+	// Alert rules are evaluated on the events of every refresh, whether cron or user triggered
+	alertService := services.NewAlertService(
+		logger, alertRepo, repositories.NewWalletRepository(mongoClient, conf.MongoDBName), priceOracle, eventBus, leaseRepo,
+	)
+	alertService.Start()
+
+	// Webhook deliveries are queued from the same events and sent by the delivery job below
+	webhookService := services.NewWebhookService(
+		logger, webhookRepo, repositories.NewWalletRepository(mongoClient, conf.MongoDBName),
+		webhooks.NewSender(), eventBus, conf.WebhookMaxAttempts,
+	)
+	webhookService.Start()
+
+	// Stream refreshes to connected clients; Redis carries each replica's events to the others
+	streamHub := stream.NewHub(logger)
+	events.NewFanout(eventBus, redisClient, logger).Start(streamHub.Dispatch, stream.EventTypes...)
+
 	// Create a new instance of Fiber
 	app := fiber.New()
 
@@ -52,34 +158,96 @@ func main() {
 	// Rate Limiting middleware
 	app.Use(security.NewRateLimiter())
 
-	// JWT verification middleware
-	app.Use(security.NewJWTMiddleware(conf.AuthServiceURL))
+	// JWT verification middleware; event streams authenticate with the stream tickets it redeems
+	app.Use(security.NewJWTMiddleware(conf.AuthServiceURL, services.NewStreamService(
+		logger, repositories.NewWalletRepository(mongoClient, conf.MongoDBName), streamTicketRepo, streamHub,
+	)))
+
+	// Scheduled balance refresh of every tracked wallet, with its run metrics on the admin API
+	refreshWalletRepo := repositories.NewWalletRepository(mongoClient, conf.MongoDBName)
+	balanceRefresher := services.NewBalanceRefresher(
+		logger,
+		refreshWalletRepo,
+		refreshRunRepo,
+		leaseRepo,
+		services.NewWalletService(
+			logger,
+			refreshWalletRepo,
+			repositories.NewBalanceRepository(mongoClient, conf.MongoDBName),
+			repositories.NewSnapshotRepository(mongoClient, conf.MongoDBName),
+			balanceProvider, priceOracle, redisClient, eventBus,
+		),
+		balanceProvider,
+		conf.RefreshWorkers,
+		conf.RefreshDeadline,
+		conf.ProviderRateLimits,
+	)
 
 	// Set up routes
-	routes.SetupRoutes(app, logger, mongoClient, redisClient, conf)
+	routes.SetupRoutes(app, logger, mongoClient, redisClient, balanceProvider, priceOracle, transactionProvider, chainRegistry, eventBus, streamHub, balanceRefresher, conf)
 
-	// Scheduler to update wallets periodically
 	c := cron.New()
-	// "0 * * * *" => every hour
-	c.AddFunc("@every 30m", func() {
-		repo := repositories.NewWalletRepository(mongoClient, conf.MongoDBName)
-		balanceRepo := repositories.NewBalanceRepository(mongoClient, conf.MongoDBName)
-		walletService := services.NewWalletService(logger, repo, balanceRepo, redisClient)
-
-		wallets, err := repo.GetAllWallets()
-		if err != nil {
-			logger.Errorf("Cron job error: %v", err)
-			return
+
+	// Refresh every tracked wallet with a bounded worker pool; the refresher itself skips runs
+	// that would overlap, here or on another replica, so they show up in the run metrics
+	if _, err := c.AddFunc(conf.BalanceRefreshCron, func() { balanceRefresher.Run() }); err != nil {
+		logger.Fatalf("Invalid BALANCE_REFRESH_CRON '%s': %v", conf.BalanceRefreshCron, err)
+	}
+
+	// Roll old balance snapshots into hourly and daily records
+	compactor := services.NewSnapshotCompactor(
+		logger,
+		repositories.NewSnapshotRepository(mongoClient, conf.MongoDBName),
+		leaseRepo,
+		conf.SnapshotRawRetention,
+		conf.SnapshotHourlyRetention,
+	)
+	compactionJob := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(func() {
+		if err := compactor.Run(); err != nil {
+			logger.Errorf("Snapshot compaction error: %v", err)
+		}
+	}))
+	if _, err := c.AddJob(conf.SnapshotCompactionCron, compactionJob); err != nil {
+		logger.Fatalf("Invalid SNAPSHOT_COMPACTION_CRON '%s': %v", conf.SnapshotCompactionCron, err)
+	}
+
+	// Ingest new transfers of every tracked wallet from its stored cursor
+	transactionService := services.NewTransactionService(
+		logger,
+		repositories.NewWalletRepository(mongoClient, conf.MongoDBName),
+		repositories.NewTransactionRepository(mongoClient, conf.MongoDBName),
+		transactionProvider,
+		contractRegistry,
+		leaseRepo,
+	)
+	transactionSyncJob := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(func() {
+		if err := transactionService.SyncAll(); err != nil {
+			logger.Errorf("Transaction sync error: %v", err)
+		}
+	}))
+	if _, err := c.AddJob(conf.TransactionSyncCron, transactionSyncJob); err != nil {
+		logger.Fatalf("Invalid TRANSACTION_SYNC_CRON '%s': %v", conf.TransactionSyncCron, err)
+	}
+
+	// Price_change rules also fire on price moves between balance refreshes
+	priceAlertJob := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(func() {
+		if err := alertService.EvaluatePriceRules(); err != nil {
+			logger.Errorf("Price alert evaluation error: %v", err)
 		}
+	}))
+	if _, err := c.AddJob(conf.PriceAlertCron, priceAlertJob); err != nil {
+		logger.Fatalf("Invalid PRICE_ALERT_CRON '%s': %v", conf.PriceAlertCron, err)
+	}
 
-		for _, w := range wallets {
-			addrParam := fmt.Sprintf("%s.%s", w.Blockchain, w.Address)
-			if _, err := walletService.FetchAndStoreBalance(w.UserID, addrParam); err != nil {
-				logger.Errorf("Cron update for wallet %s: %v", addrParam, err)
-			}
-			time.Sleep(1 * time.Second)
+	// Send due webhook deliveries, first attempts and retries alike
+	webhookDeliveryJob := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(func() {
+		if err := webhookService.DeliverDue(); err != nil {
+			logger.Errorf("Webhook delivery error: %v", err)
 		}
-	})
+	}))
+	if _, err := c.AddJob(conf.WebhookDeliveryCron, webhookDeliveryJob); err != nil {
+		logger.Fatalf("Invalid WEBHOOK_DELIVERY_CRON '%s': %v", conf.WebhookDeliveryCron, err)
+	}
 	c.Start()
 
 	// Start the server
@@ -0,0 +1,568 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/events"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/prices"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxAlertRules          = 100
+	maxAlertRuleNameLength = 100
+	maxAlertPercent        = 1000
+	defaultAlertCooldown   = time.Hour
+	minAlertCooldown       = time.Minute
+	maxAlertCooldown       = 30 * 24 * time.Hour
+	defaultAlertLimit      = 50
+	maxAlertLimit          = 500
+
+	// alertPriceWindow is how far back a price_change rule compares prices
+	alertPriceWindow = 24 * time.Hour
+	// alertPricingTimeout bounds the historical price lookup of one evaluation
+	alertPricingTimeout = 30 * time.Second
+
+	// priceAlertLeaseName is the job lease that keeps scheduled price evaluations to one replica
+	priceAlertLeaseName = "price-alerts"
+	priceAlertLeaseTTL  = 10 * time.Minute
+)
+
+var (
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+	ErrInvalidAlertRule  = errors.New("invalid alert rule")
+)
+
+type IAlertService interface {
+	CreateAlertRule(userID string, input AlertRuleInput) (*entities.AlertRule, error)
+	GetAlertRules(userID string) ([]entities.AlertRule, error)
+	GetAlertRule(userID, id string) (*entities.AlertRule, error)
+	UpdateAlertRule(userID, id string, update AlertRuleUpdate) (*entities.AlertRule, error)
+	DeleteAlertRule(userID, id string) error
+	GetAlerts(userID string, limit int) ([]entities.Alert, error)
+}
+
+// AlertRuleInput describes a new rule. Wallet is an address param of a wallet the user tracks;
+// Threshold applies to value_below rules and Percent to price_change rules.
+type AlertRuleInput struct {
+	Name            string
+	Type            string
+	Wallet          string
+	Token           string
+	Threshold       entities.USD
+	Percent         float64
+	CooldownSeconds int64
+	Enabled         *bool
+}
+
+// AlertRuleUpdate holds the editable fields of a rule; nil fields are left unchanged.
+// The type and wallet of a rule are fixed.
+type AlertRuleUpdate struct {
+	Name            *string
+	Token           *string
+	Threshold       *entities.USD
+	Percent         *float64
+	CooldownSeconds *int64
+	Enabled         *bool
+}
+
+// AlertService manages alert rules and evaluates them against the events of every balance
+// refresh, and price_change rules on a schedule as well since prices move between refreshes.
+// A rule raises at most one round of alerts per cooldown, each alert is stored once under its
+// dedup key, and value_below rules only fire when the value crosses the threshold.
+type AlertService struct {
+	logger      *logs.Logger
+	alertRepo   repositories.IAlertRepository
+	walletRepo  repositories.IWalletRepository
+	priceOracle prices.IPriceOracle
+	eventBus    events.IEventBus
+	lease       jobLease
+}
+
+func NewAlertService(
+	logger *logs.Logger,
+	alertRepo repositories.IAlertRepository,
+	walletRepo repositories.IWalletRepository,
+	priceOracle prices.IPriceOracle,
+	eventBus events.IEventBus,
+	leaseRepo repositories.ILeaseRepository,
+) *AlertService {
+	return &AlertService{
+		logger:      logger,
+		alertRepo:   alertRepo,
+		walletRepo:  walletRepo,
+		priceOracle: priceOracle,
+		eventBus:    eventBus,
+		lease:       jobLease{repo: leaseRepo, name: priceAlertLeaseName, ttl: priceAlertLeaseTTL},
+	}
+}
+
+// CreateAlertRule adds a rule on one of the user's tracked wallets
+func (as *AlertService) CreateAlertRule(userID string, input AlertRuleInput) (*entities.AlertRule, error) {
+	count, err := as.alertRepo.CountRulesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAlertRules {
+		return nil, fmt.Errorf("%w: at most %d alert rules per user", ErrInvalidAlertRule, maxAlertRules)
+	}
+
+	bc, addr, err := usecases.ParseBlockchainAndAddress(input.Wallet)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAlertRule, err)
+	}
+	addr, err = NormalizeAddress(bc, addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAlertRule, err)
+	}
+	wallet, err := as.walletRepo.GetWallet(userID, bc, addr)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, fmt.Errorf("%w: wallet %s.%s is not tracked", ErrInvalidAlertRule, bc, addr)
+	}
+
+	rule := &entities.AlertRule{
+		UserID:          userID,
+		Name:            strings.TrimSpace(input.Name),
+		Type:            strings.ToLower(strings.TrimSpace(input.Type)),
+		Blockchain:      bc,
+		Address:         addr,
+		Token:           strings.TrimSpace(input.Token),
+		Threshold:       input.Threshold,
+		Percent:         input.Percent,
+		CooldownSeconds: input.CooldownSeconds,
+		Enabled:         input.Enabled == nil || *input.Enabled,
+		CreatedAt:       time.Now(),
+	}
+	if rule.CooldownSeconds == 0 {
+		rule.CooldownSeconds = int64(defaultAlertCooldown / time.Second)
+	}
+	if err := validateAlertRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := as.alertRepo.SaveRule(rule); err != nil {
+		as.logger.Errorf("Error saving alert rule: %v", err)
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetAlertRules returns the rules of a user
+func (as *AlertService) GetAlertRules(userID string) ([]entities.AlertRule, error) {
+	rules, err := as.alertRepo.GetRulesByUser(userID)
+	if err != nil {
+		as.logger.Errorf("Error fetching alert rules: %v", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetAlertRule returns a single rule of the user
+func (as *AlertService) GetAlertRule(userID, id string) (*entities.AlertRule, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrAlertRuleNotFound
+	}
+	rule, err := as.alertRepo.GetRule(userID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrAlertRuleNotFound
+	}
+	return rule, nil
+}
+
+// UpdateAlertRule edits a rule. Changing its condition re-arms it, so a value_below rule whose
+// threshold moved fires again if the wallet is already below the new one.
+func (as *AlertService) UpdateAlertRule(userID, id string, update AlertRuleUpdate) (*entities.AlertRule, error) {
+	rule, err := as.GetAlertRule(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		rule.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Token != nil {
+		rule.Token = strings.TrimSpace(*update.Token)
+		rule.Active = false
+	}
+	if update.Threshold != nil {
+		rule.Threshold = *update.Threshold
+		rule.Active = false
+	}
+	if update.Percent != nil {
+		rule.Percent = *update.Percent
+		rule.Active = false
+	}
+	if update.CooldownSeconds != nil {
+		rule.CooldownSeconds = *update.CooldownSeconds
+	}
+	if update.Enabled != nil {
+		if *update.Enabled && !rule.Enabled {
+			rule.Active = false
+		}
+		rule.Enabled = *update.Enabled
+	}
+	if err := validateAlertRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := as.alertRepo.SaveRule(rule); err != nil {
+		as.logger.Errorf("Error saving alert rule: %v", err)
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteAlertRule removes a rule; the alerts it raised stay in the history
+func (as *AlertService) DeleteAlertRule(userID, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrAlertRuleNotFound
+	}
+	deleted, err := as.alertRepo.DeleteRule(userID, objectID)
+	if err != nil {
+		as.logger.Errorf("Error deleting alert rule: %v", err)
+		return err
+	}
+	if !deleted {
+		return ErrAlertRuleNotFound
+	}
+	return nil
+}
+
+// GetAlerts returns the user's alert history, newest first
+func (as *AlertService) GetAlerts(userID string, limit int) ([]entities.Alert, error) {
+	if limit < 1 {
+		limit = defaultAlertLimit
+	}
+	if limit > maxAlertLimit {
+		limit = maxAlertLimit
+	}
+	alerts, err := as.alertRepo.GetAlerts(userID, int64(limit))
+	if err != nil {
+		as.logger.Errorf("Error fetching alerts: %v", err)
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// validateAlertRule checks a rule and clears the fields its type does not use
+func validateAlertRule(rule *entities.AlertRule) error {
+	if len(rule.Name) > maxAlertRuleNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidAlertRule, maxAlertRuleNameLength)
+	}
+	switch rule.Type {
+	case entities.AlertValueBelow:
+		if rule.Threshold.Cmp(entities.USD{}) <= 0 {
+			return fmt.Errorf("%w: value_below rules need a positive threshold", ErrInvalidAlertRule)
+		}
+		rule.Token = ""
+		rule.Percent = 0
+	case entities.AlertBalanceChange:
+		rule.Threshold = entities.USD{}
+		rule.Percent = 0
+	case entities.AlertPriceChange:
+		if rule.Percent <= 0 || rule.Percent > maxAlertPercent {
+			return fmt.Errorf("%w: price_change rules need a percent between 0 and %d", ErrInvalidAlertRule, maxAlertPercent)
+		}
+		rule.Threshold = entities.USD{}
+	default:
+		return fmt.Errorf("%w: type must be one of %s", ErrInvalidAlertRule, strings.Join(entities.AlertTypes, ", "))
+	}
+
+	cooldown := time.Duration(rule.CooldownSeconds) * time.Second
+	if cooldown < minAlertCooldown || cooldown > maxAlertCooldown {
+		return fmt.Errorf("%w: cooldownSeconds must be between %d and %d", ErrInvalidAlertRule,
+			int64(minAlertCooldown/time.Second), int64(maxAlertCooldown/time.Second))
+	}
+	return nil
+}
+
+// Start evaluates rules on the events of every balance refresh until the returned function is called.
+// Price moves between refreshes are caught by EvaluatePriceRules.
+func (as *AlertService) Start() func() {
+	return as.eventBus.Subscribe(as.handleEvent,
+		entities.EventBalancesRefreshed,
+		entities.EventTokenAdded,
+		entities.EventTokenRemoved,
+		entities.EventBalanceIncreased,
+		entities.EventBalanceDecreased,
+	)
+}
+
+func (as *AlertService) handleEvent(event entities.DomainEvent) {
+	wallet := event.Wallet()
+	switch e := event.(type) {
+	case entities.BalancesRefreshed:
+		rules, err := as.alertRepo.GetEnabledRulesByWallet(wallet.Blockchain, wallet.Address,
+			entities.AlertValueBelow, entities.AlertPriceChange)
+		if err != nil {
+			as.logger.Errorf("Error loading alert rules of %s.%s: %v", wallet.Blockchain, wallet.Address, err)
+			return
+		}
+		var priceRules []entities.AlertRule
+		for _, rule := range rules {
+			if rule.Type == entities.AlertValueBelow {
+				as.evaluateValue(rule, e)
+			} else {
+				priceRules = append(priceRules, rule)
+			}
+		}
+		if len(priceRules) > 0 {
+			as.evaluatePrices(priceRules, e.Blockchain, e.Balances, e.Timestamp)
+		}
+
+	case entities.TokenAdded:
+		as.evaluateBalance(e.WalletEvent, e.Balance.Asset,
+			fmt.Sprintf("Received %s %s, a new token in the wallet", e.Balance.Amount.Format(e.Balance.Asset.Decimals), e.Balance.Asset.Symbol))
+	case entities.TokenRemoved:
+		as.evaluateBalance(e.WalletEvent, e.Previous.Asset,
+			fmt.Sprintf("%s balance is now 0, was %s", e.Previous.Asset.Symbol, e.Previous.Amount.Format(e.Previous.Asset.Decimals)))
+	case entities.BalanceChanged:
+		verb := "increased"
+		if e.Type == entities.EventBalanceDecreased {
+			verb = "decreased"
+		}
+		as.evaluateBalance(e.WalletEvent, e.Asset,
+			fmt.Sprintf("%s balance %s from %s to %s", e.Asset.Symbol, verb,
+				e.PreviousAmount.Format(e.Asset.Decimals), e.Amount.Format(e.Asset.Decimals)))
+	}
+}
+
+// evaluateValue fires a value_below rule when the wallet value crosses below the threshold,
+// and re-arms it once the value is back above. A total missing held tokens the oracle could not
+// price says nothing about the threshold, so such refreshes leave the rule as it is.
+func (as *AlertService) evaluateValue(rule entities.AlertRule, e entities.BalancesRefreshed) {
+	for _, b := range e.Balances {
+		if b.Unpriced && !b.Amount.IsZero() {
+			return
+		}
+	}
+
+	below := e.TotalUSD.Cmp(rule.Threshold) < 0
+	if below == rule.Active {
+		return
+	}
+
+	var triggeredAt *time.Time
+	if below && !coolingDown(rule, e.Timestamp) {
+		message := fmt.Sprintf("Wallet value $%s dropped below $%s", e.TotalUSD, rule.Threshold)
+		dedupKey := fmt.Sprintf("%s:%d", rule.ID.Hex(), e.Timestamp.UnixNano())
+		if as.raise(rule, "", message, dedupKey, e.Timestamp) {
+			triggeredAt = &e.Timestamp
+		}
+	}
+	if err := as.alertRepo.SetRuleState(rule.ID, below, triggeredAt); err != nil {
+		as.logger.Errorf("Error saving state of alert rule %s: %v", rule.ID.Hex(), err)
+	}
+}
+
+// EvaluatePriceRules checks every enabled price_change rule against the current prices of the
+// tokens its wallet holds. One replica runs it at a time.
+func (as *AlertService) EvaluatePriceRules() error {
+	lease, acquired, err := as.lease.acquire()
+	if err != nil {
+		return fmt.Errorf("could not take the price alert lease: %w", err)
+	}
+	if !acquired {
+		if lease != nil {
+			as.logger.Infof("Price alert evaluation skipped, %s holds the lease until %s", lease.Holder, lease.ExpiresAt.Format(time.RFC3339))
+		}
+		return nil
+	}
+	defer func() {
+		if err := as.lease.release(); err != nil {
+			as.logger.Warnf("Price alert evaluation could not release its lease: %v", err)
+		}
+	}()
+
+	rules, err := as.alertRepo.GetEnabledRules(entities.AlertPriceChange)
+	if err != nil {
+		return err
+	}
+	byWallet := make(map[entities.WalletRef][]entities.AlertRule)
+	for _, rule := range rules {
+		ref := entities.WalletRef{Blockchain: rule.Blockchain, Address: rule.Address}
+		byWallet[ref] = append(byWallet[ref], rule)
+	}
+
+	for ref, walletRules := range byWallet {
+		// Every user tracking the wallet stores the same balances; any rule's owner will do
+		wallet, err := as.walletRepo.GetWallet(walletRules[0].UserID, ref.Blockchain, ref.Address)
+		if err != nil {
+			as.logger.Errorf("Error loading wallet %s.%s for price alerts: %v", ref.Blockchain, ref.Address, err)
+			continue
+		}
+		if wallet == nil || len(wallet.Balances) == 0 {
+			continue
+		}
+
+		wallets := []entities.Wallet{*wallet}
+		ctx, cancel := context.WithTimeout(context.Background(), alertPricingTimeout)
+		err = as.priceOracle.PriceWallets(ctx, wallets)
+		cancel()
+		if err != nil {
+			as.logger.Errorf("Error pricing wallet %s.%s for price alerts: %v", ref.Blockchain, ref.Address, err)
+			continue
+		}
+		as.evaluatePrices(walletRules, ref.Blockchain, wallets[0].Balances, time.Now())
+	}
+	return nil
+}
+
+// evaluatePrices compares the current price of each held token with its price a day earlier.
+// A token that keeps moving raises at most one alert per rule and UTC day.
+func (as *AlertService) evaluatePrices(rules []entities.AlertRule, blockchain string, balances []entities.Balance, at time.Time) {
+	since := at.Add(-alertPriceWindow)
+	keys := make([]prices.HistoricalKey, 0, len(balances))
+	for _, b := range balances {
+		if k, ok := prices.NewHistoricalKey(blockchain, b.Asset, since); ok && !b.Asset.USDPrice.IsZero() {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), alertPricingTimeout)
+	defer cancel()
+	quotes, err := as.priceOracle.GetHistoricalPrices(ctx, keys)
+	if err != nil {
+		as.logger.Errorf("Error reading historical prices for alerts: %v", err)
+		return
+	}
+
+	day := at.UTC().Format("2006-01-02")
+	for _, rule := range rules {
+		if coolingDown(rule, at) {
+			continue
+		}
+		fired := false
+		for _, b := range balances {
+			if !matchesToken(rule, b.Asset) || b.Asset.USDPrice.IsZero() {
+				continue
+			}
+			k, ok := prices.NewHistoricalKey(blockchain, b.Asset, since)
+			if !ok {
+				continue
+			}
+			then, ok := quotes[k]
+			if !ok || then <= 0 {
+				continue
+			}
+			change := (b.Asset.USDPrice.Float64() - then) / then * 100
+			if math.Abs(change) < rule.Percent {
+				continue
+			}
+			message := fmt.Sprintf("%s moved %+.2f%% in 24h to $%s", b.Asset.Symbol, change, b.Asset.USDPrice)
+			dedupKey := fmt.Sprintf("%s:%s:%s", rule.ID.Hex(), usecases.AssetKey(b.Asset), day)
+			if as.raise(rule, b.Asset.Symbol, message, dedupKey, at) {
+				fired = true
+			}
+		}
+		if fired {
+			if err := as.alertRepo.SetRuleState(rule.ID, rule.Active, &at); err != nil {
+				as.logger.Errorf("Error saving state of alert rule %s: %v", rule.ID.Hex(), err)
+			}
+		}
+	}
+}
+
+// evaluateBalance fires the balance_change rules of the wallet that match the asset
+func (as *AlertService) evaluateBalance(e entities.WalletEvent, asset entities.Asset, message string) {
+	rules, err := as.alertRepo.GetEnabledRulesByWallet(e.Blockchain, e.Address, entities.AlertBalanceChange)
+	if err != nil {
+		as.logger.Errorf("Error loading alert rules of %s.%s: %v", e.Blockchain, e.Address, err)
+		return
+	}
+	for _, rule := range rules {
+		if !matchesToken(rule, asset) || coolingDown(rule, e.Timestamp) {
+			continue
+		}
+		dedupKey := fmt.Sprintf("%s:%s:%d", rule.ID.Hex(), usecases.AssetKey(asset), e.Timestamp.UnixNano())
+		if as.raise(rule, asset.Symbol, message, dedupKey, e.Timestamp) {
+			if err := as.alertRepo.SetRuleState(rule.ID, rule.Active, &e.Timestamp); err != nil {
+				as.logger.Errorf("Error saving state of alert rule %s: %v", rule.ID.Hex(), err)
+			}
+		}
+	}
+}
+
+// raise stores an alert and announces it, and reports whether it was new. Rules on wallets the
+// owner no longer tracks stay silent.
+func (as *AlertService) raise(rule entities.AlertRule, token, message, dedupKey string, at time.Time) bool {
+	wallet, err := as.walletRepo.GetWallet(rule.UserID, rule.Blockchain, rule.Address)
+	if err != nil || wallet == nil {
+		return false
+	}
+
+	alert := entities.Alert{
+		RuleID:      rule.ID,
+		UserID:      rule.UserID,
+		RuleName:    rule.Name,
+		Type:        rule.Type,
+		Blockchain:  rule.Blockchain,
+		Address:     rule.Address,
+		Token:       token,
+		Message:     message,
+		DedupKey:    dedupKey,
+		TriggeredAt: at,
+	}
+	created, err := as.alertRepo.SaveAlert(&alert)
+	if err != nil {
+		as.logger.Errorf("Error saving alert of rule %s: %v", rule.ID.Hex(), err)
+		return false
+	}
+	if !created {
+		return false
+	}
+
+	as.logger.Infof("Alert rule %s of user %s fired: %s", rule.ID.Hex(), rule.UserID, message)
+	as.eventBus.Publish(entities.AlertTriggered{
+		WalletEvent: entities.WalletEvent{
+			Type:       entities.EventAlertTriggered,
+			Blockchain: rule.Blockchain,
+			Address:    rule.Address,
+			Timestamp:  at,
+		},
+		UserID: rule.UserID,
+		Alert:  alert,
+	})
+	return true
+}
+
+// coolingDown reports whether the rule fired less than its cooldown before at. Alerts of the
+// refresh that last fired it, which share its timestamp, are not held back.
+func coolingDown(rule entities.AlertRule, at time.Time) bool {
+	if rule.LastTriggeredAt == nil {
+		return false
+	}
+	// Stored times keep millisecond precision
+	at = at.Truncate(time.Millisecond)
+	last := rule.LastTriggeredAt.Truncate(time.Millisecond)
+	return at.After(last) && at.Before(last.Add(time.Duration(rule.CooldownSeconds)*time.Second))
+}
+
+// matchesToken reports whether the rule applies to an asset, by symbol or contract
+func matchesToken(rule entities.AlertRule, asset entities.Asset) bool {
+	if rule.Token == "" {
+		return true
+	}
+	return strings.EqualFold(rule.Token, asset.Symbol) ||
+		(asset.Address != "" && strings.EqualFold(rule.Token, asset.Address))
+}
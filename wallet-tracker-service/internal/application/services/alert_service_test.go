@@ -0,0 +1,141 @@
+package services
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/events"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ruleState struct {
+	active    bool
+	triggered bool
+}
+
+// fakeAlertRepo records rule state changes and saved alerts; other methods are not used here
+type fakeAlertRepo struct {
+	repositories.IAlertRepository
+	states []ruleState
+	alerts []entities.Alert
+}
+
+func (r *fakeAlertRepo) SetRuleState(id primitive.ObjectID, active bool, triggeredAt *time.Time) error {
+	r.states = append(r.states, ruleState{active: active, triggered: triggeredAt != nil})
+	return nil
+}
+
+func (r *fakeAlertRepo) SaveAlert(alert *entities.Alert) (bool, error) {
+	r.alerts = append(r.alerts, *alert)
+	return true, nil
+}
+
+// trackedWalletRepo tracks every wallet it is asked about
+type trackedWalletRepo struct {
+	repositories.IWalletRepository
+}
+
+func (trackedWalletRepo) GetWallet(userID, blockchain, address string) (*entities.Wallet, error) {
+	return &entities.Wallet{UserID: userID, Blockchain: blockchain, Address: address}, nil
+}
+
+func TestCoolingDown(t *testing.T) {
+	last := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	rule := entities.AlertRule{CooldownSeconds: 3600, LastTriggeredAt: &last}
+
+	tests := []struct {
+		name string
+		rule entities.AlertRule
+		at   time.Time
+		want bool
+	}{
+		{"never fired", entities.AlertRule{CooldownSeconds: 3600}, last, false},
+		{"same refresh", rule, last, false},
+		{"same refresh after the stored millisecond", rule, last.Add(500 * time.Microsecond), false},
+		{"a millisecond later", rule, last.Add(time.Millisecond), true},
+		{"just inside the cooldown", rule, last.Add(time.Hour - time.Millisecond), true},
+		{"cooldown over", rule, last.Add(time.Hour), false},
+		{"before the last alert", rule, last.Add(-time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := coolingDown(tt.rule, tt.at); got != tt.want {
+				t.Errorf("coolingDown = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateValue(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-10 * time.Minute)
+	held := func(unpriced bool, amount int64) entities.Balance {
+		b := entities.NewBalance(entities.Asset{Symbol: "ETH", Decimals: 18}, entities.NewRawAmount(big.NewInt(amount)))
+		b.Unpriced = unpriced
+		return b
+	}
+
+	tests := []struct {
+		name       string
+		active     bool
+		lastFired  *time.Time
+		total      float64
+		balances   []entities.Balance
+		wantAlert  bool
+		wantStates []ruleState
+	}{
+		{name: "crosses below", total: 50, wantAlert: true, wantStates: []ruleState{{active: true, triggered: true}}},
+		{name: "stays below", active: true, total: 50},
+		{name: "at the threshold is not below", total: 100},
+		{name: "stays above", total: 150},
+		{name: "back above re-arms", active: true, total: 150, wantStates: []ruleState{{active: false}}},
+		{
+			// The crossing is recorded, so the rule does not fire later while the value stays below
+			name: "crosses below while cooling down", lastFired: &recent, total: 50,
+			wantStates: []ruleState{{active: true}},
+		},
+		{name: "held token unpriced", total: 0, balances: []entities.Balance{held(true, 1)}},
+		{name: "re-arm waits for a priced total", active: true, total: 150, balances: []entities.Balance{held(true, 1)}},
+		{
+			name: "unpriced token no longer held", total: 50, balances: []entities.Balance{held(true, 0), held(false, 1)},
+			wantAlert: true, wantStates: []ruleState{{active: true, triggered: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alertRepo := &fakeAlertRepo{}
+			as := NewAlertService(logs.NewLogger(), alertRepo, trackedWalletRepo{}, nil, events.NewBus(logs.NewLogger()), nil)
+			rule := entities.AlertRule{
+				ID:              primitive.NewObjectID(),
+				Type:            entities.AlertValueBelow,
+				Blockchain:      "ETH",
+				Address:         "0xabc",
+				Threshold:       entities.NewUSDFromFloat(100),
+				CooldownSeconds: 3600,
+				Active:          tt.active,
+				LastTriggeredAt: tt.lastFired,
+			}
+			as.evaluateValue(rule, entities.BalancesRefreshed{
+				WalletEvent: entities.WalletEvent{Type: entities.EventBalancesRefreshed, Blockchain: "ETH", Address: "0xabc", Timestamp: now},
+				TotalUSD:    entities.NewUSDFromFloat(tt.total),
+				Balances:    tt.balances,
+			})
+
+			if got := len(alertRepo.alerts) > 0; got != tt.wantAlert {
+				t.Errorf("alert raised = %v, want %v", got, tt.wantAlert)
+			}
+			if len(alertRepo.states) != len(tt.wantStates) {
+				t.Fatalf("rule states = %v, want %v", alertRepo.states, tt.wantStates)
+			}
+			for i, want := range tt.wantStates {
+				if alertRepo.states[i] != want {
+					t.Errorf("rule state = %+v, want %+v", alertRepo.states[i], want)
+				}
+			}
+		})
+	}
+}
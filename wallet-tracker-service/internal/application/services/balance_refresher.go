@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/providers"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+)
+
+// refreshLeaseName is the job lease that keeps the refresh to one replica at a time
+const refreshLeaseName = "balance-refresh"
+
+// refreshLeaseMargin covers the wallets still in flight at the deadline, retries included
+const refreshLeaseMargin = 5 * time.Minute
+
+type IBalanceRefresher interface {
+	Run() *entities.RefreshRun
+	Running() bool
+	GetRuns(limit int) ([]entities.RefreshRun, error)
+}
+
+// BalanceRefresher refreshes every tracked wallet on a schedule with a bounded pool of workers.
+// Wallets start on each balance provider no faster than its rate limit, a run stops starting
+// wallets at its deadline, and a lease keeps runs from overlapping on any replica.
+// Every run is recorded with its counters.
+type BalanceRefresher struct {
+	logger        *logs.Logger
+	walletRepo    repositories.IWalletRepository
+	runRepo       repositories.IRefreshRunRepository
+	lease         jobLease
+	walletService IWalletService
+	providers     *providers.Registry
+	workers       int
+	deadline      time.Duration
+	rates         map[string]float64
+	host          string
+
+	running  atomic.Bool
+	mu       sync.Mutex
+	limiters map[string]*intervalLimiter
+}
+
+// NewBalanceRefresher creates a refresher. rates holds wallets per second by provider name;
+// providers missing from it are only bounded by the number of workers.
+func NewBalanceRefresher(
+	logger *logs.Logger,
+	walletRepo repositories.IWalletRepository,
+	runRepo repositories.IRefreshRunRepository,
+	leaseRepo repositories.ILeaseRepository,
+	walletService IWalletService,
+	balanceProviders *providers.Registry,
+	workers int,
+	deadline time.Duration,
+	rates map[string]float64,
+) *BalanceRefresher {
+	host, _ := os.Hostname()
+	normalized := make(map[string]float64, len(rates))
+	for name, rate := range rates {
+		normalized[strings.ToLower(name)] = rate
+	}
+	return &BalanceRefresher{
+		logger:        logger,
+		walletRepo:    walletRepo,
+		runRepo:       runRepo,
+		lease:         jobLease{repo: leaseRepo, name: refreshLeaseName, ttl: deadline + refreshLeaseMargin},
+		walletService: walletService,
+		providers:     balanceProviders,
+		workers:       workers,
+		deadline:      deadline,
+		rates:         normalized,
+		host:          host,
+		limiters:      make(map[string]*intervalLimiter),
+	}
+}
+
+// Run refreshes every tracked wallet and returns the run's record. Wallets already being
+// refreshed when the deadline passes are finished; the rest are counted as skipped.
+func (br *BalanceRefresher) Run() *entities.RefreshRun {
+	run := &entities.RefreshRun{
+		Host:      br.host,
+		Status:    entities.RefreshRunning,
+		StartedAt: time.Now(),
+	}
+
+	if !br.running.CompareAndSwap(false, true) {
+		br.logger.Warnf("Balance refresh skipped, the previous run is still going")
+		run.Status = entities.RefreshOverlapped
+		br.finish(run)
+		return run
+	}
+	defer br.running.Store(false)
+
+	lease, acquired, err := br.lease.acquire()
+	if err != nil {
+		br.logger.Errorf("Balance refresh could not take its lease: %v", err)
+		run.Status = entities.RefreshFailed
+		run.Error = err.Error()
+		br.finish(run)
+		return run
+	}
+	if !acquired {
+		run.Status = entities.RefreshLeased
+		if lease != nil {
+			run.LeaseHolder = lease.Holder
+			br.logger.Infof("Balance refresh skipped, %s holds the lease until %s", lease.Holder, lease.ExpiresAt.Format(time.RFC3339))
+		}
+		br.finish(run)
+		return run
+	}
+	defer func() {
+		if err := br.lease.release(); err != nil {
+			br.logger.Warnf("Balance refresh could not release its lease: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), br.deadline)
+	defer cancel()
+
+	wallets, err := br.walletRepo.GetAllWallets()
+	if err != nil {
+		br.logger.Errorf("Balance refresh could not list wallets: %v", err)
+		run.Status = entities.RefreshFailed
+		run.Error = err.Error()
+		br.finish(run)
+		return run
+	}
+	run.Wallets = int64(len(wallets))
+	br.save(run)
+
+	var refreshed, failed, skipped int64
+	jobs := make(chan entities.Wallet)
+	var wg sync.WaitGroup
+	for i := 0; i < br.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for w := range jobs {
+				switch br.refresh(ctx, w) {
+				case refreshDone:
+					atomic.AddInt64(&refreshed, 1)
+				case refreshError:
+					atomic.AddInt64(&failed, 1)
+				default:
+					atomic.AddInt64(&skipped, 1)
+				}
+			}
+		}()
+	}
+
+	queued := 0
+feed:
+	for _, w := range wallets {
+		select {
+		case jobs <- w:
+			queued++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	run.Refreshed = refreshed
+	run.Failed = failed
+	run.Skipped = skipped + int64(len(wallets)-queued)
+	run.Status = entities.RefreshCompleted
+	if ctx.Err() != nil {
+		run.Status = entities.RefreshDeadlineExceeded
+	}
+	br.finish(run)
+
+	br.logger.Infof("Balance refresh %s in %s: %d wallets, %d refreshed, %d failed, %d skipped",
+		run.Status, time.Duration(run.DurationMs)*time.Millisecond, run.Wallets, run.Refreshed, run.Failed, run.Skipped)
+	return run
+}
+
+// Running reports whether a run is in progress on this replica
+func (br *BalanceRefresher) Running() bool {
+	return br.running.Load()
+}
+
+// GetRuns returns the most recent runs of every replica, newest first
+func (br *BalanceRefresher) GetRuns(limit int) ([]entities.RefreshRun, error) {
+	if limit < 1 || limit > 200 {
+		limit = 20
+	}
+	runs, err := br.runRepo.GetRecentRuns(int64(limit))
+	if err != nil {
+		br.logger.Errorf("Error fetching balance refresh runs: %v", err)
+		return nil, err
+	}
+	return runs, nil
+}
+
+type refreshOutcome int
+
+const (
+	refreshDone refreshOutcome = iota
+	refreshError
+	refreshSkipped
+)
+
+// refresh waits for the wallet's provider to allow another wallet, then refreshes it
+func (br *BalanceRefresher) refresh(ctx context.Context, w entities.Wallet) refreshOutcome {
+	if l := br.limiter(w.Blockchain); l != nil && l.Wait(ctx) != nil {
+		return refreshSkipped
+	}
+
+	// The run takes a while, so skip wallets deleted since the list was read
+	current, err := br.walletRepo.GetWallet(w.UserID, w.Blockchain, w.Address)
+	if err != nil {
+		br.logger.Errorf("Balance refresh error: %v", err)
+		return refreshError
+	}
+	if current == nil {
+		return refreshSkipped
+	}
+
+	addrParam := fmt.Sprintf("%s.%s", w.Blockchain, w.Address)
+	if _, err := br.walletService.RefreshBalance(w.UserID, addrParam); err != nil {
+		if errors.Is(err, ErrWalletNotFound) {
+			return refreshSkipped
+		}
+		br.logger.Errorf("Balance refresh for wallet %s: %v", addrParam, err)
+		return refreshError
+	}
+	return refreshDone
+}
+
+// limiter returns the rate limiter of the provider serving a blockchain, or nil when its rate is not limited
+func (br *BalanceRefresher) limiter(blockchain string) *intervalLimiter {
+	name := "default"
+	if p, err := br.providers.ProviderFor(blockchain); err == nil {
+		name = strings.ToLower(p.Name())
+	}
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	l, ok := br.limiters[name]
+	if !ok {
+		if rate := br.rates[name]; rate > 0 {
+			l = &intervalLimiter{interval: time.Duration(float64(time.Second) / rate)}
+		}
+		br.limiters[name] = l
+	}
+	return l
+}
+
+func (br *BalanceRefresher) finish(run *entities.RefreshRun) {
+	now := time.Now()
+	run.FinishedAt = &now
+	run.DurationMs = now.Sub(run.StartedAt).Milliseconds()
+	br.save(run)
+}
+
+func (br *BalanceRefresher) save(run *entities.RefreshRun) {
+	if err := br.runRepo.SaveRun(run); err != nil {
+		br.logger.Errorf("Error saving balance refresh run: %v", err)
+	}
+}
+
+// intervalLimiter spaces out the calls to Wait by a fixed interval
+type intervalLimiter struct {
+	mu       sync.Mutex
+	next     time.Time
+	interval time.Duration
+}
+
+// Wait blocks until the caller's turn, or returns the context's error if it ends first
+func (l *intervalLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/providers"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+)
+
+type fakeLeaseRepo struct {
+	mu       sync.Mutex
+	holder   string // another replica holding the lease, or empty
+	err      error
+	acquired int
+	released int
+}
+
+func (r *fakeLeaseRepo) AcquireLease(name, holder string, ttl time.Duration) (*entities.Lease, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, false, r.err
+	}
+	if r.holder != "" {
+		return &entities.Lease{Name: name, Holder: r.holder, ExpiresAt: time.Now().Add(ttl)}, false, nil
+	}
+	r.acquired++
+	return &entities.Lease{Name: name, Holder: holder, ExpiresAt: time.Now().Add(ttl)}, true, nil
+}
+
+func (r *fakeLeaseRepo) ReleaseLease(name, holder string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.released++
+	return nil
+}
+
+type fakeRunRepo struct {
+	repositories.IRefreshRunRepository
+	mu    sync.Mutex
+	saves int
+}
+
+func (r *fakeRunRepo) SaveRun(run *entities.RefreshRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saves++
+	return nil
+}
+
+// listedWalletRepo lists its wallets; those in deleted are gone by the time they are refreshed
+type listedWalletRepo struct {
+	repositories.IWalletRepository
+	wallets []entities.Wallet
+	deleted map[string]bool
+}
+
+func (r *listedWalletRepo) GetAllWallets() ([]entities.Wallet, error) {
+	return r.wallets, nil
+}
+
+func (r *listedWalletRepo) GetWallet(userID, blockchain, address string) (*entities.Wallet, error) {
+	if r.deleted[address] {
+		return nil, nil
+	}
+	return &entities.Wallet{UserID: userID, Blockchain: blockchain, Address: address}, nil
+}
+
+// refreshingWalletService answers RefreshBalance with the error set for the address, after wait
+type refreshingWalletService struct {
+	IWalletService
+	errs map[string]error
+	wait func()
+}
+
+func (s *refreshingWalletService) RefreshBalance(userID, addressParam string) ([]entities.Wallet, error) {
+	if s.wait != nil {
+		s.wait()
+	}
+	return nil, s.errs[addressParam[strings.Index(addressParam, ".")+1:]]
+}
+
+func testWallets(addresses ...string) []entities.Wallet {
+	var wallets []entities.Wallet
+	for _, addr := range addresses {
+		wallets = append(wallets, entities.Wallet{UserID: "user1", Blockchain: "ETH", Address: addr})
+	}
+	return wallets
+}
+
+func newTestRefresher(leaseRepo *fakeLeaseRepo, walletRepo *listedWalletRepo, walletService IWalletService, workers int, deadline time.Duration) *BalanceRefresher {
+	return NewBalanceRefresher(logs.NewLogger(), walletRepo, &fakeRunRepo{}, leaseRepo, walletService,
+		providers.NewRegistry(nil), workers, deadline, nil)
+}
+
+func TestBalanceRefresherRun(t *testing.T) {
+	walletRepo := &listedWalletRepo{
+		wallets: testWallets("0x1", "0x2", "0x3", "0x4"),
+		deleted: map[string]bool{"0x3": true},
+	}
+	walletService := &refreshingWalletService{errs: map[string]error{
+		"0x2": errors.New("provider down"),
+		"0x4": ErrWalletNotFound,
+	}}
+
+	tests := []struct {
+		name         string
+		lease        *fakeLeaseRepo
+		wantStatus   string
+		wantHolder   string
+		wantCounts   [4]int64 // wallets, refreshed, failed, skipped
+		wantReleased int
+	}{
+		{
+			name:         "lease taken",
+			lease:        &fakeLeaseRepo{},
+			wantStatus:   entities.RefreshCompleted,
+			wantCounts:   [4]int64{4, 1, 1, 2},
+			wantReleased: 1,
+		},
+		{
+			name:       "lease held by another replica",
+			lease:      &fakeLeaseRepo{holder: "replica-2"},
+			wantStatus: entities.RefreshLeased,
+			wantHolder: "replica-2",
+		},
+		{
+			name:       "lease unavailable",
+			lease:      &fakeLeaseRepo{err: errors.New("mongo down")},
+			wantStatus: entities.RefreshFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := newTestRefresher(tt.lease, walletRepo, walletService, 2, time.Minute).Run()
+
+			if run.Status != tt.wantStatus || run.LeaseHolder != tt.wantHolder {
+				t.Errorf("run status %s, lease holder %q; want %s, %q", run.Status, run.LeaseHolder, tt.wantStatus, tt.wantHolder)
+			}
+			if got := [4]int64{run.Wallets, run.Refreshed, run.Failed, run.Skipped}; got != tt.wantCounts {
+				t.Errorf("wallets, refreshed, failed, skipped = %v, want %v", got, tt.wantCounts)
+			}
+			if tt.lease.released != tt.wantReleased {
+				t.Errorf("lease released %d times, want %d", tt.lease.released, tt.wantReleased)
+			}
+			if run.FinishedAt == nil {
+				t.Error("run was not finished")
+			}
+		})
+	}
+}
+
+func TestBalanceRefresherRunOverlap(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	walletService := &refreshingWalletService{wait: func() {
+		once.Do(func() { close(started) })
+		<-release
+	}}
+	lease := &fakeLeaseRepo{}
+	br := newTestRefresher(lease, &listedWalletRepo{wallets: testWallets("0x1")}, walletService, 1, time.Minute)
+
+	done := make(chan *entities.RefreshRun)
+	go func() { done <- br.Run() }()
+	<-started
+
+	if !br.Running() {
+		t.Error("Running = false during a run")
+	}
+	if run := br.Run(); run.Status != entities.RefreshOverlapped {
+		t.Errorf("overlapping run status = %s, want %s", run.Status, entities.RefreshOverlapped)
+	}
+	close(release)
+	if run := <-done; run.Status != entities.RefreshCompleted {
+		t.Errorf("first run status = %s, want %s", run.Status, entities.RefreshCompleted)
+	}
+	if lease.acquired != 1 {
+		t.Errorf("lease acquired %d times, want once: the overlapping run must not touch it", lease.acquired)
+	}
+	if br.Running() {
+		t.Error("Running = true after the run")
+	}
+}
+
+func TestBalanceRefresherRunDeadline(t *testing.T) {
+	walletService := &refreshingWalletService{wait: func() { time.Sleep(100 * time.Millisecond) }}
+	lease := &fakeLeaseRepo{}
+	br := newTestRefresher(lease, &listedWalletRepo{wallets: testWallets("0x1", "0x2", "0x3")}, walletService, 1, 50*time.Millisecond)
+
+	// The wallet in flight at the deadline is finished; the others are never started
+	run := br.Run()
+	if run.Status != entities.RefreshDeadlineExceeded || run.Refreshed != 1 || run.Skipped != 2 {
+		t.Errorf("run %s with %d refreshed and %d skipped, want %s with 1 and 2",
+			run.Status, run.Refreshed, run.Skipped, entities.RefreshDeadlineExceeded)
+	}
+	if lease.released != 1 {
+		t.Errorf("lease released %d times, want 1", lease.released)
+	}
+}
+
+func TestIntervalLimiter(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	l := &intervalLimiter{interval: interval}
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 3*interval {
+		t.Errorf("4 waits took %s, want at least %s", elapsed, 3*interval)
+	}
+
+	// Idle time does not build up a burst: after a pause only the first call goes straight through
+	time.Sleep(5 * interval)
+	start = time.Now()
+	_ = l.Wait(ctx)
+	_ = l.Wait(ctx)
+	if elapsed := time.Since(start); elapsed < interval {
+		t.Errorf("2 waits after a pause took %s, want at least %s", elapsed, interval)
+	}
+
+	// A caller whose context ends gives up its wait
+	l = &intervalLimiter{interval: time.Hour}
+	_ = l.Wait(ctx)
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(cancelled); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait = %v, want the context's error", err)
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+)
+
+// leaseHolder identifies this process in job leases; replicas may share a hostname and a PID
+var leaseHolder = func() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}()
+
+// jobLease keeps a scheduled job to one replica at a time. The TTL must outlast a run, so a
+// replica that dies mid-run only holds the job back until the lease expires.
+type jobLease struct {
+	repo repositories.ILeaseRepository
+	name string
+	ttl  time.Duration
+}
+
+// acquire takes the lease for this process, returning the lease as it stands either way
+func (l jobLease) acquire() (*entities.Lease, bool, error) {
+	return l.repo.AcquireLease(l.name, leaseHolder, l.ttl)
+}
+
+func (l jobLease) release() error {
+	return l.repo.ReleaseLease(l.name, leaseHolder)
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/prices"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+)
+
+// Notes added to a PnL whose figures are incomplete
+const (
+	noteUntrackedHoldings = "part of the current holdings arrived from the user's other wallets, over a bridge or before the synced history, and has no cost basis here"
+	noteUnpricedMovements = "some transactions had no historical price and count at zero value"
+)
+
+type IPnLService interface {
+	GetWalletPnL(userID, addressParam, method string) (*entities.WalletPnL, error)
+}
+
+// PnLService computes cost basis and PnL from the classified transactions of a wallet, the same way
+// tax reports do: every incoming leg is an acquisition and every outgoing leg a disposal at the
+// market price of the time, and fees are disposals of the native asset. Transfers from or to the
+// user's other wallets, bridge transactions and approvals only move holdings, so what arrived that
+// way has no cost here; the result says so in its notes.
+type PnLService struct {
+	logger             *logs.Logger
+	walletRepo         repositories.IWalletRepository
+	balanceRepo        repositories.IBalanceRepository
+	transactionService ITransactionService
+	priceOracle        prices.IPriceOracle
+	settingsService    ISettingsService
+	chainRegistry      *chains.Registry
+}
+
+func NewPnLService(
+	logger *logs.Logger,
+	walletRepo repositories.IWalletRepository,
+	balanceRepo repositories.IBalanceRepository,
+	transactionService ITransactionService,
+	priceOracle prices.IPriceOracle,
+	settingsService ISettingsService,
+	chainRegistry *chains.Registry,
+) *PnLService {
+	return &PnLService{
+		logger:             logger,
+		walletRepo:         walletRepo,
+		balanceRepo:        balanceRepo,
+		transactionService: transactionService,
+		priceOracle:        priceOracle,
+		settingsService:    settingsService,
+		chainRegistry:      chainRegistry,
+	}
+}
+
+// assetHistory is the movement history of one asset in a wallet
+type assetHistory struct {
+	asset     entities.Asset
+	movements []usecases.Movement
+	current   entities.Balance
+	unpriced  bool
+}
+
+// GetWalletPnL returns the PnL per asset of a tracked wallet. An empty method uses the user's setting.
+func (ps *PnLService) GetWalletPnL(userID, addressParam, method string) (*entities.WalletPnL, error) {
+	if method == "" {
+		settings, err := ps.settingsService.GetSettings(userID)
+		if err != nil {
+			return nil, err
+		}
+		method = settings.CostBasisMethod
+	} else {
+		var err error
+		if method, err = normalizeCostBasisMethod(method); err != nil {
+			return nil, err
+		}
+	}
+
+	bc, addr, err := parseAddressParam(addressParam)
+	if err != nil {
+		return nil, err
+	}
+	if w, err := ps.walletRepo.GetWallet(userID, bc, addr); err != nil || w == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrWalletNotFound
+	}
+
+	txs, err := ps.transactionService.GetWalletHistory(userID, entities.WalletRef{Blockchain: bc, Address: addr}, time.Now())
+	if err != nil {
+		ps.logger.Errorf("Error reading transaction history: %v", err)
+		return nil, err
+	}
+	wallets, err := ps.walletRepo.GetWalletsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	events := taxEvents(ps.chainRegistry, txs, ownAddresses(wallets))
+	if err := priceEvents(ps.priceOracle, events); err != nil {
+		ps.logger.Errorf("Error reading historical prices: %v", err)
+		return nil, err
+	}
+
+	current, err := ps.balanceRepo.GetBalancesByWallet(bc, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.WalletPnL{
+		Blockchain: bc,
+		Address:    addr,
+		Method:     method,
+		Tokens:     []entities.TokenPnL{},
+	}
+	if len(txs) > 0 {
+		since := txs[0].Timestamp
+		result.Since = &since
+	}
+
+	var untracked, unpriced bool
+	keys, histories := assetHistories(events, current)
+	for _, key := range keys {
+		h := histories[key]
+		position, err := usecases.ComputePosition(method, h.movements)
+		if err != nil {
+			return nil, err
+		}
+		unpriced = unpriced || h.unpriced
+
+		token := entities.TokenPnL{
+			Asset:       h.asset,
+			Amount:      h.current.Amount,
+			CostBasis:   entities.NewUSDFromRat(position.CostBasis),
+			Value:       h.current.USDValue,
+			RealizedPnL: entities.NewUSDFromRat(position.Realized),
+		}
+		trackedValue := splitHolding(&token, h.current, position.Quantity)
+		if !token.UntrackedAmount.IsZero() {
+			untracked = true
+		}
+		if position.Quantity.Sign() > 0 {
+			token.AverageCost = entities.NewUSDFromRat(new(big.Rat).Quo(position.CostBasis, position.Quantity))
+			token.UnrealizedPnL = trackedValue.Sub(token.CostBasis)
+		}
+
+		result.TotalCostBasis = result.TotalCostBasis.Add(token.CostBasis)
+		result.TotalValue = result.TotalValue.Add(token.Value)
+		result.RealizedPnL = result.RealizedPnL.Add(token.RealizedPnL)
+		result.UnrealizedPnL = result.UnrealizedPnL.Add(token.UnrealizedPnL)
+		result.UntrackedValue = result.UntrackedValue.Add(token.UntrackedValue)
+		result.Tokens = append(result.Tokens, token)
+	}
+
+	if untracked {
+		result.Notes = append(result.Notes, noteUntrackedHoldings)
+	}
+	if unpriced {
+		result.Notes = append(result.Notes, noteUnpricedMovements)
+	}
+	return result, nil
+}
+
+// splitHolding sets the part of the current holding beyond the quantity the history accounts for,
+// and returns the value of the rest at the current unit price. Only that tracked part has a cost
+// basis, so it alone goes into unrealized PnL.
+func splitHolding(token *entities.TokenPnL, current entities.Balance, quantity *big.Rat) entities.USD {
+	held := current.Amount.Rat(current.Asset.Decimals)
+	if held.Sign() <= 0 {
+		return entities.USD{}
+	}
+	if held.Cmp(quantity) <= 0 {
+		return current.USDValue
+	}
+
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(current.Asset.Decimals)), nil))
+	trackedRaw := new(big.Rat).Mul(quantity, scale)
+	trackedRaw.SetInt(new(big.Int).Quo(trackedRaw.Num(), trackedRaw.Denom()))
+	untrackedRaw := new(big.Int).Sub(current.Amount.BigInt(), trackedRaw.Num())
+
+	token.UntrackedAmount = entities.NewRawAmount(untrackedRaw)
+	token.UntrackedValue = current.USDValue.MulRat(new(big.Rat).SetFrac(untrackedRaw, current.Amount.BigInt()))
+	return current.USDValue.Sub(token.UntrackedValue)
+}
+
+// assetHistories groups time-ordered events per asset and attaches the wallet's current balance of
+// each. Keys are returned in order of first appearance, followed by held assets without transactions.
+func assetHistories(events []usecases.TaxEvent, current *entities.WalletBalances) ([]string, map[string]*assetHistory) {
+	var keys []string
+	histories := make(map[string]*assetHistory)
+	history := func(asset entities.Asset) *assetHistory {
+		key := usecases.AssetKey(asset)
+		h, ok := histories[key]
+		if !ok {
+			h = &assetHistory{asset: asset, current: entities.Balance{Asset: asset}}
+			histories[key] = h
+			keys = append(keys, key)
+		}
+		return h
+	}
+
+	for _, ev := range events {
+		h := history(ev.Asset)
+		movement := ev.Movement
+		if movement.UnitPrice == nil {
+			movement.UnitPrice = new(big.Rat)
+			h.unpriced = true
+		}
+		h.movements = append(h.movements, movement)
+	}
+
+	// The stored balances carry the latest price of each asset
+	if current != nil {
+		for _, b := range current.Balances {
+			h := history(b.Asset)
+			h.asset = b.Asset
+			h.current = b
+		}
+	}
+	return keys, histories
+}
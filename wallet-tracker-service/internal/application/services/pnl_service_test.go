@@ -0,0 +1,50 @@
+package services
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+func TestSplitHolding(t *testing.T) {
+	eth := entities.Asset{Symbol: "ETH", Decimals: 18}
+	ether := func(n int64) entities.RawAmount {
+		return entities.NewRawAmount(new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)))
+	}
+
+	tests := []struct {
+		name          string
+		held          entities.RawAmount
+		quantity      *big.Rat
+		wantTracked   string
+		wantUntracked string
+		wantValue     string
+	}{
+		// 10 ETH from cold storage and 1 bought: only the bought one is measured against its cost
+		{"holding beyond the history", ether(11), big.NewRat(1, 1), "3000", "10000000000000000000", "30000"},
+		{"holding matches the history", ether(2), big.NewRat(2, 1), "6000", "0", "0"},
+		{"history claims more than is held", ether(1), big.NewRat(3, 1), "3000", "0", "0"},
+		{"nothing held", entities.NewRawAmount(big.NewInt(0)), big.NewRat(1, 1), "0", "0", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := entities.Balance{
+				Asset:    eth,
+				Amount:   tt.held,
+				USDValue: entities.NewUSDFromRat(new(big.Rat).Mul(tt.held.Rat(18), big.NewRat(3000, 1))),
+			}
+			var token entities.TokenPnL
+			tracked := splitHolding(&token, current, tt.quantity)
+			if got := tracked.String(); got != tt.wantTracked {
+				t.Errorf("tracked value = %s, want %s", got, tt.wantTracked)
+			}
+			if got := token.UntrackedAmount.String(); got != tt.wantUntracked {
+				t.Errorf("untracked amount = %s, want %s", got, tt.wantUntracked)
+			}
+			if got := token.UntrackedValue.String(); got != tt.wantValue {
+				t.Errorf("untracked value = %s, want %s", got, tt.wantValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxPortfolioNameLength = 100
+	maxPortfolioWallets    = 500
+)
+
+var (
+	ErrPortfolioNotFound = errors.New("portfolio not found")
+	ErrInvalidPortfolio  = errors.New("invalid portfolio")
+)
+
+type IPortfolioService interface {
+	CreatePortfolio(userID, name, description string, addressParams []string) (*entities.Portfolio, error)
+	GetPortfolios(userID string) ([]entities.Portfolio, error)
+	GetPortfolio(userID, id string) (*entities.Portfolio, error)
+	UpdatePortfolio(userID, id string, update PortfolioUpdate) (*entities.Portfolio, error)
+	DeletePortfolio(userID, id string) error
+	GetPortfolioSummary(userID, id string) (*entities.PortfolioSummary, error)
+}
+
+// PortfolioUpdate holds the editable fields of a portfolio; nil fields are left unchanged
+type PortfolioUpdate struct {
+	Name        *string
+	Description *string
+	Wallets     *[]string
+}
+
+type PortfolioService struct {
+	logger        *logs.Logger
+	portfolioRepo repositories.IPortfolioRepository
+	walletRepo    repositories.IWalletRepository
+	balanceRepo   repositories.IBalanceRepository
+}
+
+func NewPortfolioService(
+	logger *logs.Logger,
+	portfolioRepo repositories.IPortfolioRepository,
+	walletRepo repositories.IWalletRepository,
+	balanceRepo repositories.IBalanceRepository,
+) *PortfolioService {
+	return &PortfolioService{
+		logger:        logger,
+		portfolioRepo: portfolioRepo,
+		walletRepo:    walletRepo,
+		balanceRepo:   balanceRepo,
+	}
+}
+
+// CreatePortfolio groups already tracked wallets of the user under a name
+func (ps *PortfolioService) CreatePortfolio(userID, name, description string, addressParams []string) (*entities.Portfolio, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxPortfolioNameLength {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidPortfolio, maxPortfolioNameLength)
+	}
+	wallets, err := ps.resolveWallets(userID, addressParams)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio := &entities.Portfolio{
+		UserID:      userID,
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		Wallets:     wallets,
+		CreatedAt:   time.Now(),
+	}
+	if err := ps.portfolioRepo.SavePortfolio(portfolio); err != nil {
+		ps.logger.Errorf("Error saving portfolio: %v", err)
+		return nil, err
+	}
+	return portfolio, nil
+}
+
+// GetPortfolios returns the portfolios of a user
+func (ps *PortfolioService) GetPortfolios(userID string) ([]entities.Portfolio, error) {
+	portfolios, err := ps.portfolioRepo.GetPortfoliosByUser(userID)
+	if err != nil {
+		ps.logger.Errorf("Error fetching portfolios: %v", err)
+		return nil, err
+	}
+	return portfolios, nil
+}
+
+// GetPortfolio returns a single portfolio of the user
+func (ps *PortfolioService) GetPortfolio(userID, id string) (*entities.Portfolio, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrPortfolioNotFound
+	}
+	portfolio, err := ps.portfolioRepo.GetPortfolio(userID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if portfolio == nil {
+		return nil, ErrPortfolioNotFound
+	}
+	return portfolio, nil
+}
+
+// UpdatePortfolio renames a portfolio or replaces its wallet list
+func (ps *PortfolioService) UpdatePortfolio(userID, id string, update PortfolioUpdate) (*entities.Portfolio, error) {
+	portfolio, err := ps.GetPortfolio(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" || len(name) > maxPortfolioNameLength {
+			return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidPortfolio, maxPortfolioNameLength)
+		}
+		portfolio.Name = name
+	}
+	if update.Description != nil {
+		portfolio.Description = strings.TrimSpace(*update.Description)
+	}
+	if update.Wallets != nil {
+		wallets, err := ps.resolveWallets(userID, *update.Wallets)
+		if err != nil {
+			return nil, err
+		}
+		portfolio.Wallets = wallets
+	}
+
+	if err := ps.portfolioRepo.SavePortfolio(portfolio); err != nil {
+		ps.logger.Errorf("Error saving portfolio: %v", err)
+		return nil, err
+	}
+	return portfolio, nil
+}
+
+// DeletePortfolio removes the grouping; its wallets stay tracked
+func (ps *PortfolioService) DeletePortfolio(userID, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrPortfolioNotFound
+	}
+	deleted, err := ps.portfolioRepo.DeletePortfolio(userID, objectID)
+	if err != nil {
+		ps.logger.Errorf("Error deleting portfolio: %v", err)
+		return err
+	}
+	if !deleted {
+		return ErrPortfolioNotFound
+	}
+	return nil
+}
+
+// GetPortfolioSummary aggregates the stored balances of the portfolio's wallets.
+// Wallets the user has stopped tracking since are left out.
+func (ps *PortfolioService) GetPortfolioSummary(userID, id string) (*entities.PortfolioSummary, error) {
+	portfolio, err := ps.GetPortfolio(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked, err := ps.walletRepo.GetAllAddressesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	trackedSet := make(map[string]bool, len(tracked))
+	for _, a := range tracked {
+		trackedSet[a] = true
+	}
+
+	wallets := make([]entities.WalletRef, 0, len(portfolio.Wallets))
+	for _, w := range portfolio.Wallets {
+		if trackedSet[w.Blockchain+"."+w.Address] {
+			wallets = append(wallets, w)
+		}
+	}
+
+	summary, err := ps.balanceRepo.AggregateBalances(wallets)
+	if err != nil {
+		ps.logger.Errorf("Error aggregating portfolio %s: %v", id, err)
+		return nil, err
+	}
+	summary.PortfolioID = portfolio.ID.Hex()
+	summary.Name = portfolio.Name
+	return summary, nil
+}
+
+// resolveWallets normalizes address params and checks that the user tracks each wallet
+func (ps *PortfolioService) resolveWallets(userID string, addressParams []string) ([]entities.WalletRef, error) {
+	if len(addressParams) > maxPortfolioWallets {
+		return nil, fmt.Errorf("%w: a portfolio can hold at most %d wallets", ErrInvalidPortfolio, maxPortfolioWallets)
+	}
+
+	seen := make(map[string]bool, len(addressParams))
+	wallets := make([]entities.WalletRef, 0, len(addressParams))
+	for _, addressParam := range addressParams {
+		bc, addr, err := usecases.ParseBlockchainAndAddress(addressParam)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPortfolio, err)
+		}
+		addr, err = NormalizeAddress(bc, addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPortfolio, err)
+		}
+		if seen[bc+"."+addr] {
+			continue
+		}
+		seen[bc+"."+addr] = true
+
+		wallet, err := ps.walletRepo.GetWallet(userID, bc, addr)
+		if err != nil {
+			return nil, err
+		}
+		if wallet == nil {
+			return nil, fmt.Errorf("%w: wallet %s.%s is not tracked", ErrInvalidPortfolio, bc, addr)
+		}
+
+		wallets = append(wallets, entities.WalletRef{
+			Blockchain: bc,
+			Address:    addr,
+			CAIP10:     usecases.FormatCAIP10(bc, addr),
+		})
+	}
+	return wallets, nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+)
+
+var ErrInvalidSettings = errors.New("invalid settings")
+
+type ISettingsService interface {
+	GetSettings(userID string) (*entities.UserSettings, error)
+	UpdateSettings(userID string, update SettingsUpdate) (*entities.UserSettings, error)
+}
+
+// SettingsUpdate holds the settings to change; nil fields are left as they are
+type SettingsUpdate struct {
+	CostBasisMethod *string
+}
+
+type SettingsService struct {
+	logger       *logs.Logger
+	settingsRepo repositories.ISettingsRepository
+}
+
+func NewSettingsService(logger *logs.Logger, settingsRepo repositories.ISettingsRepository) *SettingsService {
+	return &SettingsService{
+		logger:       logger,
+		settingsRepo: settingsRepo,
+	}
+}
+
+// GetSettings returns the user's settings, or the defaults if they never saved any
+func (ss *SettingsService) GetSettings(userID string) (*entities.UserSettings, error) {
+	settings, err := ss.settingsRepo.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &entities.UserSettings{UserID: userID}
+	}
+	if settings.CostBasisMethod == "" {
+		settings.CostBasisMethod = usecases.DefaultCostBasisMethod
+	}
+	return settings, nil
+}
+
+func (ss *SettingsService) UpdateSettings(userID string, update SettingsUpdate) (*entities.UserSettings, error) {
+	settings, err := ss.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.CostBasisMethod != nil {
+		method, err := normalizeCostBasisMethod(*update.CostBasisMethod)
+		if err != nil {
+			return nil, err
+		}
+		settings.CostBasisMethod = method
+	}
+
+	if err := ss.settingsRepo.SaveSettings(settings); err != nil {
+		ss.logger.Errorf("Error saving settings: %v", err)
+		return nil, err
+	}
+	return settings, nil
+}
+
+func normalizeCostBasisMethod(method string) (string, error) {
+	method = strings.ToLower(strings.TrimSpace(method))
+	if !usecases.ValidCostBasisMethod(method) {
+		return "", fmt.Errorf("%w: costBasisMethod must be fifo, lifo, hifo or average", ErrInvalidSettings)
+	}
+	return method, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+)
+
+// compactionLeaseName is the job lease that keeps compaction to one replica at a time
+const compactionLeaseName = "snapshot-compaction"
+
+// compactionLeaseTTL outlasts the two rollup passes of a run
+const compactionLeaseTTL = 25 * time.Minute
+
+// SnapshotCompactor keeps raw snapshots for rawRetention, hourly rollups up to hourlyRetention
+// and daily rollups beyond that. A lease keeps runs from overlapping on any replica.
+type SnapshotCompactor struct {
+	logger          *logs.Logger
+	snapshotRepo    repositories.ISnapshotRepository
+	lease           jobLease
+	rawRetention    time.Duration
+	hourlyRetention time.Duration
+}
+
+func NewSnapshotCompactor(
+	logger *logs.Logger,
+	snapshotRepo repositories.ISnapshotRepository,
+	leaseRepo repositories.ILeaseRepository,
+	rawRetention time.Duration,
+	hourlyRetention time.Duration,
+) *SnapshotCompactor {
+	if hourlyRetention < rawRetention {
+		hourlyRetention = rawRetention
+	}
+	return &SnapshotCompactor{
+		logger:          logger,
+		snapshotRepo:    snapshotRepo,
+		lease:           jobLease{repo: leaseRepo, name: compactionLeaseName, ttl: compactionLeaseTTL},
+		rawRetention:    rawRetention,
+		hourlyRetention: hourlyRetention,
+	}
+}
+
+// Run rolls raw snapshots into hours, then hours into days. Cutoffs are aligned to bucket
+// boundaries so a bucket is only rolled up once it is complete.
+func (sc *SnapshotCompactor) Run() error {
+	lease, acquired, err := sc.lease.acquire()
+	if err != nil {
+		return fmt.Errorf("could not take the compaction lease: %w", err)
+	}
+	if !acquired {
+		if lease != nil {
+			sc.logger.Infof("Snapshot compaction skipped, %s holds the lease until %s", lease.Holder, lease.ExpiresAt.Format(time.RFC3339))
+		}
+		return nil
+	}
+	defer func() {
+		if err := sc.lease.release(); err != nil {
+			sc.logger.Warnf("Snapshot compaction could not release its lease: %v", err)
+		}
+	}()
+
+	now := time.Now()
+
+	hourCutoff := repositories.BucketStart(now.Add(-sc.rawRetention), repositories.BucketHour)
+	written, removed, err := sc.snapshotRepo.RollupSnapshots(entities.ResolutionRaw, entities.ResolutionHour, hourCutoff)
+	if err != nil {
+		return err
+	}
+	sc.logger.Infof("Snapshot compaction: %d raw snapshots before %s rolled into %d hourly", removed, hourCutoff.Format(time.RFC3339), written)
+
+	dayCutoff := repositories.BucketStart(now.Add(-sc.hourlyRetention), repositories.BucketDay)
+	written, removed, err = sc.snapshotRepo.RollupSnapshots(entities.ResolutionHour, entities.ResolutionDay, dayCutoff)
+	if err != nil {
+		return err
+	}
+	sc.logger.Infof("Snapshot compaction: %d hourly snapshots before %s rolled into %d daily", removed, dayCutoff.Format(time.RFC3339), written)
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/stream"
+)
+
+// streamTicketTTL is how long a stream ticket can wait to be redeemed
+const streamTicketTTL = 30 * time.Second
+
+type IStreamService interface {
+	Connect(userID string, addressParams []string) (*stream.Client, error)
+	Disconnect(client *stream.Client)
+	IssueTicket(userID string, tokenExpiresAt *time.Time) (string, time.Time, error)
+}
+
+// StreamService opens live update streams on the user's own wallets
+type StreamService struct {
+	logger     *logs.Logger
+	walletRepo repositories.IWalletRepository
+	ticketRepo repositories.IStreamTicketRepository
+	hub        *stream.Hub
+}
+
+func NewStreamService(
+	logger *logs.Logger,
+	walletRepo repositories.IWalletRepository,
+	ticketRepo repositories.IStreamTicketRepository,
+	hub *stream.Hub,
+) *StreamService {
+	return &StreamService{
+		logger:     logger,
+		walletRepo: walletRepo,
+		ticketRepo: ticketRepo,
+		hub:        hub,
+	}
+}
+
+// IssueTicket creates a single-use ticket that opens one stream for the user in place of the
+// bearer token, which then stays out of URLs and access logs
+func (ss *StreamService) IssueTicket(userID string, tokenExpiresAt *time.Time) (string, time.Time, error) {
+	ticketBytes := make([]byte, 32)
+	if _, err := rand.Read(ticketBytes); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate stream ticket: %w", err)
+	}
+	ticket := hex.EncodeToString(ticketBytes)
+
+	expiresAt := time.Now().UTC().Add(streamTicketTTL)
+	if err := ss.ticketRepo.SaveTicket(&entities.StreamTicket{
+		ID:             streamTicketID(ticket),
+		UserID:         userID,
+		TokenExpiresAt: tokenExpiresAt,
+		ExpiresAt:      expiresAt,
+	}); err != nil {
+		ss.logger.Errorf("Error saving stream ticket: %v", err)
+		return "", time.Time{}, err
+	}
+	return ticket, expiresAt, nil
+}
+
+// RedeemTicket spends a ticket, returning nil when it is unknown, expired or already used
+func (ss *StreamService) RedeemTicket(ticket string) (*entities.StreamTicket, error) {
+	return ss.ticketRepo.ConsumeTicket(streamTicketID(ticket), time.Now().UTC())
+}
+
+func streamTicketID(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+	return hex.EncodeToString(sum[:])
+}
+
+// Connect registers a stream on the given wallets, or on every wallet the user tracks when none
+// are given. The set is fixed for the life of the stream; wallets added later need a reconnect.
+func (ss *StreamService) Connect(userID string, addressParams []string) (*stream.Client, error) {
+	var wallets []entities.WalletRef
+	if len(addressParams) == 0 {
+		tracked, err := ss.walletRepo.GetWalletsByUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range tracked {
+			wallets = append(wallets, walletRef(w.Blockchain, w.Address))
+		}
+	} else {
+		seen := make(map[string]bool, len(addressParams))
+		for _, addressParam := range addressParams {
+			bc, addr, err := parseAddressParam(addressParam)
+			if err != nil {
+				return nil, err
+			}
+			if seen[bc+"."+addr] {
+				continue
+			}
+			seen[bc+"."+addr] = true
+
+			wallet, err := ss.walletRepo.GetWallet(userID, bc, addr)
+			if err != nil {
+				return nil, err
+			}
+			if wallet == nil {
+				return nil, ErrWalletNotFound
+			}
+			wallets = append(wallets, walletRef(bc, addr))
+		}
+	}
+
+	return ss.hub.Register(userID, wallets), nil
+}
+
+func (ss *StreamService) Disconnect(client *stream.Client) {
+	ss.hub.Unregister(client)
+}
+
+func walletRef(blockchain, address string) entities.WalletRef {
+	return entities.WalletRef{
+		Blockchain: blockchain,
+		Address:    address,
+		CAIP10:     usecases.FormatCAIP10(blockchain, address),
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/prices"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+)
+
+// taxPricingTimeout bounds the historical price lookups of one report
+const taxPricingTimeout = 2 * time.Minute
+
+// labelFee marks the disposal of the native asset spent on a transaction fee
+const labelFee = "fee"
+
+var ErrInvalidTaxReport = errors.New("invalid tax report request")
+
+type ITaxReportService interface {
+	GetTaxReport(userID string, year int, jurisdiction, method string) (*entities.TaxReport, error)
+}
+
+// TaxReportService builds capital gains reports from the classified transactions of all of a
+// user's wallets. Holdings of an asset are matched across wallets and chains, and every incoming
+// leg is an acquisition and every outgoing leg a disposal at the market price of the time, except:
+//   - transfers between the user's own wallets and bridge transactions, which only move holdings
+//   - approvals, which move nothing
+//
+// Fees paid in the native asset are disposals of it.
+type TaxReportService struct {
+	logger             *logs.Logger
+	walletRepo         repositories.IWalletRepository
+	transactionService ITransactionService
+	priceOracle        prices.IPriceOracle
+	settingsService    ISettingsService
+	chainRegistry      *chains.Registry
+}
+
+func NewTaxReportService(
+	logger *logs.Logger,
+	walletRepo repositories.IWalletRepository,
+	transactionService ITransactionService,
+	priceOracle prices.IPriceOracle,
+	settingsService ISettingsService,
+	chainRegistry *chains.Registry,
+) *TaxReportService {
+	return &TaxReportService{
+		logger:             logger,
+		walletRepo:         walletRepo,
+		transactionService: transactionService,
+		priceOracle:        priceOracle,
+		settingsService:    settingsService,
+		chainRegistry:      chainRegistry,
+	}
+}
+
+// GetTaxReport lists the user's disposals in the tax year starting in year. An empty jurisdiction
+// uses the default one, and an empty method the user's cost basis setting if the jurisdiction
+// allows it, else the jurisdiction's default.
+func (trs *TaxReportService) GetTaxReport(userID string, year int, jurisdiction, method string) (*entities.TaxReport, error) {
+	if jurisdiction == "" {
+		jurisdiction = usecases.DefaultJurisdiction
+	}
+	j, ok := usecases.GetJurisdiction(jurisdiction)
+	if !ok {
+		codes := make([]string, 0, len(usecases.Jurisdictions))
+		for code := range usecases.Jurisdictions {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return nil, fmt.Errorf("%w: jurisdiction must be one of %s", ErrInvalidTaxReport, strings.Join(codes, ", "))
+	}
+	if year < 2009 || year > time.Now().UTC().Year() {
+		return nil, fmt.Errorf("%w: year must be between 2009 and the current year", ErrInvalidTaxReport)
+	}
+
+	method = strings.ToLower(strings.TrimSpace(method))
+	if method == "" {
+		settings, err := trs.settingsService.GetSettings(userID)
+		if err != nil {
+			return nil, err
+		}
+		method = j.Methods[0]
+		if j.AllowsMethod(settings.CostBasisMethod) {
+			method = settings.CostBasisMethod
+		}
+	}
+	if !j.AllowsMethod(method) {
+		return nil, fmt.Errorf("%w: %s tax reports support %s lot matching", ErrInvalidTaxReport, j.Name, strings.Join(j.Methods, ", "))
+	}
+
+	_, to := j.TaxYear(year)
+	txs, err := trs.transactionService.GetHistory(userID, to)
+	if err != nil {
+		trs.logger.Errorf("Error reading transaction history: %v", err)
+		return nil, err
+	}
+	wallets, err := trs.walletRepo.GetWalletsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := taxEvents(trs.chainRegistry, txs, ownAddresses(wallets))
+	if err := priceEvents(trs.priceOracle, events); err != nil {
+		trs.logger.Errorf("Error reading historical prices: %v", err)
+		return nil, err
+	}
+	return usecases.BuildTaxReport(j, year, method, events)
+}
+
+// taxEvents turns transaction legs into acquisitions and disposals; UnitPrice is left unset
+func taxEvents(chainRegistry *chains.Registry, txs []entities.Transaction, own map[string]bool) []usecases.TaxEvent {
+	var events []usecases.TaxEvent
+	for _, tx := range txs {
+		label := tx.Classification()
+
+		if !tx.Fee.IsZero() {
+			if chain, ok := chainRegistry.Chain(tx.Blockchain); ok {
+				asset := chain.NativeAsset.Asset()
+				events = append(events, taxEvent(tx, asset, new(big.Rat).Neg(tx.Fee.Rat(asset.Decimals)), labelFee))
+			}
+		}
+
+		if tx.Failed || tx.Amount.IsZero() {
+			continue
+		}
+		if label == entities.LabelBridge || label == entities.LabelApprove {
+			continue
+		}
+		quantity := tx.Amount.Rat(tx.Asset.Decimals)
+		switch tx.Direction {
+		case entities.DirectionIn:
+			if own[tx.Blockchain+":"+strings.ToLower(tx.From)] {
+				continue
+			}
+		case entities.DirectionOut:
+			if own[tx.Blockchain+":"+strings.ToLower(tx.To)] {
+				continue
+			}
+			quantity.Neg(quantity)
+		default:
+			continue
+		}
+		events = append(events, taxEvent(tx, tx.Asset, quantity, label))
+	}
+	return events
+}
+
+func taxEvent(tx entities.Transaction, asset entities.Asset, quantity *big.Rat, label string) usecases.TaxEvent {
+	return usecases.TaxEvent{
+		Movement:   usecases.Movement{Timestamp: tx.Timestamp, Quantity: quantity},
+		AssetKey:   taxAssetKey(tx.Blockchain, asset),
+		Asset:      asset,
+		Blockchain: tx.Blockchain,
+		Hash:       tx.Hash,
+		Label:      label,
+	}
+}
+
+// taxAssetKey identifies an asset across chains by its CoinGecko ID when it has one,
+// so holdings bridged to another chain keep their lots
+func taxAssetKey(blockchain string, asset entities.Asset) string {
+	if asset.CoingeckoID != "" {
+		return "cg:" + asset.CoingeckoID
+	}
+	if asset.Address != "" {
+		return blockchain + ":" + strings.ToLower(asset.Address)
+	}
+	return blockchain + ":native:" + asset.Symbol
+}
+
+// priceEvents sets the USD unit price of each event at its time, when one is known
+func priceEvents(priceOracle prices.IPriceOracle, events []usecases.TaxEvent) error {
+	keys := make([]prices.HistoricalKey, 0, len(events))
+	for _, ev := range events {
+		if k, ok := prices.NewHistoricalKey(ev.Blockchain, ev.Asset, ev.Timestamp); ok {
+			keys = append(keys, k)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), taxPricingTimeout)
+	defer cancel()
+	quotes, err := priceOracle.GetHistoricalPrices(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		k, ok := prices.NewHistoricalKey(events[i].Blockchain, events[i].Asset, events[i].Timestamp)
+		if !ok {
+			continue
+		}
+		if price, ok := quotes[k]; ok {
+			events[i].UnitPrice = entities.NewUSDFromFloat(price).Rat()
+		}
+	}
+	return nil
+}
+
+// ownAddresses indexes the user's wallets by chain and lower-cased address
+func ownAddresses(wallets []entities.Wallet) map[string]bool {
+	own := make(map[string]bool, len(wallets))
+	for _, w := range wallets {
+		own[w.Blockchain+":"+strings.ToLower(w.Address)] = true
+	}
+	return own
+}
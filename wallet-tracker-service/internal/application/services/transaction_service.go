@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/contracts"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/providers"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 200
+
+	// transactionSyncTimeout bounds the ingestion of a single wallet
+	transactionSyncTimeout = 5 * time.Minute
+
+	// labelBackfillSize bounds how many stored transactions one sync classifies after the fact
+	labelBackfillSize = 500
+
+	// transactionSyncLeaseName is the job lease that keeps the scheduled sync to one replica,
+	// so history providers see each wallet once per run whatever the replica count
+	transactionSyncLeaseName = "transaction-sync"
+	transactionSyncLeaseTTL  = time.Hour
+)
+
+type ITransactionService interface {
+	GetTransactions(userID, addressParam string, query TransactionQuery) ([]entities.Transaction, string, error)
+	SetTransactionLabel(userID, addressParam, hash, label string) error
+	GetHistory(userID string, to time.Time) ([]entities.Transaction, error)
+	GetWalletHistory(userID string, wallet entities.WalletRef, to time.Time) ([]entities.Transaction, error)
+}
+
+// TransactionQuery filters a transaction listing. Cursor is the next cursor of the previous page.
+type TransactionQuery struct {
+	Token     string
+	Direction string
+	From      time.Time
+	To        time.Time
+	Cursor    string
+	Limit     int
+}
+
+type TransactionService struct {
+	logger              *logs.Logger
+	walletRepo          repositories.IWalletRepository
+	transactionRepo     repositories.ITransactionRepository
+	transactionProvider providers.ITransactionProvider
+	contractRegistry    *contracts.Registry
+	lease               jobLease
+}
+
+func NewTransactionService(
+	logger *logs.Logger,
+	walletRepo repositories.IWalletRepository,
+	transactionRepo repositories.ITransactionRepository,
+	transactionProvider providers.ITransactionProvider,
+	contractRegistry *contracts.Registry,
+	leaseRepo repositories.ILeaseRepository,
+) *TransactionService {
+	return &TransactionService{
+		logger:              logger,
+		walletRepo:          walletRepo,
+		transactionRepo:     transactionRepo,
+		transactionProvider: transactionProvider,
+		contractRegistry:    contractRegistry,
+		lease:               jobLease{repo: leaseRepo, name: transactionSyncLeaseName, ttl: transactionSyncLeaseTTL},
+	}
+}
+
+// SyncWallet ingests the wallet's transfers since its stored cursor and returns how many were new
+func (ts *TransactionService) SyncWallet(ctx context.Context, blockchain, address string) (int64, error) {
+	stored, err := ts.transactionRepo.GetCursor(blockchain, address)
+	if err != nil {
+		return 0, err
+	}
+	cursor := ""
+	if stored != nil {
+		cursor = stored.Cursor
+	}
+
+	txs, next, err := ts.transactionProvider.GetTransactions(ctx, blockchain, address, cursor)
+	if err != nil {
+		return 0, err
+	}
+	ts.classify(blockchain, txs)
+	created, err := ts.transactionRepo.SaveTransactions(txs)
+	if err != nil {
+		return 0, err
+	}
+
+	// The cursor only moves once the transfers before it are stored
+	if next != cursor {
+		if err := ts.transactionRepo.SaveCursor(&entities.TransactionCursor{
+			Blockchain: blockchain,
+			Address:    address,
+			Cursor:     next,
+		}); err != nil {
+			return created, err
+		}
+	}
+
+	// Transfers stored before classification existed, or before the contract table knew their contract
+	if err := ts.labelStored(blockchain, address); err != nil {
+		ts.logger.Warnf("Could not label stored transactions of %s.%s: %v", blockchain, address, err)
+	}
+	return created, nil
+}
+
+// classify labels the legs in place, per transaction hash
+func (ts *TransactionService) classify(blockchain string, txs []entities.Transaction) {
+	lookup := func(address string) (entities.KnownContract, bool) {
+		return ts.contractRegistry.Lookup(blockchain, address)
+	}
+
+	byHash := make(map[string][]int)
+	for i, tx := range txs {
+		byHash[tx.Hash] = append(byHash[tx.Hash], i)
+	}
+	for _, indexes := range byHash {
+		legs := make([]entities.Transaction, 0, len(indexes))
+		for _, i := range indexes {
+			legs = append(legs, txs[i])
+		}
+		label, protocol := usecases.ClassifyTransaction(legs, lookup)
+		for _, i := range indexes {
+			txs[i].Label = label
+			txs[i].Protocol = protocol
+		}
+	}
+}
+
+func (ts *TransactionService) labelStored(blockchain, address string) error {
+	txs, err := ts.transactionRepo.GetUnlabeledTransactions(blockchain, address, labelBackfillSize)
+	if err != nil {
+		return err
+	}
+	ts.classify(blockchain, txs)
+
+	labeled := make(map[string]bool)
+	for _, tx := range txs {
+		if labeled[tx.Hash] {
+			continue
+		}
+		labeled[tx.Hash] = true
+		if err := ts.transactionRepo.SetLabel(blockchain, address, tx.Hash, tx.Label, tx.Protocol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncAll ingests new transfers for every tracked wallet; wallets tracked by several users are synced once.
+// A lease keeps runs to one replica at a time.
+func (ts *TransactionService) SyncAll() error {
+	lease, acquired, err := ts.lease.acquire()
+	if err != nil {
+		return fmt.Errorf("could not take the transaction sync lease: %w", err)
+	}
+	if !acquired {
+		if lease != nil {
+			ts.logger.Infof("Transaction sync skipped, %s holds the lease until %s", lease.Holder, lease.ExpiresAt.Format(time.RFC3339))
+		}
+		return nil
+	}
+	defer func() {
+		if err := ts.lease.release(); err != nil {
+			ts.logger.Warnf("Transaction sync could not release its lease: %v", err)
+		}
+	}()
+
+	wallets, err := ts.walletRepo.GetAllWallets()
+	if err != nil {
+		return err
+	}
+
+	// No wallet starts once its sync could outlive the lease; the rest resume from their
+	// cursors on the next run
+	deadline := time.Now().Add(transactionSyncLeaseTTL - transactionSyncTimeout)
+	seen := make(map[string]bool)
+	for i, w := range wallets {
+		key := w.Blockchain + "." + w.Address
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if time.Now().After(deadline) {
+			ts.logger.Warnf("Transaction sync stopped at its deadline with %d wallets left for the next run", len(wallets)-i)
+			break
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), transactionSyncTimeout)
+		created, err := ts.SyncWallet(ctx, w.Blockchain, w.Address)
+		cancel()
+		if err != nil {
+			if !errors.Is(err, providers.ErrTransactionsUnsupported) {
+				ts.logger.Errorf("Transaction sync for wallet %s: %v", key, err)
+			}
+			continue
+		}
+		if created > 0 {
+			ts.logger.Infof("Stored %d new transfers for wallet %s", created, key)
+		}
+	}
+	return nil
+}
+
+// GetTransactions lists the stored transfers of one wallet, or of every wallet of the user when
+// addressParam is empty. It returns the cursor of the next page, empty on the last page.
+func (ts *TransactionService) GetTransactions(userID, addressParam string, query TransactionQuery) ([]entities.Transaction, string, error) {
+	switch query.Direction {
+	case "", entities.DirectionIn, entities.DirectionOut, entities.DirectionSelf:
+	default:
+		return nil, "", fmt.Errorf("%w: direction must be in, out or self", ErrInvalidWallet)
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultTransactionPageSize
+	}
+	if query.Limit > maxTransactionPageSize {
+		query.Limit = maxTransactionPageSize
+	}
+
+	filter := repositories.TransactionFilter{
+		Token:     strings.TrimSpace(query.Token),
+		Direction: query.Direction,
+		From:      query.From,
+		To:        query.To,
+		Limit:     query.Limit,
+	}
+	if query.Cursor != "" {
+		after, err := decodeTransactionCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = after
+	}
+
+	var wallets []entities.WalletRef
+	if addressParam != "" {
+		bc, addr, err := parseAddressParam(addressParam)
+		if err != nil {
+			return nil, "", err
+		}
+		if w, err := ts.walletRepo.GetWallet(userID, bc, addr); err != nil || w == nil {
+			if err != nil {
+				return nil, "", err
+			}
+			return nil, "", ErrWalletNotFound
+		}
+		wallets = append(wallets, entities.WalletRef{Blockchain: bc, Address: addr})
+	} else {
+		tracked, err := ts.walletRepo.GetWalletsByUser(userID)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, w := range tracked {
+			wallets = append(wallets, entities.WalletRef{Blockchain: w.Blockchain, Address: w.Address})
+		}
+	}
+
+	txs, err := ts.transactionRepo.GetTransactions(wallets, filter)
+	if err != nil {
+		ts.logger.Errorf("Error listing transactions: %v", err)
+		return nil, "", err
+	}
+	if err := ts.applyManualLabels(userID, wallets, txs); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(txs) == query.Limit {
+		last := txs[len(txs)-1]
+		next = encodeTransactionCursor(repositories.TransactionPageKey{Timestamp: last.Timestamp, ID: last.ID})
+	}
+	return txs, next, nil
+}
+
+// GetHistory returns every transaction of the user's wallets before to, oldest first, with the
+// user's manual labels applied
+func (ts *TransactionService) GetHistory(userID string, to time.Time) ([]entities.Transaction, error) {
+	tracked, err := ts.walletRepo.GetWalletsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	wallets := make([]entities.WalletRef, 0, len(tracked))
+	for _, w := range tracked {
+		wallets = append(wallets, entities.WalletRef{Blockchain: w.Blockchain, Address: w.Address})
+	}
+
+	txs, err := ts.transactionRepo.GetTransactionHistory(wallets, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.applyManualLabels(userID, wallets, txs); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// GetWalletHistory returns every transaction of one of the user's wallets before to, oldest first,
+// with the user's manual labels applied
+func (ts *TransactionService) GetWalletHistory(userID string, wallet entities.WalletRef, to time.Time) ([]entities.Transaction, error) {
+	wallets := []entities.WalletRef{wallet}
+	txs, err := ts.transactionRepo.GetTransactionHistory(wallets, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.applyManualLabels(userID, wallets, txs); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// SetTransactionLabel overrides the label of every leg of a transaction for the user; an empty label
+// goes back to the classifier's
+func (ts *TransactionService) SetTransactionLabel(userID, addressParam, hash, label string) error {
+	bc, addr, err := parseAddressParam(addressParam)
+	if err != nil {
+		return err
+	}
+	hash = strings.TrimSpace(hash)
+	if hash == "" {
+		return fmt.Errorf("%w: hash is required", ErrInvalidWallet)
+	}
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label != "" && !validLabel(label) {
+		return fmt.Errorf("%w: label must be one of %s", ErrInvalidWallet, strings.Join(entities.Labels, ", "))
+	}
+
+	w, err := ts.walletRepo.GetWallet(userID, bc, addr)
+	if err != nil {
+		return err
+	}
+	if w == nil {
+		return ErrWalletNotFound
+	}
+
+	if label == "" {
+		return ts.transactionRepo.DeleteManualLabel(userID, bc, addr, hash)
+	}
+	return ts.transactionRepo.SaveManualLabel(&entities.TransactionLabel{
+		UserID:     userID,
+		Blockchain: bc,
+		Address:    addr,
+		Hash:       hash,
+		Label:      label,
+	})
+}
+
+// applyManualLabels sets ManualLabel on the transactions the user labeled by hand
+func (ts *TransactionService) applyManualLabels(userID string, wallets []entities.WalletRef, txs []entities.Transaction) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	hashes := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash)
+	}
+
+	labels, err := ts.transactionRepo.GetManualLabels(userID, wallets, hashes)
+	if err != nil {
+		return err
+	}
+	byTx := make(map[string]string, len(labels))
+	for _, l := range labels {
+		byTx[l.Blockchain+"."+l.Address+"."+l.Hash] = l.Label
+	}
+	for i := range txs {
+		txs[i].ManualLabel = byTx[txs[i].Blockchain+"."+txs[i].Address+"."+txs[i].Hash]
+	}
+	return nil
+}
+
+func validLabel(label string) bool {
+	for _, l := range entities.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeTransactionCursor makes an opaque page cursor from a sort position
+func encodeTransactionCursor(key repositories.TransactionPageKey) string {
+	raw := fmt.Sprintf("%d:%s", key.Timestamp.UnixMilli(), key.ID.Hex())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTransactionCursor(cursor string) (*repositories.TransactionPageKey, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidWallet)
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	millis, hexID, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, invalid
+	}
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return nil, invalid
+	}
+	return &repositories.TransactionPageKey{Timestamp: time.UnixMilli(ms).UTC(), ID: id}, nil
+}
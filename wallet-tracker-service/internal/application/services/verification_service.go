@@ -0,0 +1,191 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/signatures"
+)
+
+const challengeTTL = 10 * time.Minute
+
+var (
+	ErrChallengeNotFound = errors.New("no pending challenge for this wallet, request a new one")
+	ErrInvalidSignature  = errors.New("invalid signature")
+)
+
+type IVerificationService interface {
+	CreateChallenge(userID, addressParam string) (*entities.WalletChallenge, error)
+	VerifyWallet(userID, addressParam, signature string) (*entities.Wallet, error)
+	GetVerification(userID, addressParam string) (*entities.Wallet, error)
+}
+
+// VerificationService proves that a user controls a tracked wallet with a signed nonce:
+// EIP-191 (in SIWE message format) for EVM chains and ed25519 for Solana
+type VerificationService struct {
+	logger        *logs.Logger
+	walletRepo    repositories.IWalletRepository
+	challengeRepo repositories.IChallengeRepository
+	domain        string
+	uri           string
+}
+
+func NewVerificationService(
+	logger *logs.Logger,
+	walletRepo repositories.IWalletRepository,
+	challengeRepo repositories.IChallengeRepository,
+	domain string,
+	uri string,
+) *VerificationService {
+	return &VerificationService{
+		logger:        logger,
+		walletRepo:    walletRepo,
+		challengeRepo: challengeRepo,
+		domain:        domain,
+		uri:           uri,
+	}
+}
+
+// CreateChallenge issues a nonce and the message the wallet has to sign
+func (vs *VerificationService) CreateChallenge(userID, addressParam string) (*entities.WalletChallenge, error) {
+	wallet, chain, err := vs.trackedWallet(userID, addressParam)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	challenge := &entities.WalletChallenge{
+		UserID:     userID,
+		Blockchain: wallet.Blockchain,
+		Address:    wallet.Address,
+		Nonce:      hex.EncodeToString(nonceBytes),
+		IssuedAt:   now,
+		ExpiresAt:  now.Add(challengeTTL),
+	}
+	challenge.Message = vs.challengeMessage(chain, challenge)
+
+	if err := vs.challengeRepo.SaveChallenge(challenge); err != nil {
+		vs.logger.Errorf("Error saving challenge: %v", err)
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// VerifyWallet checks the signature of the pending challenge and marks the wallet verified.
+// A challenge can be used once, whether or not the signature matches.
+func (vs *VerificationService) VerifyWallet(userID, addressParam, signature string) (*entities.Wallet, error) {
+	wallet, chain, err := vs.trackedWallet(userID, addressParam)
+	if err != nil {
+		return nil, err
+	}
+
+	// The challenge is spent before its signature is checked, so each one gets a single attempt
+	challenge, err := vs.challengeRepo.ConsumeChallenge(userID, wallet.Blockchain, wallet.Address, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, ErrChallengeNotFound
+	}
+
+	sig, err := signatures.DecodeSignature(signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	switch chain.AddressFormat {
+	case addresses.FormatEVM:
+		err = signatures.VerifyEIP191(wallet.Address, challenge.Message, sig)
+	case addresses.FormatSolana:
+		err = signatures.VerifyEd25519(wallet.Address, challenge.Message, sig)
+	}
+	if err != nil {
+		vs.logger.Warnf("Ownership verification failed for %s.%s: %v", wallet.Blockchain, wallet.Address, err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	verifiedAt := time.Now()
+	found, err := vs.walletRepo.SetWalletVerified(userID, wallet.Blockchain, wallet.Address, verifiedAt)
+	if err != nil {
+		vs.logger.Errorf("Error saving wallet: %v", err)
+		return nil, err
+	}
+	if !found {
+		return nil, ErrWalletNotFound
+	}
+	wallet.Verified = true
+	wallet.VerifiedAt = &verifiedAt
+
+	vs.logger.Infof("User %s verified ownership of %s.%s", userID, wallet.Blockchain, wallet.Address)
+	return wallet, nil
+}
+
+// GetVerification returns the tracked wallet with its verification state
+func (vs *VerificationService) GetVerification(userID, addressParam string) (*entities.Wallet, error) {
+	bc, addr, err := parseAddressParam(addressParam)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := vs.walletRepo.GetWallet(userID, bc, addr)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, ErrWalletNotFound
+	}
+	return wallet, nil
+}
+
+// trackedWallet loads the user's wallet and checks that its chain supports signed messages
+func (vs *VerificationService) trackedWallet(userID, addressParam string) (*entities.Wallet, entities.Chain, error) {
+	bc, addr, err := parseAddressParam(addressParam)
+	if err != nil {
+		return nil, entities.Chain{}, err
+	}
+	chain, _ := chains.Get().Chain(bc)
+	if chain.AddressFormat != addresses.FormatEVM && chain.AddressFormat != addresses.FormatSolana {
+		return nil, entities.Chain{}, fmt.Errorf("%w: ownership verification is not supported for %s", ErrInvalidWallet, bc)
+	}
+
+	wallet, err := vs.walletRepo.GetWallet(userID, bc, addr)
+	if err != nil {
+		return nil, entities.Chain{}, err
+	}
+	if wallet == nil {
+		return nil, entities.Chain{}, ErrWalletNotFound
+	}
+	return wallet, chain, nil
+}
+
+// challengeMessage renders an EIP-4361 (Sign-In with Ethereum) message, or its Solana counterpart
+func (vs *VerificationService) challengeMessage(chain entities.Chain, c *entities.WalletChallenge) string {
+	account, chainID := "Ethereum", fmt.Sprintf("%d", chain.EVMChainID)
+	if chain.AddressFormat == addresses.FormatSolana {
+		account, chainID = "Solana", strings.TrimPrefix(chain.CAIP2, "solana:")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s wants you to sign in with your %s account:\n", vs.domain, account)
+	fmt.Fprintf(&b, "%s\n\n", c.Address)
+	fmt.Fprintf(&b, "Prove ownership of this wallet to Panorama Block. This does not authorize any transaction.\n\n")
+	fmt.Fprintf(&b, "URI: %s\n", vs.uri)
+	fmt.Fprintf(&b, "Version: 1\n")
+	fmt.Fprintf(&b, "Chain ID: %s\n", chainID)
+	fmt.Fprintf(&b, "Nonce: %s\n", c.Nonce)
+	fmt.Fprintf(&b, "Issued At: %s\n", c.IssuedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Expiration Time: %s", c.ExpiresAt.Format(time.RFC3339))
+	return b.String()
+}
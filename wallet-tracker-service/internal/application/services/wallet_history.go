@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+)
+
+// maxHistoryPoints bounds the size of a value series
+const maxHistoryPoints = 2000
+
+// GetValueHistory returns the USD value series of one wallet, or of every wallet of the user when addressParam is empty
+func (ws *WalletService) GetValueHistory(userID, addressParam string, from, to time.Time, bucket string) ([]entities.ValuePoint, error) {
+	switch bucket {
+	case repositories.BucketHour, repositories.BucketDay, repositories.BucketWeek:
+	default:
+		return nil, fmt.Errorf("%w: bucket must be hour, day or week", ErrInvalidWallet)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: 'from' must be before 'to'", ErrInvalidWallet)
+	}
+	points := 0
+	for t := repositories.BucketStart(from, bucket); !t.After(to); t = repositories.BucketNext(t, bucket) {
+		if points++; points > maxHistoryPoints {
+			return nil, fmt.Errorf("%w: range too large for %s buckets, at most %d points", ErrInvalidWallet, bucket, maxHistoryPoints)
+		}
+	}
+
+	var wallets []entities.WalletRef
+	if addressParam != "" {
+		bc, addr, err := parseAddressParam(addressParam)
+		if err != nil {
+			return nil, err
+		}
+		if w, err := ws.walletRepo.GetWallet(userID, bc, addr); err != nil || w == nil {
+			if err != nil {
+				return nil, err
+			}
+			return nil, ErrWalletNotFound
+		}
+		wallets = append(wallets, entities.WalletRef{Blockchain: bc, Address: addr})
+	} else {
+		tracked, err := ws.walletRepo.GetWalletsByUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range tracked {
+			wallets = append(wallets, entities.WalletRef{Blockchain: w.Blockchain, Address: w.Address})
+		}
+	}
+
+	series, err := ws.snapshotRepo.GetValueSeries(wallets, from, to, bucket)
+	if err != nil {
+		ws.logger.Errorf("Error building value history: %v", err)
+		return nil, err
+	}
+	return series, nil
+}
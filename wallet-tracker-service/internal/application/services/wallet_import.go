@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+// MaxImportRows bounds a single import request
+const MaxImportRows = 1000
+
+// Import row outcomes
+const (
+	ImportStatusCreated = "created"
+	ImportStatusUpdated = "updated"
+	ImportStatusFailed  = "failed"
+)
+
+// WalletImportResult reports what happened to one imported row (1-based, header excluded)
+type WalletImportResult struct {
+	Row     int    `json:"row"`
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ImportWallets upserts every valid record; invalid rows are reported and do not stop the import.
+// Label and tags of existing wallets are replaced by the file's values.
+func (ws *WalletService) ImportWallets(userID string, records []usecases.WalletRecord) ([]WalletImportResult, error) {
+	if len(records) > MaxImportRows {
+		return nil, fmt.Errorf("%w: at most %d rows per import", ErrInvalidWallet, MaxImportRows)
+	}
+
+	results := make([]WalletImportResult, 0, len(records))
+	for i, rec := range records {
+		result := WalletImportResult{Row: i + 1, Chain: rec.Chain, Address: rec.Address}
+
+		status, err := ws.importRecord(userID, rec, &result)
+		if err != nil {
+			result.Status = ImportStatusFailed
+			result.Error = err.Error()
+		} else {
+			result.Status = status
+		}
+		results = append(results, result)
+	}
+
+	ws.logger.Infof("User %s imported %d wallet rows", userID, len(records))
+	return results, nil
+}
+
+func (ws *WalletService) importRecord(userID string, rec usecases.WalletRecord, result *WalletImportResult) (string, error) {
+	bc := strings.ToUpper(strings.TrimSpace(rec.Chain))
+	if bc == "" || rec.Address == "" {
+		return "", fmt.Errorf("chain and address are required")
+	}
+	// NormalizeAddress runs the same checks as ValidateAddress and also yields the stored form
+	addr, err := NormalizeAddress(bc, rec.Address)
+	if err != nil {
+		return "", err
+	}
+	result.Chain, result.Address = bc, addr
+
+	label, err := normalizeLabel(rec.Label)
+	if err != nil {
+		return "", err
+	}
+	tags, err := normalizeTags(rec.Tags)
+	if err != nil {
+		return "", err
+	}
+
+	wallet, err := ws.walletRepo.GetWallet(userID, bc, addr)
+	if err != nil {
+		return "", err
+	}
+	status := ImportStatusUpdated
+	if wallet == nil {
+		status = ImportStatusCreated
+		wallet = &entities.Wallet{
+			UserID:     userID,
+			Blockchain: bc,
+			Address:    addr,
+			CAIP10:     usecases.FormatCAIP10(bc, addr),
+			CreatedAt:  time.Now(),
+		}
+	}
+	wallet.Label = label
+	wallet.Tags = tags
+
+	if err := ws.walletRepo.SaveWallet(wallet); err != nil {
+		ws.logger.Errorf("Error saving imported wallet: %v", err)
+		return "", fmt.Errorf("failed to save wallet")
+	}
+	return status, nil
+}
+
+// ExportWallets returns the user's tracked wallets in the import file layout
+func (ws *WalletService) ExportWallets(userID string) ([]usecases.WalletRecord, error) {
+	wallets, err := ws.walletRepo.GetWalletsByUser(userID)
+	if err != nil {
+		ws.logger.Errorf("Error fetching wallets: %v", err)
+		return nil, err
+	}
+
+	records := make([]usecases.WalletRecord, 0, len(wallets))
+	for _, w := range wallets {
+		records = append(records, usecases.WalletRecord{
+			Chain:   w.Blockchain,
+			Address: w.Address,
+			Label:   w.Label,
+			Tags:    w.Tags,
+		})
+	}
+	return records, nil
+}
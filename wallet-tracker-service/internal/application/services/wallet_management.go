@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+const (
+	maxLabelLength = 100
+	maxTags        = 20
+	maxTagLength   = 32
+)
+
+var (
+	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrWalletAlreadyExists = errors.New("wallet is already tracked")
+	ErrInvalidWallet       = errors.New("invalid wallet")
+	ErrSnapshotNotFound    = errors.New("no balance snapshot at or before this time")
+)
+
+// WalletUpdate holds the editable fields of a wallet; nil fields are left unchanged
+type WalletUpdate struct {
+	Label *string
+	Tags  *[]string
+}
+
+// AddWallet starts tracking an address for the user. Balances are filled in by the next refresh.
+func (ws *WalletService) AddWallet(userID, addressParam, label string, tags []string) (*entities.Wallet, error) {
+	bc, addr, err := parseAddressParam(addressParam)
+	if err != nil {
+		return nil, err
+	}
+	label, err = normalizeLabel(label)
+	if err != nil {
+		return nil, invalidWallet(err)
+	}
+	tags, err = normalizeTags(tags)
+	if err != nil {
+		return nil, invalidWallet(err)
+	}
+
+	existing, err := ws.walletRepo.GetWallet(userID, bc, addr)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrWalletAlreadyExists
+	}
+
+	wallet := &entities.Wallet{
+		UserID:     userID,
+		Blockchain: bc,
+		Address:    addr,
+		CAIP10:     usecases.FormatCAIP10(bc, addr),
+		Label:      label,
+		Tags:       tags,
+		CreatedAt:  time.Now(),
+	}
+	if err := ws.walletRepo.SaveWallet(wallet); err != nil {
+		ws.logger.Errorf("Error saving wallet: %v", err)
+		return nil, err
+	}
+
+	ws.logger.Infof("User %s started tracking %s.%s", userID, bc, addr)
+	return wallet, nil
+}
+
+// UpdateWallet changes the label and/or tags of a tracked wallet
+func (ws *WalletService) UpdateWallet(userID, addressParam string, update WalletUpdate) (*entities.Wallet, error) {
+	bc, addr, err := parseAddressParam(addressParam)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := ws.walletRepo.GetWallet(userID, bc, addr)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	if update.Label != nil {
+		label, err := normalizeLabel(*update.Label)
+		if err != nil {
+			return nil, invalidWallet(err)
+		}
+		wallet.Label = label
+	}
+	if update.Tags != nil {
+		tags, err := normalizeTags(*update.Tags)
+		if err != nil {
+			return nil, invalidWallet(err)
+		}
+		wallet.Tags = tags
+	}
+
+	if err := ws.walletRepo.SaveWallet(wallet); err != nil {
+		ws.logger.Errorf("Error saving wallet: %v", err)
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// DeleteWallet stops tracking a wallet for the user. Shared balance data is kept for other users.
+func (ws *WalletService) DeleteWallet(userID, addressParam string) error {
+	bc, addr, err := parseAddressParam(addressParam)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := ws.walletRepo.DeleteWallet(userID, bc, addr)
+	if err != nil {
+		ws.logger.Errorf("Error deleting wallet: %v", err)
+		return err
+	}
+	if !deleted {
+		return ErrWalletNotFound
+	}
+
+	ws.logger.Infof("User %s stopped tracking %s.%s", userID, bc, addr)
+	return nil
+}
+
+// parseAddressParam resolves an address param into its blockchain and canonical address
+func parseAddressParam(addressParam string) (string, string, error) {
+	bc, addr, err := usecases.ParseBlockchainAndAddress(addressParam)
+	if err != nil {
+		return "", "", invalidWallet(err)
+	}
+	addr, err = NormalizeAddress(bc, addr)
+	if err != nil {
+		return "", "", invalidWallet(err)
+	}
+	return bc, addr, nil
+}
+
+// invalidWallet marks a validation error so handlers can answer 400
+func invalidWallet(err error) error {
+	return fmt.Errorf("%w: %v", ErrInvalidWallet, err)
+}
+
+func normalizeLabel(label string) (string, error) {
+	label = strings.TrimSpace(label)
+	if len(label) > maxLabelLength {
+		return "", fmt.Errorf("label must be at most %d characters", maxLabelLength)
+	}
+	return label, nil
+}
+
+// normalizeTags trims and lower-cases tags, dropping empty and duplicate entries
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return nil, fmt.Errorf("tag '%s' must be at most %d characters", tag, maxTagLength)
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	if len(result) > maxTags {
+		return nil, fmt.Errorf("a wallet can have at most %d tags", maxTags)
+	}
+	return result, nil
+}
@@ -4,131 +4,230 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
 	"time"
 
 	"github.com/avast/retry-go"
 	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
 	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/events"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/prices"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/providers"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
 	"github.com/redis/go-redis/v9"
 )
 
-var SupportedBlockchains = map[string]bool{
-	"BSC":         true,
-	"ETH":         true,
-	"POLYGON":     true,
-	"SOLANA":      true,
-	"AVAX_CCHAIN": true,
-	"OPTIMISM":    true,
-	"ARBITRUM":    true,
-	"FANTOM":      true,
-	"TRON":        true,
-	"BASE":        true,
-	"CELO":        true,
-	"BTC":         true,
-}
+// valueChangeEventPercent is the smallest move of a wallet's USD value between two refreshes that
+// raises a value.changed event
+const valueChangeEventPercent = 1.0
 
 type IWalletService interface {
 	FetchAndStoreBalance(userID, addressParam string) ([]entities.Wallet, error)
+	RefreshBalance(userID, addressParam string) ([]entities.Wallet, error)
 	GetAllAddresses(userID string) ([]string, error)
+	GetAllCAIP10Addresses(userID string) ([]string, error)
 	GetWalletTokens(userID, addressParam string, page, limit int, symbol string) ([]entities.Balance, error)
 	GetWalletBalances(userID, bc, addr string) (*entities.WalletBalances, error)
+	GetBalancesAt(userID, addressParam string, at time.Time) (*entities.BalanceSnapshot, error)
+	GetValueHistory(userID, addressParam string, from, to time.Time, bucket string) ([]entities.ValuePoint, error)
+	GetWalletUTXOs(userID, addressParam string) (map[string][]entities.UTXO, error)
+	AddWallet(userID, addressParam, label string, tags []string) (*entities.Wallet, error)
+	UpdateWallet(userID, addressParam string, update WalletUpdate) (*entities.Wallet, error)
+	DeleteWallet(userID, addressParam string) error
+	ImportWallets(userID string, records []usecases.WalletRecord) ([]WalletImportResult, error)
+	ExportWallets(userID string) ([]usecases.WalletRecord, error)
 }
 
 type WalletService struct {
-	logger      *logs.Logger
-	walletRepo  repositories.IWalletRepository
-	balanceRepo repositories.IBalanceRepository
-	redisClient *redis.Client
+	logger          *logs.Logger
+	walletRepo      repositories.IWalletRepository
+	balanceRepo     repositories.IBalanceRepository
+	snapshotRepo    repositories.ISnapshotRepository
+	balanceProvider providers.IBalanceProvider
+	priceOracle     prices.IPriceOracle
+	redisClient     *redis.Client
+	eventBus        events.IEventBus
 }
 
 func NewWalletService(
 	logger *logs.Logger,
 	walletRepo repositories.IWalletRepository,
 	balanceRepo repositories.IBalanceRepository,
+	snapshotRepo repositories.ISnapshotRepository,
+	balanceProvider providers.IBalanceProvider,
+	priceOracle prices.IPriceOracle,
 	redisClient *redis.Client,
+	eventBus events.IEventBus,
 ) *WalletService {
 	return &WalletService{
-		logger:      logger,
-		walletRepo:  walletRepo,
-		balanceRepo: balanceRepo,
-		redisClient: redisClient,
+		logger:          logger,
+		walletRepo:      walletRepo,
+		balanceRepo:     balanceRepo,
+		snapshotRepo:    snapshotRepo,
+		balanceProvider: balanceProvider,
+		priceOracle:     priceOracle,
+		redisClient:     redisClient,
+		eventBus:        eventBus,
 	}
 }
 
 // ValidateAddress validates blockchain and address
 func ValidateAddress(blockchain, address string) error {
-	if !SupportedBlockchains[blockchain] {
-		return fmt.Errorf("blockchain '%s' not supported", blockchain)
+	_, err := NormalizeAddress(blockchain, address)
+	return err
+}
+
+// NormalizeAddress validates the address for its blockchain and returns the canonical form
+// stored in Mongo (EIP-55 checksum for EVM chains, lower-case bech32 for BTC)
+func NormalizeAddress(blockchain, address string) (string, error) {
+	chain, ok := chains.Get().Chain(blockchain)
+	if !ok {
+		return "", fmt.Errorf("blockchain '%s' not supported", blockchain)
 	}
-	// Simple example for BSC and ETH
-	matched, _ := regexp.MatchString(`(?i)^0x[0-9a-fA-F]{40}$`, address)
-	if (blockchain == "BSC" || blockchain == "ETH") && !matched {
-		return fmt.Errorf("invalid address for %s: %s", blockchain, address)
+	normalized, err := addresses.Normalize(chain.AddressFormat, address)
+	if err != nil {
+		return "", fmt.Errorf("invalid address for %s: %w", blockchain, err)
 	}
-	// Additional rules for other blockchains can be added here
-	return nil
+	return normalized, nil
 }
 
-// FetchAndStoreBalance calls external API, saves to Mongo and Redis (cache) if enabled
+// FetchAndStoreBalance calls the balance provider, saves to Mongo and Redis (cache) if enabled.
+// An address the user does not track yet starts being tracked.
 func (ws *WalletService) FetchAndStoreBalance(userID, addressParam string) ([]entities.Wallet, error) {
+	return ws.fetchAndStore(userID, addressParam, true)
+}
+
+// RefreshBalance fetches and stores the balances of a tracked wallet. It never recreates a
+// wallet deleted in the meantime, and returns ErrWalletNotFound for one.
+func (ws *WalletService) RefreshBalance(userID, addressParam string) ([]entities.Wallet, error) {
+	return ws.fetchAndStore(userID, addressParam, false)
+}
+
+func (ws *WalletService) fetchAndStore(userID, addressParam string, track bool) ([]entities.Wallet, error) {
 	ws.logger.Infof("Fetching wallet details for user %s: %s", userID, addressParam)
 
 	bc, addr, parseErr := usecases.ParseBlockchainAndAddress(addressParam)
 	if parseErr != nil {
 		return nil, parseErr
 	}
-	if err := ValidateAddress(bc, addr); err != nil {
+	addr, err := NormalizeAddress(bc, addr)
+	if err != nil {
 		return nil, err
 	}
 
-	// 1) Try to fetch from cache
+	// 1) Provider balances are cached per address and shared by every user tracking it.
+	// User fields are never cached; they come from the caller's own wallet document.
 	redisKey := fmt.Sprintf("balance:%s:%s", bc, addr)
-	if ws.redisClient != nil {
-		cached, cacheErr := ws.redisClient.Get(context.Background(), redisKey).Result()
-		if cacheErr == nil && cached != "" {
-			var cachedRes []entities.Wallet
-			if err := json.Unmarshal([]byte(cached), &cachedRes); err == nil {
-				ws.logger.Infof("Returning data from Redis cache for %s", addressParam)
-				return cachedRes, nil
+	wallets, cached := ws.cachedBalances(redisKey)
+	unpriced := false
+	if cached {
+		ws.logger.Infof("Using cached balances from Redis for %s", addressParam)
+	} else {
+		// 2) Call balance provider with retry
+		err = retry.Do(
+			func() error {
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+
+				res, callErr := ws.balanceProvider.GetWalletBalance(ctx, bc, addr)
+				if callErr != nil {
+					return callErr
+				}
+				wallets = res
+				return nil
+			},
+			retry.Attempts(3), // tries up to 3x
+			retry.Delay(2*time.Second),
+		)
+
+		if err != nil {
+			ws.logger.Errorf("Failed to fetch wallet data after retries: %v", err)
+			return nil, fmt.Errorf("failed to fetch wallet data: %w", err)
+		}
+
+		// 3) Value balances in USD so every refresh is priced the same way
+		if ws.priceOracle != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := ws.priceOracle.PriceWallets(ctx, wallets); err != nil {
+				ws.logger.Warnf("Failed to price wallet %s: %v", addressParam, err)
+				unpriced = true
 			}
+			cancel()
 		}
-	}
 
-	// 2) Call external API with retry
-	var apiResponse *usecases.WalletAPIResponse
-	err := retry.Do(
-		func() error {
-			res, callErr := usecases.GetWalletBalance(addressParam, ws.logger)
-			if callErr != nil {
-				return callErr
+		// Store the canonical address so case variants map to the same document
+		for i := range wallets {
+			if wallets[i].Blockchain == bc {
+				wallets[i].Address = addr
 			}
-			apiResponse = res
-			return nil
-		},
-		retry.Attempts(3), // tries up to 3x
-		retry.Delay(2*time.Second),
-	)
+		}
 
-	if err != nil {
-		ws.logger.Errorf("Failed to fetch wallet data after retries: %v", err)
-		return nil, fmt.Errorf("failed to fetch wallet data: %w", err)
+		// 4) Cache in Redis if available, before user fields are merged in. Balances left
+		// unpriced are not, so the next refresh prices them again.
+		if ws.redisClient != nil && len(wallets) > 0 && !unpriced {
+			jsonData, jsonErr := json.Marshal(providerBalances(wallets))
+			if jsonErr == nil {
+				ws.redisClient.Set(context.Background(), redisKey, jsonData, 5*time.Minute)
+			}
+		}
 	}
 
-	// 3) Process and save to MongoDB
-	wallets := apiResponse.Wallets
+	// 5) Merge into the caller's wallet documents and save to MongoDB
+	stored := 0
 	for i := range wallets {
+		wallets[i].CAIP10 = usecases.FormatCAIP10(wallets[i].Blockchain, wallets[i].Address)
 		wallets[i].UserID = userID
 		wallets[i].CreatedAt = time.Now()
 		wallets[i].LastUpdated = time.Now()
 
-		if err := ws.walletRepo.SaveWallet(&wallets[i]); err != nil {
-			ws.logger.Errorf("Error saving wallet: %v", err)
+		existing, err := ws.walletRepo.GetWallet(userID, wallets[i].Blockchain, wallets[i].Address)
+		if err != nil {
+			ws.logger.Errorf("Error loading wallet: %v", err)
+			continue
+		}
+		if existing != nil {
+			// Only balances are written, so edits and deletes made meanwhile are not undone
+			wallets[i].ID = existing.ID
+			wallets[i].Label = existing.Label
+			wallets[i].Tags = existing.Tags
+			wallets[i].Verified = existing.Verified
+			wallets[i].VerifiedAt = existing.VerifiedAt
+			wallets[i].CreatedAt = existing.CreatedAt
+
+			updated, err := ws.walletRepo.UpdateWalletBalances(&wallets[i])
+			if err != nil {
+				ws.logger.Errorf("Error saving wallet: %v", err)
+				continue
+			}
+			if !updated {
+				ws.logger.Infof("Wallet %s.%s was deleted during its refresh", wallets[i].Blockchain, wallets[i].Address)
+				continue
+			}
+		} else {
+			if !track {
+				continue
+			}
+			if err := ws.walletRepo.SaveWallet(&wallets[i]); err != nil {
+				ws.logger.Errorf("Error saving wallet: %v", err)
+				continue
+			}
+		}
+		stored++
+
+		// Cached balances were stored, diffed and snapshotted by the refresh that fetched them.
+		// Unpriced ones are not, as their missing value would read as a drop to zero.
+		if cached || unpriced {
 			continue
 		}
 
+		// The stored balances are what the previous refresh saw
+		previous, err := ws.balanceRepo.GetBalancesByWallet(wallets[i].Blockchain, wallets[i].Address)
+		if err != nil {
+			ws.logger.Warnf("Error loading previous balances: %v", err)
+		}
+
 		balances := &entities.WalletBalances{
 			Blockchain: wallets[i].Blockchain,
 			Address:    wallets[i].Address,
@@ -137,20 +236,73 @@ func (ws *WalletService) FetchAndStoreBalance(userID, addressParam string) ([]en
 		}
 		if err := ws.balanceRepo.SaveBalances(balances); err != nil {
 			ws.logger.Errorf("Error saving balances: %v", err)
+		} else if ws.eventBus != nil {
+			var changes []entities.DomainEvent
+			if previous != nil {
+				changes = usecases.DiffBalances(
+					balances.Blockchain, balances.Address, previous.Balances, balances.Balances, balances.UpdatedAt, valueChangeEventPercent,
+				)
+			}
+			ws.eventBus.Publish(append(changes, entities.BalancesRefreshed{
+				WalletEvent: entities.WalletEvent{
+					Type:       entities.EventBalancesRefreshed,
+					Blockchain: balances.Blockchain,
+					Address:    balances.Address,
+					Timestamp:  balances.UpdatedAt,
+				},
+				TotalUSD: wallets[i].Balance,
+				Balances: balances.Balances,
+			})...)
 		}
-	}
 
-	// 4) Cache in Redis if available
-	if ws.redisClient != nil && len(wallets) > 0 {
-		jsonData, jsonErr := json.Marshal(wallets)
-		if jsonErr == nil {
-			ws.redisClient.Set(context.Background(), redisKey, jsonData, 5*time.Minute)
+		snapshot := &entities.BalanceSnapshot{
+			Blockchain: wallets[i].Blockchain,
+			Address:    wallets[i].Address,
+			Timestamp:  balances.UpdatedAt,
+			TotalUSD:   wallets[i].Balance,
+			Balances:   wallets[i].Balances,
+		}
+		if err := ws.snapshotRepo.SaveSnapshot(snapshot); err != nil {
+			ws.logger.Errorf("Error saving balance snapshot: %v", err)
 		}
 	}
 
+	if stored == 0 && !track {
+		return nil, ErrWalletNotFound
+	}
 	return wallets, nil
 }
 
+// cachedBalances returns the provider balances cached for an address, if any
+func (ws *WalletService) cachedBalances(redisKey string) ([]entities.Wallet, bool) {
+	if ws.redisClient == nil {
+		return nil, false
+	}
+	cached, err := ws.redisClient.Get(context.Background(), redisKey).Result()
+	if err != nil || cached == "" {
+		return nil, false
+	}
+	var wallets []entities.Wallet
+	if err := json.Unmarshal([]byte(cached), &wallets); err != nil || len(wallets) == 0 {
+		return nil, false
+	}
+	return wallets, true
+}
+
+// providerBalances keeps only what the balance provider and price oracle set on each wallet
+func providerBalances(wallets []entities.Wallet) []entities.Wallet {
+	out := make([]entities.Wallet, len(wallets))
+	for i, w := range wallets {
+		out[i] = entities.Wallet{
+			Blockchain: w.Blockchain,
+			Address:    w.Address,
+			Balance:    w.Balance,
+			Balances:   w.Balances,
+		}
+	}
+	return out
+}
+
 // GetAllAddresses returns all wallet addresses tracked by the service
 func (ws *WalletService) GetAllAddresses(userID string) ([]string, error) {
 	addresses, err := ws.walletRepo.GetAllAddressesByUser(userID)
@@ -161,9 +313,30 @@ func (ws *WalletService) GetAllAddresses(userID string) ([]string, error) {
 	return addresses, nil
 }
 
+// GetAllCAIP10Addresses returns the tracked wallets of a user as CAIP-10 account IDs
+func (ws *WalletService) GetAllCAIP10Addresses(userID string) ([]string, error) {
+	addrs, err := ws.GetAllAddresses(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(addrs))
+	for _, addressParam := range addrs {
+		bc, addr, err := usecases.ParseBlockchainAndAddress(addressParam)
+		if err != nil {
+			continue
+		}
+		if id := usecases.FormatCAIP10(bc, addr); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 // GetWalletBalances gets the balances for a wallet
 func (ws *WalletService) GetWalletBalances(userID, bc, addr string) (*entities.WalletBalances, error) {
-	if err := ValidateAddress(bc, addr); err != nil {
+	addr, err := NormalizeAddress(bc, addr)
+	if err != nil {
 		return nil, err
 	}
 	if w, err := ws.walletRepo.GetWallet(userID, bc, addr); err != nil || w == nil {
@@ -176,14 +349,39 @@ func (ws *WalletService) GetWalletBalances(userID, bc, addr string) (*entities.W
 	return wb, err
 }
 
+// GetBalancesAt returns the wallet's balances as of the given time, from the latest snapshot not after it
+func (ws *WalletService) GetBalancesAt(userID, addressParam string, at time.Time) (*entities.BalanceSnapshot, error) {
+	bc, addr, err := parseAddressParam(addressParam)
+	if err != nil {
+		return nil, err
+	}
+	if w, err := ws.walletRepo.GetWallet(userID, bc, addr); err != nil || w == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrWalletNotFound
+	}
+
+	snapshot, err := ws.snapshotRepo.GetSnapshotAt(bc, addr, at)
+	if err != nil {
+		ws.logger.Errorf("Error fetching snapshot: %v", err)
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, ErrSnapshotNotFound
+	}
+	return snapshot, nil
+}
+
 // GetWalletTokens gets wallet tokens with pagination and filtering
 func (ws *WalletService) GetWalletTokens(userID, addressParam string, page, limit int, symbol string) ([]entities.Balance, error) {
 	bc, addr, err := usecases.ParseBlockchainAndAddress(addressParam)
 	if err != nil {
 		return nil, err
 	}
-	if errVal := ValidateAddress(bc, addr); errVal != nil {
-		return nil, errVal
+	addr, err = NormalizeAddress(bc, addr)
+	if err != nil {
+		return nil, err
 	}
 
 	if w, err := ws.walletRepo.GetWallet(userID, bc, addr); err != nil || w == nil {
@@ -221,3 +419,31 @@ func (ws *WalletService) GetWalletTokens(userID, addressParam string, page, limi
 
 	return filtered[start:end], nil
 }
+
+// GetWalletUTXOs returns the unspent outputs per address for a tracked UTXO-based wallet
+func (ws *WalletService) GetWalletUTXOs(userID, addressParam string) (map[string][]entities.UTXO, error) {
+	bc, addr, err := usecases.ParseBlockchainAndAddress(addressParam)
+	if err != nil {
+		return nil, err
+	}
+	addr, err = NormalizeAddress(bc, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if w, err := ws.walletRepo.GetWallet(userID, bc, addr); err != nil || w == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("wallet not found")
+	}
+
+	utxoProvider, ok := ws.balanceProvider.(providers.IUTXOProvider)
+	if !ok {
+		return nil, fmt.Errorf("UTXOs not supported for %s", bc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return utxoProvider.GetUTXOs(ctx, bc, addr)
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/events"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/webhooks"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxWebhookSubscriptions = 20
+	maxWebhookURLLength     = 2048
+	webhookSecretBytes      = 32
+
+	// Retries wait webhookBackoffBase, then twice as long after every further failure, up to webhookBackoffMax
+	webhookBackoffBase = time.Minute
+	webhookBackoffMax  = 6 * time.Hour
+
+	// webhookClaimLease hides a claimed delivery from other workers; it outlasts webhookSendTimeout
+	// so a worker that dies mid-send only delays the delivery
+	webhookClaimLease  = 2 * time.Minute
+	webhookSendTimeout = 15 * time.Second
+	webhookWorkers     = 8
+	// webhookBatchSize caps the deliveries sent per run, so a backlog drains over several runs
+	webhookBatchSize = 500
+)
+
+var (
+	ErrWebhookNotFound    = errors.New("webhook subscription not found")
+	ErrInvalidWebhook     = errors.New("invalid webhook subscription")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+)
+
+type IWebhookService interface {
+	CreateSubscription(userID, rawURL string, eventTypes []string, enabled *bool) (*entities.WebhookSubscription, error)
+	GetSubscriptions(userID string) ([]entities.WebhookSubscription, error)
+	GetSubscription(userID, id string) (*entities.WebhookSubscription, error)
+	UpdateSubscription(userID, id string, update WebhookUpdate) (*entities.WebhookSubscription, error)
+	DeleteSubscription(userID, id string) error
+	GetDeadLetters(userID string, limit int) ([]entities.WebhookDelivery, error)
+	ReplayDeadLetter(userID, id string) (*entities.WebhookDelivery, error)
+}
+
+// WebhookUpdate holds the editable fields of a subscription; nil fields are left unchanged
+type WebhookUpdate struct {
+	URL        *string
+	EventTypes *[]string
+	Enabled    *bool
+}
+
+// webhookPayload is the JSON body of a delivery
+type webhookPayload struct {
+	ID        string               `json:"id"`
+	Type      string               `json:"type"`
+	CreatedAt time.Time            `json:"createdAt"`
+	Data      entities.DomainEvent `json:"data"`
+}
+
+// WebhookService manages webhook subscriptions and delivers the domain events of the users'
+// wallets to them. Events are queued in the database, so deliveries survive restarts and are
+// retried with exponential backoff until maxAttempts, after which they move to the dead-letter
+// store and can be replayed.
+type WebhookService struct {
+	logger      *logs.Logger
+	webhookRepo repositories.IWebhookRepository
+	walletRepo  repositories.IWalletRepository
+	sender      webhooks.ISender
+	eventBus    events.IEventBus
+	maxAttempts int
+}
+
+func NewWebhookService(
+	logger *logs.Logger,
+	webhookRepo repositories.IWebhookRepository,
+	walletRepo repositories.IWalletRepository,
+	sender webhooks.ISender,
+	eventBus events.IEventBus,
+	maxAttempts int,
+) *WebhookService {
+	return &WebhookService{
+		logger:      logger,
+		webhookRepo: webhookRepo,
+		walletRepo:  walletRepo,
+		sender:      sender,
+		eventBus:    eventBus,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// CreateSubscription registers an endpoint for the events of the user's wallets. The returned
+// subscription carries the signing secret, which is not shown again.
+func (ws *WebhookService) CreateSubscription(userID, rawURL string, eventTypes []string, enabled *bool) (*entities.WebhookSubscription, error) {
+	count, err := ws.webhookRepo.CountSubscriptionsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWebhookSubscriptions {
+		return nil, fmt.Errorf("%w: at most %d webhook subscriptions per user", ErrInvalidWebhook, maxWebhookSubscriptions)
+	}
+
+	endpoint, err := normalizeWebhookURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	types, err := normalizeEventTypes(eventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secretBytes := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	sub := &entities.WebhookSubscription{
+		UserID:     userID,
+		URL:        endpoint,
+		Secret:     "whsec_" + hex.EncodeToString(secretBytes),
+		EventTypes: types,
+		Enabled:    enabled == nil || *enabled,
+		CreatedAt:  time.Now(),
+	}
+	if err := ws.webhookRepo.SaveSubscription(sub); err != nil {
+		ws.logger.Errorf("Error saving webhook subscription: %v", err)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// GetSubscriptions returns the subscriptions of a user, without their secrets
+func (ws *WebhookService) GetSubscriptions(userID string) ([]entities.WebhookSubscription, error) {
+	subs, err := ws.webhookRepo.GetSubscriptionsByUser(userID)
+	if err != nil {
+		ws.logger.Errorf("Error fetching webhook subscriptions: %v", err)
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// GetSubscription returns a single subscription of the user, without its secret
+func (ws *WebhookService) GetSubscription(userID, id string) (*entities.WebhookSubscription, error) {
+	sub, err := ws.subscription(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// UpdateSubscription changes the endpoint, the event filter or whether it is enabled.
+// Deliveries already queued go to the new URL.
+func (ws *WebhookService) UpdateSubscription(userID, id string, update WebhookUpdate) (*entities.WebhookSubscription, error) {
+	sub, err := ws.subscription(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.URL != nil {
+		endpoint, err := normalizeWebhookURL(*update.URL)
+		if err != nil {
+			return nil, err
+		}
+		sub.URL = endpoint
+	}
+	if update.EventTypes != nil {
+		types, err := normalizeEventTypes(*update.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		sub.EventTypes = types
+	}
+	if update.Enabled != nil {
+		sub.Enabled = *update.Enabled
+	}
+
+	if err := ws.webhookRepo.SaveSubscription(sub); err != nil {
+		ws.logger.Errorf("Error saving webhook subscription: %v", err)
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// DeleteSubscription removes a subscription; its queued deliveries are dropped
+func (ws *WebhookService) DeleteSubscription(userID, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrWebhookNotFound
+	}
+	deleted, err := ws.webhookRepo.DeleteSubscription(userID, objectID)
+	if err != nil {
+		ws.logger.Errorf("Error deleting webhook subscription: %v", err)
+		return err
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// GetDeadLetters returns the user's deliveries that ran out of attempts, most recent first
+func (ws *WebhookService) GetDeadLetters(userID string, limit int) ([]entities.WebhookDelivery, error) {
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+	deliveries, err := ws.webhookRepo.GetDeadLetters(userID, int64(limit))
+	if err != nil {
+		ws.logger.Errorf("Error fetching webhook dead letters: %v", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ReplayDeadLetter puts a dead delivery back in the queue with a fresh set of attempts. It keeps
+// its ID and body, so receivers can recognize an event they already processed.
+func (ws *WebhookService) ReplayDeadLetter(userID, id string) (*entities.WebhookDelivery, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrDeadLetterNotFound
+	}
+	delivery, err := ws.webhookRepo.GetDeadLetter(userID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	delivery.Status = entities.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.DeadAt = nil
+	if err := ws.webhookRepo.EnqueueDeliveries([]entities.WebhookDelivery{*delivery}); err != nil {
+		ws.logger.Errorf("Error requeueing webhook delivery %s: %v", id, err)
+		return nil, err
+	}
+	if _, err := ws.webhookRepo.DeleteDeadLetter(userID, objectID); err != nil {
+		ws.logger.Errorf("Error removing replayed dead letter %s: %v", id, err)
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (ws *WebhookService) subscription(userID, id string) (*entities.WebhookSubscription, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	sub, err := ws.webhookRepo.GetSubscription(userID, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return sub, nil
+}
+
+func normalizeWebhookURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" || len(rawURL) > maxWebhookURLLength {
+		return "", fmt.Errorf("%w: url must be 1 to %d characters", ErrInvalidWebhook, maxWebhookURLLength)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return "", fmt.Errorf("%w: url must be an absolute https URL", ErrInvalidWebhook)
+	}
+	if u.User != nil {
+		return "", fmt.Errorf("%w: url must not contain credentials", ErrInvalidWebhook)
+	}
+	// The sender checks the addresses again when it connects
+	if err := webhooks.CheckDestination(u); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	return u.String(), nil
+}
+
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	known := make(map[string]bool, len(entities.EventTypes))
+	for _, t := range entities.EventTypes {
+		known[t] = true
+	}
+
+	types := []string{}
+	seen := make(map[string]bool, len(eventTypes))
+	for _, t := range eventTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !known[t] {
+			return nil, fmt.Errorf("%w: event types must be among %s", ErrInvalidWebhook, strings.Join(entities.EventTypes, ", "))
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
+
+// Start queues a delivery for every event a subscription wants until the returned function is called
+func (ws *WebhookService) Start() func() {
+	return ws.eventBus.Subscribe(ws.enqueue)
+}
+
+// enqueue queues an event for the subscriptions of the users it concerns: the owner of an alert,
+// or every user tracking the wallet
+func (ws *WebhookService) enqueue(event entities.DomainEvent) {
+	var users []string
+	if alert, ok := event.(entities.AlertTriggered); ok {
+		users = []string{alert.UserID}
+	} else {
+		wallet := event.Wallet()
+		var err error
+		users, err = ws.walletRepo.GetUsersByWallet(wallet.Blockchain, wallet.Address)
+		if err != nil {
+			ws.logger.Errorf("Error resolving users of %s.%s for webhooks: %v", wallet.Blockchain, wallet.Address, err)
+			return
+		}
+	}
+
+	subs, err := ws.webhookRepo.GetEnabledSubscriptionsByUsers(users)
+	if err != nil {
+		ws.logger.Errorf("Error loading webhook subscriptions: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var deliveries []entities.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Wants(event.EventType()) {
+			continue
+		}
+		delivery := entities.WebhookDelivery{
+			ID:             primitive.NewObjectID(),
+			SubscriptionID: sub.ID,
+			UserID:         sub.UserID,
+			URL:            sub.URL,
+			EventType:      event.EventType(),
+			Status:         entities.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+		body, err := json.Marshal(webhookPayload{
+			ID:        delivery.ID.Hex(),
+			Type:      event.EventType(),
+			CreatedAt: now,
+			Data:      event,
+		})
+		if err != nil {
+			ws.logger.Errorf("Error encoding %s webhook: %v", event.EventType(), err)
+			return
+		}
+		delivery.Payload = string(body)
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := ws.webhookRepo.EnqueueDeliveries(deliveries); err != nil {
+		ws.logger.Errorf("Error queueing %d webhook deliveries: %v", len(deliveries), err)
+	}
+}
+
+// DeliverDue sends the deliveries whose next attempt is due, several at a time. Replicas can run
+// it concurrently: each delivery is claimed before it is sent.
+func (ws *WebhookService) DeliverDue() error {
+	jobs := make(chan entities.WebhookDelivery)
+	var wg sync.WaitGroup
+	for i := 0; i < webhookWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range jobs {
+				ws.deliver(delivery)
+			}
+		}()
+	}
+
+	var err error
+	for n := 0; n < webhookBatchSize; n++ {
+		var delivery *entities.WebhookDelivery
+		delivery, err = ws.webhookRepo.ClaimDueDelivery(time.Now(), webhookClaimLease)
+		if err != nil || delivery == nil {
+			break
+		}
+		jobs <- *delivery
+	}
+	close(jobs)
+	wg.Wait()
+	return err
+}
+
+// deliver makes one attempt and records its outcome
+func (ws *WebhookService) deliver(delivery entities.WebhookDelivery) {
+	sub, err := ws.webhookRepo.GetSubscriptionByID(delivery.SubscriptionID)
+	if err != nil {
+		// The claim lease runs out and the delivery is tried again
+		ws.logger.Errorf("Error loading webhook subscription %s: %v", delivery.SubscriptionID.Hex(), err)
+		return
+	}
+	if sub == nil || !sub.Enabled {
+		if err := ws.webhookRepo.DeleteDelivery(delivery.ID); err != nil {
+			ws.logger.Errorf("Error dropping webhook delivery %s: %v", delivery.ID.Hex(), err)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookSendTimeout)
+	defer cancel()
+	statusCode, sendErr := ws.sender.Send(ctx, sub.URL, sub.Secret, delivery.ID.Hex(), delivery.EventType, []byte(delivery.Payload))
+
+	now := time.Now()
+	attempts := delivery.Attempts + 1
+	switch {
+	case sendErr == nil:
+		err = ws.webhookRepo.MarkDelivered(delivery.ID, attempts, statusCode, now)
+	case attempts >= ws.maxAttempts:
+		ws.logger.Warnf("Webhook delivery %s to %s failed %d times, moving it to dead letters: %v",
+			delivery.ID.Hex(), sub.URL, attempts, sendErr)
+		delivery.URL = sub.URL
+		delivery.Status = entities.DeliveryDead
+		delivery.Attempts = attempts
+		delivery.LastError = sendErr.Error()
+		delivery.LastStatusCode = statusCode
+		delivery.DeadAt = &now
+		err = ws.webhookRepo.MoveToDeadLetters(&delivery)
+	default:
+		err = ws.webhookRepo.ScheduleRetry(delivery.ID, attempts, statusCode, sendErr.Error(), now.Add(webhookBackoff(attempts)))
+	}
+	if err != nil {
+		ws.logger.Errorf("Error recording webhook delivery %s: %v", delivery.ID.Hex(), err)
+	}
+}
+
+// webhookBackoff is the wait after the given number of failed attempts, with up to 10% jitter
+// so deliveries that failed together do not retry together
+func webhookBackoff(attempts int) time.Duration {
+	wait := webhookBackoffMax
+	if attempts <= 16 {
+		if d := webhookBackoffBase << (attempts - 1); d < webhookBackoffMax {
+			wait = d
+		}
+	}
+	return wait + time.Duration(mathrand.Int63n(int64(wait/10)+1))
+}
@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+// AssetKey identifies an asset within one wallet: its contract, or the symbol for native coins
+func AssetKey(asset entities.Asset) string {
+	if asset.Address != "" {
+		return strings.ToLower(asset.Address)
+	}
+	return "native:" + asset.Symbol
+}
+
+// DiffBalances compares two refreshes of a wallet and returns the events between them: added and
+// removed tokens and amount changes in asset order, then a ValueChanged when the total USD value
+// moved by at least valueThreshold percent.
+func DiffBalances(blockchain, address string, previous, current []entities.Balance, at time.Time, valueThreshold float64) []entities.DomainEvent {
+	meta := func(eventType string) entities.WalletEvent {
+		return entities.WalletEvent{Type: eventType, Blockchain: blockchain, Address: address, Timestamp: at}
+	}
+
+	before := make(map[string]entities.Balance, len(previous))
+	for _, b := range previous {
+		if !b.Amount.IsZero() {
+			before[AssetKey(b.Asset)] = b
+		}
+	}
+	after := make(map[string]entities.Balance, len(current))
+	for _, b := range current {
+		if !b.Amount.IsZero() {
+			after[AssetKey(b.Asset)] = b
+		}
+	}
+
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var events []entities.DomainEvent
+	for _, key := range keys {
+		old, had := before[key]
+		now, has := after[key]
+		switch {
+		case !had:
+			events = append(events, entities.TokenAdded{WalletEvent: meta(entities.EventTokenAdded), Balance: now})
+		case !has:
+			events = append(events, entities.TokenRemoved{WalletEvent: meta(entities.EventTokenRemoved), Previous: old})
+		default:
+			cmp := now.Amount.Cmp(old.Amount)
+			if cmp == 0 {
+				continue
+			}
+			eventType := entities.EventBalanceIncreased
+			if cmp < 0 {
+				eventType = entities.EventBalanceDecreased
+			}
+			events = append(events, entities.BalanceChanged{
+				WalletEvent:    meta(eventType),
+				Asset:          now.Asset,
+				PreviousAmount: old.Amount,
+				Amount:         now.Amount,
+				PreviousUSD:    old.USDValue,
+				USDValue:       now.USDValue,
+			})
+		}
+	}
+
+	prevTotal, total := totalUSD(previous), totalUSD(current)
+	if !prevTotal.IsZero() {
+		change := total.Sub(prevTotal).Float64() / prevTotal.Float64() * 100
+		if change != 0 && math.Abs(change) >= valueThreshold {
+			events = append(events, entities.ValueChanged{
+				WalletEvent:   meta(entities.EventValueChanged),
+				PreviousUSD:   prevTotal,
+				USDValue:      total,
+				ChangePercent: change,
+			})
+		}
+	}
+	return events
+}
+
+func totalUSD(balances []entities.Balance) entities.USD {
+	total := entities.USD{}
+	for _, b := range balances {
+		total = total.Add(b.USDValue)
+	}
+	return total
+}
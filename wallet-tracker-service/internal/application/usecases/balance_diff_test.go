@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+func balance(symbol, contract string, amount int64, usd float64) entities.Balance {
+	b := entities.NewBalance(entities.Asset{Symbol: symbol, Address: contract}, entities.NewRawAmount(big.NewInt(amount)))
+	b.USDValue = entities.NewUSDFromFloat(usd)
+	return b
+}
+
+func eventTypes(events []entities.DomainEvent) []string {
+	var types []string
+	for _, ev := range events {
+		types = append(types, ev.EventType())
+	}
+	return types
+}
+
+func TestDiffBalances(t *testing.T) {
+	const usdc = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+	tests := []struct {
+		name      string
+		previous  []entities.Balance
+		current   []entities.Balance
+		threshold float64
+		want      []string
+	}{
+		{
+			name:     "unchanged",
+			previous: []entities.Balance{balance("ETH", "", 1, 100)},
+			current:  []entities.Balance{balance("ETH", "", 1, 100)},
+			want:     nil,
+		},
+		{
+			name:     "amounts move both ways",
+			previous: []entities.Balance{balance("ETH", "", 2, 100), balance("USDC", usdc, 5, 100)},
+			current:  []entities.Balance{balance("ETH", "", 1, 100), balance("USDC", usdc, 6, 100)},
+			want:     []string{entities.EventBalanceIncreased, entities.EventBalanceDecreased},
+		},
+		{
+			// A zero amount counts as not held either side
+			name:     "zero amounts are not tokens",
+			previous: []entities.Balance{balance("ETH", "", 1, 0), balance("USDC", usdc, 0, 0)},
+			current:  []entities.Balance{balance("ETH", "", 0, 0), balance("DAI", "0x6b175474e89094c44da98b954eedeac495271d0f", 0, 0)},
+			want:     []string{entities.EventTokenRemoved},
+		},
+		{
+			name:      "token added",
+			previous:  []entities.Balance{balance("ETH", "", 1, 100)},
+			current:   []entities.Balance{balance("ETH", "", 1, 100), balance("USDC", usdc, 5, 0)},
+			threshold: 100,
+			want:      []string{entities.EventTokenAdded},
+		},
+		{
+			// A token named like the native coin is a different asset
+			name:     "native and contract assets are keyed apart",
+			previous: []entities.Balance{balance("ETH", "", 1, 100)},
+			current:  []entities.Balance{balance("ETH", "", 1, 100), balance("ETH", "0x2170ed0880ac9a755fd29b2688956bd959f933f8", 1, 0)},
+			want:     []string{entities.EventTokenAdded},
+		},
+		{
+			name:     "contract addresses compare without case",
+			previous: []entities.Balance{balance("USDC", usdc, 5, 100)},
+			current:  []entities.Balance{balance("USDC", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", 5, 100)},
+			want:     nil,
+		},
+		{
+			name:      "value change at the threshold",
+			previous:  []entities.Balance{balance("ETH", "", 1, 100)},
+			current:   []entities.Balance{balance("ETH", "", 1, 110)},
+			threshold: 10,
+			want:      []string{entities.EventValueChanged},
+		},
+		{
+			name:      "value change under the threshold",
+			previous:  []entities.Balance{balance("ETH", "", 1, 100)},
+			current:   []entities.Balance{balance("ETH", "", 1, 109.99)},
+			threshold: 10,
+			want:      nil,
+		},
+		{
+			name:     "no value change from a zero total",
+			previous: []entities.Balance{balance("ETH", "", 1, 0)},
+			current:  []entities.Balance{balance("ETH", "", 1, 100)},
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := DiffBalances("ETH", "0xabc", tt.previous, tt.current, day(1), tt.threshold)
+			if got := eventTypes(events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+			for _, ev := range events {
+				if ref := ev.Wallet(); ref.Blockchain != "ETH" || ref.Address != "0xabc" || !ev.OccurredAt().Equal(day(1)) {
+					t.Errorf("%s carries wallet %v at %s", ev.EventType(), ref, ev.OccurredAt())
+				}
+			}
+		})
+	}
+}
+
+func TestDiffBalancesValueChange(t *testing.T) {
+	events := DiffBalances("ETH", "0xabc",
+		[]entities.Balance{balance("ETH", "", 1, 200)},
+		[]entities.Balance{balance("ETH", "", 1, 150)},
+		day(1), 5)
+	if len(events) != 1 {
+		t.Fatalf("events = %v, want one value change", eventTypes(events))
+	}
+	change := events[0].(entities.ValueChanged)
+	if change.ChangePercent != -25 || change.PreviousUSD.String() != "200" || change.USDValue.String() != "150" {
+		t.Errorf("value change = %v%% from %s to %s, want -25%% from 200 to 150", change.ChangePercent, change.PreviousUSD, change.USDValue)
+	}
+}
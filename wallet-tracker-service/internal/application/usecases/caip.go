@@ -0,0 +1,51 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+)
+
+// ChainFromCAIP2 returns the internal chain name for a CAIP-2 ID such as "eip155:1"
+func ChainFromCAIP2(caip2 string) (string, error) {
+	chain, ok := chains.Get().ChainByCAIP2(caip2)
+	if !ok {
+		return "", fmt.Errorf("unsupported CAIP-2 chain '%s'", caip2)
+	}
+	return chain.Key, nil
+}
+
+// CAIP2FromChain returns the CAIP-2 ID of an internal chain name
+func CAIP2FromChain(blockchain string) (string, bool) {
+	chain, ok := chains.Get().Chain(blockchain)
+	if !ok || chain.CAIP2 == "" {
+		return "", false
+	}
+	return chain.CAIP2, true
+}
+
+// FormatCAIP10 builds the CAIP-10 account ID of a wallet, or "" when the chain has no CAIP-2 ID
+func FormatCAIP10(blockchain, address string) string {
+	caip2, ok := CAIP2FromChain(blockchain)
+	if !ok {
+		return ""
+	}
+	return caip2 + ":" + address
+}
+
+// maxCAIP10AddressLength is the longest account address CAIP-10 allows
+const maxCAIP10AddressLength = 128
+
+// parseCAIP10 splits "namespace:reference:address" into the internal chain name and address
+func parseCAIP10(accountID string) (string, string, error) {
+	parts := strings.Split(accountID, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" || len(parts[2]) > maxCAIP10AddressLength {
+		return "", "", fmt.Errorf("invalid CAIP-10 account ID, expect namespace:reference:address")
+	}
+	chain, err := ChainFromCAIP2(parts[0] + ":" + parts[1])
+	if err != nil {
+		return "", "", err
+	}
+	return chain, parts[2], nil
+}
@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCAIP10RoundTrip(t *testing.T) {
+	tests := []struct {
+		blockchain string
+		address    string
+		accountID  string
+	}{
+		{"ETH", "0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B", "eip155:1:0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B"},
+		{"BASE", "0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B", "eip155:8453:0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B"},
+		{"SOLANA", "7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV", "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp:7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV"},
+		{"BTC", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", "bip122:000000000019d6689c085ae165831e93:bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"},
+		{"TRON", "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7", "tron:0x2b6653dc:TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.blockchain, func(t *testing.T) {
+			if got := FormatCAIP10(tt.blockchain, tt.address); got != tt.accountID {
+				t.Errorf("FormatCAIP10 = %s, want %s", got, tt.accountID)
+			}
+			blockchain, address, err := ParseBlockchainAndAddress(tt.accountID)
+			if err != nil {
+				t.Fatalf("ParseBlockchainAndAddress: %v", err)
+			}
+			if blockchain != tt.blockchain || address != tt.address {
+				t.Errorf("parsed (%s, %s), want (%s, %s)", blockchain, address, tt.blockchain, tt.address)
+			}
+		})
+	}
+
+	if got := FormatCAIP10("UNKNOWN", "0xabc"); got != "" {
+		t.Errorf("FormatCAIP10 of an unknown chain = %s, want empty", got)
+	}
+}
+
+func TestParseBlockchainAndAddress(t *testing.T) {
+	tests := []struct {
+		param          string
+		wantBlockchain string
+		wantAddress    string
+		wantErr        bool
+	}{
+		{param: "ETH.0xabc", wantBlockchain: "ETH", wantAddress: "0xabc"},
+		{param: "eip155:56:0xabc", wantBlockchain: "BSC", wantAddress: "0xabc"},
+		{param: "ETH", wantErr: true},
+		{param: "ETH.0xabc.def", wantErr: true},
+		{param: "eip155:1", wantErr: true},
+		{param: "eip155::0xabc", wantErr: true},
+		{param: ":1:0xabc", wantErr: true},
+		{param: "eip155:1:", wantErr: true},
+		{param: "eip155:1:0xabc:extra", wantErr: true},
+		{param: "eip155:999999:0xabc", wantErr: true},
+		{param: "cosmos:cosmoshub-4:cosmos1abc", wantErr: true},
+		{param: "eip155:1:" + strings.Repeat("a", 129), wantErr: true},
+	}
+	for _, tt := range tests {
+		blockchain, address, err := ParseBlockchainAndAddress(tt.param)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseBlockchainAndAddress(%q) = (%s, %s), want an error", tt.param, blockchain, address)
+			}
+			continue
+		}
+		if err != nil || blockchain != tt.wantBlockchain || address != tt.wantAddress {
+			t.Errorf("ParseBlockchainAndAddress(%q) = (%s, %s, %v), want (%s, %s)", tt.param, blockchain, address, err, tt.wantBlockchain, tt.wantAddress)
+		}
+	}
+}
+
+func TestChainFromCAIP2(t *testing.T) {
+	if chain, err := ChainFromCAIP2("eip155:137"); err != nil || chain != "POLYGON" {
+		t.Errorf("ChainFromCAIP2(eip155:137) = %s, %v", chain, err)
+	}
+	if _, err := ChainFromCAIP2("eip155:0x1"); err == nil {
+		t.Error("an unknown CAIP-2 ID should fail")
+	}
+	if caip2, ok := CAIP2FromChain("SOLANA"); !ok || caip2 != "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp" {
+		t.Errorf("CAIP2FromChain(SOLANA) = %s, %v", caip2, ok)
+	}
+}
@@ -0,0 +1,248 @@
+package usecases
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Cost basis methods
+const (
+	CostBasisFIFO    = "fifo"
+	CostBasisLIFO    = "lifo"
+	CostBasisHIFO    = "hifo" // highest unit cost first
+	CostBasisAverage = "average"
+)
+
+// DefaultCostBasisMethod is used when the user has not picked one
+const DefaultCostBasisMethod = CostBasisFIFO
+
+// ValidCostBasisMethod reports whether method is a supported cost basis method
+func ValidCostBasisMethod(method string) bool {
+	switch method {
+	case CostBasisFIFO, CostBasisLIFO, CostBasisHIFO, CostBasisAverage:
+		return true
+	default:
+		return false
+	}
+}
+
+// Movement is a change in the held quantity of one asset, valued at the unit price of the time.
+// A positive Quantity is an acquisition, a negative one a disposal.
+type Movement struct {
+	Timestamp time.Time
+	Quantity  *big.Rat
+	UnitPrice *big.Rat
+}
+
+// Lot is an acquisition that has not been fully disposed of.
+// Unpriced lots were acquired without a known price and have a zero UnitCost.
+type Lot struct {
+	Acquired time.Time
+	Quantity *big.Rat
+	UnitCost *big.Rat
+	Unpriced bool
+}
+
+// LotMatch is the part of a lot consumed by a disposal
+type LotMatch struct {
+	Acquired time.Time
+	Quantity *big.Rat
+	Cost     *big.Rat
+	Unpriced bool // the lot, or part of the average pool, had no known price
+}
+
+// LotMatcher decides which open lots a disposal consumes
+type LotMatcher interface {
+	Acquire(lot Lot)
+	// Dispose consumes quantity from the open lots. Any quantity not covered by them is returned
+	// as unmatched and has no known cost.
+	Dispose(quantity *big.Rat) (matches []LotMatch, unmatched *big.Rat)
+	Open() []Lot
+}
+
+// NewLotMatcher returns the matcher for a cost basis method
+func NewLotMatcher(method string) (LotMatcher, error) {
+	switch method {
+	case CostBasisFIFO:
+		return &orderedMatcher{next: oldestLot}, nil
+	case CostBasisLIFO:
+		return &orderedMatcher{next: newestLot}, nil
+	case CostBasisHIFO:
+		return &orderedMatcher{next: costliestLot}, nil
+	case CostBasisAverage:
+		return &averageMatcher{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported cost basis method '%s'", method)
+	}
+}
+
+// orderedMatcher consumes whole lots one at a time, in the order picked by next.
+// Lots are kept in acquisition order.
+type orderedMatcher struct {
+	next func(lots []Lot) int
+	lots []Lot
+}
+
+func oldestLot(lots []Lot) int {
+	return 0
+}
+
+func newestLot(lots []Lot) int {
+	return len(lots) - 1
+}
+
+// costliestLot picks the highest unit cost, the oldest of equal ones
+func costliestLot(lots []Lot) int {
+	best := 0
+	for i := 1; i < len(lots); i++ {
+		if lots[i].UnitCost.Cmp(lots[best].UnitCost) > 0 {
+			best = i
+		}
+	}
+	return best
+}
+
+func (m *orderedMatcher) Acquire(lot Lot) {
+	m.lots = append(m.lots, copyLot(lot))
+}
+
+func (m *orderedMatcher) Dispose(quantity *big.Rat) ([]LotMatch, *big.Rat) {
+	remaining := new(big.Rat).Set(quantity)
+	var matches []LotMatch
+	for remaining.Sign() > 0 && len(m.lots) > 0 {
+		i := m.next(m.lots)
+		lot := &m.lots[i]
+
+		take := lot.Quantity
+		if take.Cmp(remaining) > 0 {
+			take = remaining
+		}
+		take = new(big.Rat).Set(take)
+		matches = append(matches, LotMatch{
+			Acquired: lot.Acquired,
+			Quantity: take,
+			Cost:     new(big.Rat).Mul(take, lot.UnitCost),
+			Unpriced: lot.Unpriced,
+		})
+
+		lot.Quantity = new(big.Rat).Sub(lot.Quantity, take)
+		remaining.Sub(remaining, take)
+		if lot.Quantity.Sign() == 0 {
+			m.lots = append(m.lots[:i], m.lots[i+1:]...)
+		}
+	}
+	return matches, remaining
+}
+
+func (m *orderedMatcher) Open() []Lot {
+	lots := make([]Lot, len(m.lots))
+	for i, lot := range m.lots {
+		lots[i] = copyLot(lot)
+	}
+	return lots
+}
+
+// averageMatcher pools every acquisition into one lot at the weighted average unit cost.
+// The pool keeps the date of its oldest acquisition still held.
+type averageMatcher struct {
+	pool *Lot
+}
+
+func (m *averageMatcher) Acquire(lot Lot) {
+	if m.pool == nil {
+		pooled := copyLot(lot)
+		m.pool = &pooled
+		return
+	}
+	cost := new(big.Rat).Mul(m.pool.Quantity, m.pool.UnitCost)
+	cost.Add(cost, new(big.Rat).Mul(lot.Quantity, lot.UnitCost))
+	m.pool.Quantity = new(big.Rat).Add(m.pool.Quantity, lot.Quantity)
+	m.pool.UnitCost = new(big.Rat).Quo(cost, m.pool.Quantity)
+	if lot.Acquired.Before(m.pool.Acquired) {
+		m.pool.Acquired = lot.Acquired
+	}
+	m.pool.Unpriced = m.pool.Unpriced || lot.Unpriced
+}
+
+func (m *averageMatcher) Dispose(quantity *big.Rat) ([]LotMatch, *big.Rat) {
+	if m.pool == nil {
+		return nil, new(big.Rat).Set(quantity)
+	}
+	take := new(big.Rat).Set(quantity)
+	if take.Cmp(m.pool.Quantity) > 0 {
+		take.Set(m.pool.Quantity)
+	}
+	match := LotMatch{
+		Acquired: m.pool.Acquired,
+		Quantity: take,
+		Cost:     new(big.Rat).Mul(take, m.pool.UnitCost),
+		Unpriced: m.pool.Unpriced,
+	}
+	m.pool.Quantity = new(big.Rat).Sub(m.pool.Quantity, take)
+	if m.pool.Quantity.Sign() == 0 {
+		m.pool = nil
+	}
+	return []LotMatch{match}, new(big.Rat).Sub(quantity, take)
+}
+
+func (m *averageMatcher) Open() []Lot {
+	if m.pool == nil {
+		return nil
+	}
+	return []Lot{copyLot(*m.pool)}
+}
+
+func copyLot(lot Lot) Lot {
+	return Lot{
+		Acquired: lot.Acquired,
+		Quantity: new(big.Rat).Set(lot.Quantity),
+		UnitCost: new(big.Rat).Set(lot.UnitCost),
+		Unpriced: lot.Unpriced,
+	}
+}
+
+// Position is the outcome of replaying an asset's movements through a lot matcher
+type Position struct {
+	Quantity  *big.Rat // still held
+	CostBasis *big.Rat // cost of the quantity still held
+	Proceeds  *big.Rat // value received for everything disposed of
+	Realized  *big.Rat // proceeds minus the cost of what was disposed of
+}
+
+// ComputePosition replays movements in time order with the given cost basis method.
+// Disposals beyond the known holdings are treated as having zero cost.
+func ComputePosition(method string, movements []Movement) (Position, error) {
+	matcher, err := NewLotMatcher(method)
+	if err != nil {
+		return Position{}, err
+	}
+
+	proceeds := new(big.Rat)
+	disposedCost := new(big.Rat)
+	for _, mv := range movements {
+		switch mv.Quantity.Sign() {
+		case 1:
+			matcher.Acquire(Lot{Acquired: mv.Timestamp, Quantity: mv.Quantity, UnitCost: mv.UnitPrice})
+		case -1:
+			quantity := new(big.Rat).Neg(mv.Quantity)
+			proceeds.Add(proceeds, new(big.Rat).Mul(quantity, mv.UnitPrice))
+			matches, _ := matcher.Dispose(quantity)
+			for _, match := range matches {
+				disposedCost.Add(disposedCost, match.Cost)
+			}
+		}
+	}
+
+	position := Position{
+		Quantity:  new(big.Rat),
+		CostBasis: new(big.Rat),
+		Proceeds:  proceeds,
+		Realized:  new(big.Rat).Sub(proceeds, disposedCost),
+	}
+	for _, lot := range matcher.Open() {
+		position.Quantity.Add(position.Quantity, lot.Quantity)
+		position.CostBasis.Add(position.CostBasis, new(big.Rat).Mul(lot.Quantity, lot.UnitCost))
+	}
+	return position, nil
+}
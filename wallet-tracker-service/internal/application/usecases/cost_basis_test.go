@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func rat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("bad rational " + s)
+	}
+	return r
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestComputePosition(t *testing.T) {
+	// Three lots bought at 10, 30 and 20, then 1.5 sold at 40
+	movements := []Movement{
+		{Timestamp: day(1), Quantity: rat("1"), UnitPrice: rat("10")},
+		{Timestamp: day(2), Quantity: rat("1"), UnitPrice: rat("30")},
+		{Timestamp: day(3), Quantity: rat("1"), UnitPrice: rat("20")},
+		{Timestamp: day(4), Quantity: rat("-1.5"), UnitPrice: rat("40")},
+	}
+	tests := []struct {
+		method        string
+		wantCostBasis string
+		wantRealized  string
+	}{
+		{CostBasisFIFO, "35", "35"},    // sells 1@10 and 0.5@30
+		{CostBasisLIFO, "25", "25"},    // sells 1@20 and 0.5@30
+		{CostBasisHIFO, "20", "20"},    // sells 1@30 and 0.5@20
+		{CostBasisAverage, "30", "30"}, // sells 1.5 at the average of 20
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			position, err := ComputePosition(tt.method, movements)
+			if err != nil {
+				t.Fatalf("ComputePosition: %v", err)
+			}
+			if position.Quantity.Cmp(rat("1.5")) != 0 {
+				t.Errorf("quantity = %s, want 1.5", position.Quantity.RatString())
+			}
+			if position.Proceeds.Cmp(rat("60")) != 0 {
+				t.Errorf("proceeds = %s, want 60", position.Proceeds.RatString())
+			}
+			if position.CostBasis.Cmp(rat(tt.wantCostBasis)) != 0 {
+				t.Errorf("cost basis = %s, want %s", position.CostBasis.RatString(), tt.wantCostBasis)
+			}
+			if position.Realized.Cmp(rat(tt.wantRealized)) != 0 {
+				t.Errorf("realized = %s, want %s", position.Realized.RatString(), tt.wantRealized)
+			}
+		})
+	}
+
+	if _, err := ComputePosition("lofo", movements); err == nil {
+		t.Error("an unknown method should fail")
+	}
+}
+
+func TestLotMatcherDispose(t *testing.T) {
+	tests := []struct {
+		method        string
+		wantAcquired  []time.Time
+		wantUnmatched string
+	}{
+		{CostBasisFIFO, []time.Time{day(1), day(2)}, "0.5"},
+		{CostBasisLIFO, []time.Time{day(3), day(2)}, "0.5"},
+		// Equal unit costs go oldest first
+		{CostBasisHIFO, []time.Time{day(2), day(3)}, "0.5"},
+		{CostBasisAverage, []time.Time{day(1)}, "0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			matcher, err := NewLotMatcher(tt.method)
+			if err != nil {
+				t.Fatalf("NewLotMatcher: %v", err)
+			}
+			matcher.Acquire(Lot{Acquired: day(1), Quantity: rat("1"), UnitCost: rat("10")})
+			matcher.Acquire(Lot{Acquired: day(2), Quantity: rat("1"), UnitCost: rat("30")})
+			matcher.Acquire(Lot{Acquired: day(3), Quantity: rat("0.5"), UnitCost: rat("30"), Unpriced: true})
+
+			// The matcher must not keep a reference to the caller's quantity
+			quantity := rat("3")
+			matches, unmatched := matcher.Dispose(quantity)
+			if quantity.Cmp(rat("3")) != 0 {
+				t.Errorf("Dispose changed its argument to %s", quantity.RatString())
+			}
+			if unmatched.Cmp(rat(tt.wantUnmatched)) != 0 {
+				t.Errorf("unmatched = %s, want %s", unmatched.RatString(), tt.wantUnmatched)
+			}
+
+			total := new(big.Rat)
+			unpriced := false
+			for i, match := range matches {
+				total.Add(total, match.Quantity)
+				unpriced = unpriced || match.Unpriced
+				if i < len(tt.wantAcquired) && !match.Acquired.Equal(tt.wantAcquired[i]) {
+					t.Errorf("match %d acquired %s, want %s", i, match.Acquired, tt.wantAcquired[i])
+				}
+			}
+			if total.Cmp(rat("2.5")) != 0 {
+				t.Errorf("matched %s, want 2.5", total.RatString())
+			}
+			if !unpriced {
+				t.Error("no match reported the unpriced lot")
+			}
+			if open := matcher.Open(); len(open) != 0 {
+				t.Errorf("%d lots still open", len(open))
+			}
+		})
+	}
+}
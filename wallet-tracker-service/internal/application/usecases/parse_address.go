@@ -0,0 +1,20 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseBlockchainAndAddress parses an address param in the format "BLOCKCHAIN.ADDRESS"
+// or as a CAIP-10 account ID such as "eip155:1:0xabc..."
+func ParseBlockchainAndAddress(addressParam string) (string, string, error) {
+	if strings.Contains(addressParam, ":") {
+		return parseCAIP10(addressParam)
+	}
+
+	parts := strings.Split(addressParam, ".")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid address format, expect BLOCKCHAIN.ADDRESS or a CAIP-10 account ID")
+	}
+	return parts[0], parts[1], nil
+}
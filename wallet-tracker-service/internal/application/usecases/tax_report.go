@@ -0,0 +1,247 @@
+package usecases
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+// Jurisdiction holds the rules a tax report follows
+type Jurisdiction struct {
+	Code string
+	Name string
+	// The tax year starts on this day; a report for year N covers the tax year starting in N
+	YearStartMonth time.Month
+	YearStartDay   int
+	// Lot matching methods allowed, the first one is the default
+	Methods []string
+	// Months an asset must be held for a long-term disposal, 0 when the distinction does not apply
+	LongTermMonths int
+}
+
+// Jurisdictions supported by tax reports, by code.
+// UK and Canada pool holdings at their average cost (Section 104 pool, adjusted cost base);
+// the UK same-day and 30-day matching rules are not applied.
+var Jurisdictions = map[string]Jurisdiction{
+	"US": {Code: "US", Name: "United States", YearStartMonth: time.January, YearStartDay: 1,
+		Methods: []string{CostBasisFIFO, CostBasisLIFO, CostBasisHIFO}, LongTermMonths: 12},
+	"UK": {Code: "UK", Name: "United Kingdom", YearStartMonth: time.April, YearStartDay: 6,
+		Methods: []string{CostBasisAverage}},
+	"CA": {Code: "CA", Name: "Canada", YearStartMonth: time.January, YearStartDay: 1,
+		Methods: []string{CostBasisAverage}},
+	"DE": {Code: "DE", Name: "Germany", YearStartMonth: time.January, YearStartDay: 1,
+		Methods: []string{CostBasisFIFO, CostBasisLIFO}, LongTermMonths: 12},
+	"AU": {Code: "AU", Name: "Australia", YearStartMonth: time.July, YearStartDay: 1,
+		Methods: []string{CostBasisFIFO, CostBasisLIFO, CostBasisHIFO}, LongTermMonths: 12},
+}
+
+// DefaultJurisdiction is used when a report does not name one
+const DefaultJurisdiction = "US"
+
+// GetJurisdiction looks a jurisdiction up by code, case-insensitively
+func GetJurisdiction(code string) (Jurisdiction, bool) {
+	j, ok := Jurisdictions[strings.ToUpper(strings.TrimSpace(code))]
+	return j, ok
+}
+
+// TaxYear returns the start of the tax year starting in year and the start of the next one, in UTC
+func (j Jurisdiction) TaxYear(year int) (time.Time, time.Time) {
+	start := time.Date(year, j.YearStartMonth, j.YearStartDay, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(1, 0, 0)
+}
+
+// AllowsMethod reports whether the jurisdiction accepts a lot matching method
+func (j Jurisdiction) AllowsMethod(method string) bool {
+	for _, m := range j.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Term classifies a holding period, "" when the jurisdiction does not distinguish them
+func (j Jurisdiction) Term(acquired, sold time.Time) string {
+	if j.LongTermMonths == 0 {
+		return ""
+	}
+	if sold.After(acquired.AddDate(0, j.LongTermMonths, 0)) {
+		return entities.TermLong
+	}
+	return entities.TermShort
+}
+
+// TaxEvent is an acquisition (positive Quantity) or disposal (negative Quantity) of an asset at
+// its market value. Events of the same AssetKey share their lots. UnitPrice is nil when the asset
+// had no known price at the time.
+type TaxEvent struct {
+	Movement
+	AssetKey   string
+	Asset      entities.Asset
+	Blockchain string
+	Hash       string
+	Label      string
+}
+
+// BuildTaxReport replays events in time order through one lot matcher per asset and lists the
+// disposals within the tax year. Acquisitions without a price have zero cost, and the disposals
+// matched against them say so in their note.
+func BuildTaxReport(j Jurisdiction, year int, method string, events []TaxEvent) (*entities.TaxReport, error) {
+	if !j.AllowsMethod(method) {
+		return nil, fmt.Errorf("%s tax reports support %s lot matching", j.Name, strings.Join(j.Methods, ", "))
+	}
+	from, to := j.TaxYear(year)
+	report := &entities.TaxReport{
+		Jurisdiction: j.Code,
+		Year:         year,
+		Method:       method,
+		From:         from,
+		To:           to,
+		Disposals:    []entities.TaxDisposal{},
+	}
+
+	sorted := append([]TaxEvent(nil), events...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		if !sorted[a].Timestamp.Equal(sorted[b].Timestamp) {
+			return sorted[a].Timestamp.Before(sorted[b].Timestamp)
+		}
+		// Acquisitions of a transaction come before its disposals
+		return sorted[a].Quantity.Sign() > sorted[b].Quantity.Sign()
+	})
+
+	matchers := make(map[string]LotMatcher)
+	for _, ev := range sorted {
+		if !ev.Timestamp.Before(to) {
+			break
+		}
+		matcher, ok := matchers[ev.AssetKey]
+		if !ok {
+			var err error
+			if matcher, err = NewLotMatcher(method); err != nil {
+				return nil, err
+			}
+			matchers[ev.AssetKey] = matcher
+		}
+
+		unitPrice := ev.UnitPrice
+		if unitPrice == nil {
+			unitPrice = new(big.Rat)
+		}
+		switch ev.Quantity.Sign() {
+		case 1:
+			matcher.Acquire(Lot{Acquired: ev.Timestamp, Quantity: ev.Quantity, UnitCost: unitPrice, Unpriced: ev.UnitPrice == nil})
+		case -1:
+			quantity := new(big.Rat).Neg(ev.Quantity)
+			matches, unmatched := matcher.Dispose(quantity)
+			if ev.Timestamp.Before(from) {
+				continue
+			}
+			for i := range matches {
+				report.Disposals = append(report.Disposals, disposal(j, ev, unitPrice, matches[i].Quantity, &matches[i]))
+			}
+			if unmatched.Sign() > 0 {
+				report.Disposals = append(report.Disposals, disposal(j, ev, unitPrice, unmatched, nil))
+			}
+		}
+	}
+
+	proceeds, cost := entities.USD{}, entities.USD{}
+	for _, d := range report.Disposals {
+		proceeds = proceeds.Add(d.Proceeds)
+		cost = cost.Add(d.Cost)
+	}
+	report.Proceeds = proceeds
+	report.Cost = cost
+	report.Gain = proceeds.Sub(cost)
+	return report, nil
+}
+
+// disposal builds the report line of a quantity disposed of, matched to a lot or nil when unmatched
+func disposal(j Jurisdiction, ev TaxEvent, unitPrice, quantity *big.Rat, match *LotMatch) entities.TaxDisposal {
+	proceeds := new(big.Rat).Mul(quantity, unitPrice)
+	cost := new(big.Rat)
+	if match != nil {
+		cost = match.Cost
+	}
+	d := entities.TaxDisposal{
+		Asset:      ev.Asset,
+		Blockchain: ev.Blockchain,
+		Quantity:   formatQuantity(quantity, ev.Asset.Decimals),
+		Sold:       ev.Timestamp,
+		Proceeds:   entities.NewUSDFromRat(proceeds),
+		Cost:       entities.NewUSDFromRat(cost),
+		Gain:       entities.NewUSDFromRat(new(big.Rat).Sub(proceeds, cost)),
+		Hash:       ev.Hash,
+		Label:      ev.Label,
+	}
+	var notes []string
+	if match != nil {
+		at := match.Acquired
+		d.Acquired = &at
+		d.Term = j.Term(at, ev.Timestamp)
+		if match.Unpriced {
+			notes = append(notes, "acquired without a price, cost counted as zero")
+		}
+	} else {
+		notes = append(notes, "no matching acquisition, cost unknown")
+	}
+	if ev.UnitPrice == nil {
+		notes = append(notes, "no price at disposal")
+	}
+	d.Note = strings.Join(notes, "; ")
+	return d
+}
+
+// formatQuantity renders a token quantity without trailing zeros
+func formatQuantity(q *big.Rat, decimals int) string {
+	s := q.FloatString(decimals)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+var taxReportCSVHeader = []string{
+	"date_acquired", "date_sold", "asset", "chain", "contract", "quantity",
+	"proceeds_usd", "cost_usd", "gain_usd", "term", "label", "tx_hash", "note",
+}
+
+// WriteTaxReportCSV writes one row per disposal, dates in RFC 3339 UTC
+func WriteTaxReportCSV(w io.Writer, report *entities.TaxReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taxReportCSVHeader); err != nil {
+		return err
+	}
+	for _, d := range report.Disposals {
+		acquired := ""
+		if d.Acquired != nil {
+			acquired = d.Acquired.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			acquired,
+			d.Sold.UTC().Format(time.RFC3339),
+			d.Asset.Symbol,
+			d.Blockchain,
+			d.Asset.Address,
+			d.Quantity,
+			d.Proceeds.Rat().FloatString(2),
+			d.Cost.Rat().FloatString(2),
+			d.Gain.Rat().FloatString(2),
+			d.Term,
+			d.Label,
+			d.Hash,
+			d.Note,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,169 @@
+package usecases
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+var testETH = entities.Asset{Symbol: "ETH", Name: "Ethereum", Decimals: 18}
+
+func taxEvent(at time.Time, quantity, price, hash string) TaxEvent {
+	ev := TaxEvent{
+		Movement:   Movement{Timestamp: at, Quantity: rat(quantity)},
+		AssetKey:   "cg:ethereum",
+		Asset:      testETH,
+		Blockchain: "ETH",
+		Hash:       hash,
+		Label:      entities.LabelSend,
+	}
+	if price != "" {
+		ev.UnitPrice = rat(price)
+	}
+	return ev
+}
+
+func TestJurisdictionTaxYear(t *testing.T) {
+	tests := []struct {
+		code      string
+		wantStart time.Time
+	}{
+		{"US", time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"UK", time.Date(2023, time.April, 6, 0, 0, 0, 0, time.UTC)},
+		{"AU", time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		j, ok := GetJurisdiction(strings.ToLower(tt.code))
+		if !ok {
+			t.Fatalf("jurisdiction %s not found", tt.code)
+		}
+		from, to := j.TaxYear(2023)
+		if !from.Equal(tt.wantStart) || !to.Equal(tt.wantStart.AddDate(1, 0, 0)) {
+			t.Errorf("%s tax year 2023 = %s to %s", tt.code, from, to)
+		}
+	}
+}
+
+func TestBuildTaxReportYearBoundaries(t *testing.T) {
+	tests := []struct {
+		code   string
+		method string
+		start  time.Time // of the 2023 tax year
+	}{
+		{"UK", CostBasisAverage, time.Date(2023, time.April, 6, 0, 0, 0, 0, time.UTC)},
+		{"AU", CostBasisFIFO, time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			j, _ := GetJurisdiction(tt.code)
+			events := []TaxEvent{
+				taxEvent(time.Date(2022, time.January, 10, 0, 0, 0, 0, time.UTC), "4", "100", "buy"),
+				taxEvent(tt.start.Add(-time.Second), "-1", "150", "last-second-of-previous-year"),
+				taxEvent(tt.start, "-1", "200", "first-second-of-year"),
+				taxEvent(tt.start.AddDate(1, 0, 0).Add(-time.Second), "-1", "250", "last-second-of-year"),
+				taxEvent(tt.start.AddDate(1, 0, 0), "-1", "300", "first-second-of-next-year"),
+			}
+
+			report, err := BuildTaxReport(j, 2023, tt.method, events)
+			if err != nil {
+				t.Fatalf("BuildTaxReport: %v", err)
+			}
+			var hashes []string
+			for _, d := range report.Disposals {
+				hashes = append(hashes, d.Hash)
+			}
+			if got := strings.Join(hashes, ","); got != "first-second-of-year,last-second-of-year" {
+				t.Fatalf("disposals = %s", got)
+			}
+			if report.Proceeds.String() != "450" || report.Cost.String() != "200" || report.Gain.String() != "250" {
+				t.Errorf("proceeds %s, cost %s, gain %s", report.Proceeds, report.Cost, report.Gain)
+			}
+
+			first := report.Disposals[0]
+			if first.Acquired == nil || first.Quantity != "1" {
+				t.Fatalf("first disposal = %+v", first)
+			}
+			if wantTerm := map[string]string{"UK": "", "AU": entities.TermLong}[tt.code]; first.Term != wantTerm {
+				t.Errorf("term = %q, want %q", first.Term, wantTerm)
+			}
+		})
+	}
+
+	if _, err := BuildTaxReport(Jurisdictions["UK"], 2023, CostBasisFIFO, nil); err == nil {
+		t.Error("UK reports should reject FIFO")
+	}
+}
+
+func TestBuildTaxReportNotes(t *testing.T) {
+	events := []TaxEvent{
+		taxEvent(day(1), "1", "", "airdrop"),
+		taxEvent(day(2), "1", "50", "buy"),
+		taxEvent(day(3), "-3", "80", "sell"),
+	}
+	report, err := BuildTaxReport(Jurisdictions["US"], 2024, CostBasisFIFO, events)
+	if err != nil {
+		t.Fatalf("BuildTaxReport: %v", err)
+	}
+	if len(report.Disposals) != 3 {
+		t.Fatalf("%d disposals, want 3", len(report.Disposals))
+	}
+
+	unpriced, priced, unmatched := report.Disposals[0], report.Disposals[1], report.Disposals[2]
+	if unpriced.Cost.String() != "0" || !strings.Contains(unpriced.Note, "acquired without a price") {
+		t.Errorf("disposal of the unpriced lot = cost %s, note %q", unpriced.Cost, unpriced.Note)
+	}
+	if priced.Cost.String() != "50" || priced.Note != "" {
+		t.Errorf("disposal of the priced lot = cost %s, note %q", priced.Cost, priced.Note)
+	}
+	if unmatched.Acquired != nil || !strings.Contains(unmatched.Note, "no matching acquisition") {
+		t.Errorf("unmatched disposal = %+v", unmatched)
+	}
+	if report.Gain.String() != "190" {
+		t.Errorf("gain = %s, want 190", report.Gain)
+	}
+}
+
+func TestWriteTaxReportCSV(t *testing.T) {
+	acquired := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	report := &entities.TaxReport{
+		Disposals: []entities.TaxDisposal{
+			{
+				Asset:      entities.Asset{Symbol: "USDC", Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6},
+				Blockchain: "ETH",
+				Quantity:   "1000.5",
+				Acquired:   &acquired,
+				Sold:       time.Date(2024, time.February, 1, 8, 30, 0, 0, time.FixedZone("CET", 3600)),
+				Proceeds:   entities.NewUSDFromRat(big.NewRat(200101, 200)),
+				Cost:       entities.NewUSDFromRat(big.NewRat(1000, 1)),
+				Gain:       entities.NewUSDFromRat(big.NewRat(101, 200)),
+				Term:       entities.TermShort,
+				Hash:       "0xabc",
+				Label:      entities.LabelSwap,
+			},
+			{
+				Asset:      testETH,
+				Blockchain: "ETH",
+				Quantity:   "0.1",
+				Sold:       time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+				Hash:       "0xdef",
+				Label:      entities.LabelSend,
+				Note:       "no matching acquisition, cost unknown; no price at disposal",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTaxReportCSV(&buf, report); err != nil {
+		t.Fatalf("WriteTaxReportCSV: %v", err)
+	}
+	want := "date_acquired,date_sold,asset,chain,contract,quantity,proceeds_usd,cost_usd,gain_usd,term,label,tx_hash,note\n" +
+		"2023-03-01T12:00:00Z,2024-02-01T07:30:00Z,USDC,ETH,0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48,1000.5,1000.51,1000.00,0.51,short,swap,0xabc,\n" +
+		",2024-03-01T00:00:00Z,ETH,ETH,,0.1,0.00,0.00,0.00,,send,0xdef,\"no matching acquisition, cost unknown; no price at disposal\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
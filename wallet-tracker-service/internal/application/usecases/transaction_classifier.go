@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"golang.org/x/crypto/sha3"
+)
+
+const zeroEVMAddress = "0x0000000000000000000000000000000000000000"
+
+// methodLabels maps function signatures that mean the same thing on any contract
+var methodLabels = map[string]string{
+	"approve(address,uint256)":                                      entities.LabelApprove,
+	"increaseAllowance(address,uint256)":                            entities.LabelApprove,
+	"setApprovalForAll(address,bool)":                               entities.LabelApprove,
+	"permit(address,address,uint256,uint256,uint8,bytes32,bytes32)": entities.LabelApprove,
+	"approve(address,address,uint160,uint48)":                       entities.LabelApprove, // Permit2
+
+	// Uniswap Universal Router and V2/V3 style routers, including Trader Joe's AVAX variants
+	"execute(bytes,bytes[],uint256)":                                                                   entities.LabelSwap,
+	"execute(bytes,bytes[])":                                                                           entities.LabelSwap,
+	"swapExactTokensForTokens(uint256,uint256,address[],address,uint256)":                              entities.LabelSwap,
+	"swapTokensForExactTokens(uint256,uint256,address[],address,uint256)":                              entities.LabelSwap,
+	"swapExactETHForTokens(uint256,address[],address,uint256)":                                         entities.LabelSwap,
+	"swapETHForExactTokens(uint256,address[],address,uint256)":                                         entities.LabelSwap,
+	"swapExactTokensForETH(uint256,uint256,address[],address,uint256)":                                 entities.LabelSwap,
+	"swapTokensForExactETH(uint256,uint256,address[],address,uint256)":                                 entities.LabelSwap,
+	"swapExactAVAXForTokens(uint256,address[],address,uint256)":                                        entities.LabelSwap,
+	"swapExactTokensForAVAX(uint256,uint256,address[],address,uint256)":                                entities.LabelSwap,
+	"swapExactTokensForTokensSupportingFeeOnTransferTokens(uint256,uint256,address[],address,uint256)": entities.LabelSwap,
+	"swapExactETHForTokensSupportingFeeOnTransferTokens(uint256,address[],address,uint256)":            entities.LabelSwap,
+	"swapExactTokensForETHSupportingFeeOnTransferTokens(uint256,uint256,address[],address,uint256)":    entities.LabelSwap,
+	"exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))":               entities.LabelSwap,
+	"exactInputSingle((address,address,uint24,address,uint256,uint256,uint160))":                       entities.LabelSwap,
+	"exactInput((bytes,address,uint256,uint256,uint256))":                                              entities.LabelSwap,
+	"exactInput((bytes,address,uint256,uint256))":                                                      entities.LabelSwap,
+
+	// Aave-style pools
+	"supply(address,uint256,address,uint16)":         entities.LabelLend,
+	"deposit(address,uint256,address,uint16)":        entities.LabelLend,
+	"withdraw(address,uint256,address)":              entities.LabelWithdraw,
+	"borrow(address,uint256,uint256,uint16,address)": entities.LabelBorrow,
+	"repay(address,uint256,uint256,address)":         entities.LabelRepay,
+
+	// Bridges
+	"depositV3(address,address,address,address,uint256,uint256,uint256,address,uint32,uint32,uint32,bytes)": entities.LabelBridge,
+}
+
+// categoryMethodLabels maps signatures whose meaning depends on the kind of contract called,
+// e.g. mint(uint256) supplies to a Compound-style market but mints on a plain token
+var categoryMethodLabels = map[string]map[string]string{
+	entities.ContractCategoryLending: {
+		"mint(uint256)":                      entities.LabelLend,
+		"mint()":                             entities.LabelLend,
+		"enterMarkets(address[])":            entities.LabelLend,
+		"redeem(uint256)":                    entities.LabelWithdraw,
+		"redeemUnderlying(uint256)":          entities.LabelWithdraw,
+		"exitMarket(address)":                entities.LabelWithdraw,
+		"borrow(uint256)":                    entities.LabelBorrow,
+		"repayBorrow(uint256)":               entities.LabelRepay,
+		"repayBorrow()":                      entities.LabelRepay,
+		"repayBorrowBehalf(address,uint256)": entities.LabelRepay,
+	},
+	entities.ContractCategoryStaking: {
+		"submit(address)":                             entities.LabelStake,
+		"requestWithdrawals(uint256[],address)":       entities.LabelUnstake,
+		"requestWithdrawalsWstETH(uint256[],address)": entities.LabelUnstake,
+		"claimWithdrawal(uint256)":                    entities.LabelUnstake,
+		"claimWithdrawals(uint256[],uint256[])":       entities.LabelUnstake,
+	},
+}
+
+// selectorLabels and categorySelectorLabels are the tables above keyed by 4-byte selector
+var (
+	selectorLabels         = selectorTable(methodLabels)
+	categorySelectorLabels = func() map[string]map[string]string {
+		tables := make(map[string]map[string]string, len(categoryMethodLabels))
+		for category, methods := range categoryMethodLabels {
+			tables[category] = selectorTable(methods)
+		}
+		return tables
+	}()
+)
+
+// tokenTransferSelectors are the plain ERC-20 transfer methods. They send or receive the token
+// whatever its contract is known for, e.g. moving stETH is not staking.
+var tokenTransferSelectors = map[string]bool{
+	MethodSelector("transfer(address,uint256)"):             true,
+	MethodSelector("transferFrom(address,address,uint256)"): true,
+}
+
+// MethodSelector returns the 0x-prefixed 4-byte selector of a function signature
+func MethodSelector(signature string) string {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(signature))
+	return "0x" + hex.EncodeToString(h.Sum(nil)[:4])
+}
+
+func selectorTable(methods map[string]string) map[string]string {
+	table := make(map[string]string, len(methods))
+	for signature, label := range methods {
+		table[MethodSelector(signature)] = label
+	}
+	return table
+}
+
+// ContractLookup finds a known contract by address on the transaction's chain
+type ContractLookup func(address string) (entities.KnownContract, bool)
+
+// ClassifyTransaction labels a transaction from all of its legs for one wallet.
+// It looks at, in order: the method selector, plain token transfers, the known contract
+// called, then the transfers themselves (mint and burn events, assets in and out). It also returns
+// the name of the known contract involved, if any.
+func ClassifyTransaction(legs []entities.Transaction, lookup ContractLookup) (string, string) {
+	var method, contract string
+	for _, leg := range legs {
+		if leg.Method != "" && method == "" {
+			method = strings.ToLower(leg.Method)
+		}
+		if leg.Contract != "" && contract == "" {
+			contract = leg.Contract
+		}
+	}
+
+	known, isKnown := entities.KnownContract{}, false
+	if contract != "" {
+		known, isKnown = lookup(contract)
+	}
+	if !isKnown {
+		// Deposits into a known contract, e.g. a bridge, can be plain transfers
+		for _, leg := range legs {
+			if leg.Direction == entities.DirectionOut && leg.To != "" {
+				if known, isKnown = lookup(leg.To); isKnown {
+					break
+				}
+			}
+		}
+	}
+	protocol := ""
+	if isKnown {
+		protocol = known.Name
+	}
+
+	if label, ok := selectorLabels[method]; ok {
+		return label, protocol
+	}
+	if isKnown {
+		if label, ok := categorySelectorLabels[known.Category][method]; ok {
+			return label, protocol
+		}
+	}
+
+	ins, outs := movedAssets(legs)
+	if tokenTransferSelectors[method] {
+		if len(outs) == 0 && len(ins) > 0 {
+			return entities.LabelReceive, protocol
+		}
+		return entities.LabelSend, protocol
+	}
+	if isKnown {
+		if label := categoryLabel(known.Category, ins, outs); label != "" {
+			return label, protocol
+		}
+	}
+	return flowLabel(legs, ins, outs), protocol
+}
+
+// movedAssets collects the assets that came in and went out, ignoring fee-only legs and failed transactions
+func movedAssets(legs []entities.Transaction) (map[string]bool, map[string]bool) {
+	ins, outs := make(map[string]bool), make(map[string]bool)
+	for _, leg := range legs {
+		if leg.Failed || leg.Amount.IsZero() {
+			continue
+		}
+		switch leg.Direction {
+		case entities.DirectionIn:
+			ins[AssetKey(leg.Asset)] = true
+		case entities.DirectionOut:
+			outs[AssetKey(leg.Asset)] = true
+		}
+	}
+	return ins, outs
+}
+
+// categoryLabel infers the label of a call to a known contract from the direction of funds
+func categoryLabel(category string, ins, outs map[string]bool) string {
+	switch category {
+	case entities.ContractCategoryDEX:
+		return entities.LabelSwap
+	case entities.ContractCategoryBridge:
+		return entities.LabelBridge
+	case entities.ContractCategoryApproval:
+		return entities.LabelApprove
+	case entities.ContractCategoryStaking:
+		switch {
+		case len(outs) > 0 && len(ins) > 0:
+			return entities.LabelSwap // e.g. wrapping stETH
+		case len(outs) > 0:
+			return entities.LabelStake
+		case len(ins) > 0:
+			return entities.LabelUnstake
+		}
+	case entities.ContractCategoryLending:
+		switch {
+		case len(outs) > 0 && len(ins) == 0:
+			return entities.LabelLend
+		case len(ins) > 0 && len(outs) == 0:
+			return entities.LabelWithdraw
+		}
+	}
+	return ""
+}
+
+// flowLabel labels a transaction from its transfers alone. Token transfers from the zero
+// address are mints and transfers to it are burns.
+func flowLabel(legs []entities.Transaction, ins, outs map[string]bool) string {
+	minted, burned := false, false
+	for _, leg := range legs {
+		if leg.Failed || leg.Amount.IsZero() || leg.Asset.Address == "" {
+			continue
+		}
+		if leg.Direction == entities.DirectionIn && strings.EqualFold(leg.From, zeroEVMAddress) {
+			minted = true
+		}
+		if leg.Direction == entities.DirectionOut && strings.EqualFold(leg.To, zeroEVMAddress) {
+			burned = true
+		}
+	}
+
+	switch {
+	case minted && !burned && len(outs) == 0:
+		return entities.LabelMint
+	case burned && !minted && len(ins) == 0:
+		return entities.LabelBurn
+	case len(ins) > 0 && len(outs) > 0:
+		return entities.LabelSwap
+	case len(outs) > 0:
+		return entities.LabelSend
+	case len(ins) > 0:
+		return entities.LabelReceive
+	}
+
+	// Nothing moved: a self transfer or a contract call that only paid gas
+	for _, leg := range legs {
+		if leg.Direction == entities.DirectionSelf {
+			return entities.LabelSend
+		}
+	}
+	return entities.LabelOther
+}
@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+const (
+	testWallet  = "0x1111111111111111111111111111111111111111"
+	testStETH   = "0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84"
+	testRouter  = "0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD"
+	testBridge  = "0x5c7BCd6E7De5423a257D81B442095A1a6ced35C5"
+	testUnknown = "0x2222222222222222222222222222222222222222"
+)
+
+func testLookup(address string) (entities.KnownContract, bool) {
+	switch address {
+	case testStETH:
+		return entities.KnownContract{Name: "Lido stETH", Category: entities.ContractCategoryStaking}, true
+	case testRouter:
+		return entities.KnownContract{Name: "Uniswap Universal Router", Category: entities.ContractCategoryDEX}, true
+	case testBridge:
+		return entities.KnownContract{Name: "Across SpokePool", Category: entities.ContractCategoryBridge}, true
+	}
+	return entities.KnownContract{}, false
+}
+
+func testLeg(direction, contract, method, token string) entities.Transaction {
+	leg := entities.Transaction{
+		Direction: direction,
+		Contract:  contract,
+		Method:    method,
+		Amount:    entities.NewRawAmount(big.NewInt(1000)),
+		Asset:     entities.Asset{Symbol: "ETH", Decimals: 18},
+		From:      testUnknown,
+		To:        testWallet,
+	}
+	if direction == entities.DirectionOut {
+		leg.From, leg.To = testWallet, testUnknown
+	}
+	if token != "" {
+		leg.Asset = entities.Asset{Symbol: "TKN", Address: token, Decimals: 18}
+	}
+	return leg
+}
+
+func TestClassifyTransaction(t *testing.T) {
+	transfer := MethodSelector("transfer(address,uint256)")
+	transferFrom := MethodSelector("transferFrom(address,address,uint256)")
+	execute := MethodSelector("execute(bytes,bytes[],uint256)")
+
+	tests := []struct {
+		name         string
+		legs         []entities.Transaction
+		wantLabel    string
+		wantProtocol string
+	}{
+		{
+			name:         "stETH sent with transfer",
+			legs:         []entities.Transaction{testLeg(entities.DirectionOut, testStETH, transfer, testStETH)},
+			wantLabel:    entities.LabelSend,
+			wantProtocol: "Lido stETH",
+		},
+		{
+			name:         "stETH received with transferFrom",
+			legs:         []entities.Transaction{testLeg(entities.DirectionIn, testStETH, transferFrom, testStETH)},
+			wantLabel:    entities.LabelReceive,
+			wantProtocol: "Lido stETH",
+		},
+		{
+			name:      "unknown token sent",
+			legs:      []entities.Transaction{testLeg(entities.DirectionOut, testUnknown, transfer, testUnknown)},
+			wantLabel: entities.LabelSend,
+		},
+		{
+			name:         "stETH staked without a known selector",
+			legs:         []entities.Transaction{testLeg(entities.DirectionOut, testStETH, "0xdeadbeef", "")},
+			wantLabel:    entities.LabelStake,
+			wantProtocol: "Lido stETH",
+		},
+		{
+			name: "router swap",
+			legs: []entities.Transaction{
+				testLeg(entities.DirectionOut, testRouter, execute, ""),
+				testLeg(entities.DirectionIn, testRouter, execute, testUnknown),
+			},
+			wantLabel:    entities.LabelSwap,
+			wantProtocol: "Uniswap Universal Router",
+		},
+		{
+			name: "native deposit into a bridge",
+			legs: []entities.Transaction{func() entities.Transaction {
+				leg := testLeg(entities.DirectionOut, "", "", "")
+				leg.To = testBridge
+				return leg
+			}()},
+			wantLabel:    entities.LabelBridge,
+			wantProtocol: "Across SpokePool",
+		},
+		{
+			name:      "plain receive",
+			legs:      []entities.Transaction{testLeg(entities.DirectionIn, "", "", "")},
+			wantLabel: entities.LabelReceive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, protocol := ClassifyTransaction(tt.legs, testLookup)
+			if label != tt.wantLabel || protocol != tt.wantProtocol {
+				t.Errorf("ClassifyTransaction = (%s, %s), want (%s, %s)", label, protocol, tt.wantLabel, tt.wantProtocol)
+			}
+		})
+	}
+}
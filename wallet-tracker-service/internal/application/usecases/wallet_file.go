@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Wallet file formats accepted by import and produced by export
+const (
+	WalletFileCSV  = "csv"
+	WalletFileJSON = "json"
+)
+
+// csvTagSeparator joins tags inside the single CSV tags column
+const csvTagSeparator = ";"
+
+var walletCSVHeader = []string{"chain", "address", "label", "tags"}
+
+// WalletRecord is one row of a wallet import/export file
+type WalletRecord struct {
+	Chain   string   `json:"chain"`
+	Address string   `json:"address"`
+	Label   string   `json:"label,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// ParseWalletFile reads wallet records in the given format
+func ParseWalletFile(format string, r io.Reader) ([]WalletRecord, error) {
+	switch format {
+	case WalletFileCSV:
+		return parseWalletCSV(r)
+	case WalletFileJSON:
+		var records []WalletRecord
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, fmt.Errorf("invalid JSON, expect an array of {chain, address, label, tags}: %w", err)
+		}
+		return records, nil
+	default:
+		return nil, fmt.Errorf("unsupported wallet file format '%s'", format)
+	}
+}
+
+// WriteWalletFile writes wallet records in the given format
+func WriteWalletFile(format string, w io.Writer, records []WalletRecord) error {
+	switch format {
+	case WalletFileCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(walletCSVHeader); err != nil {
+			return err
+		}
+		for _, rec := range records {
+			if err := writer.Write([]string{rec.Chain, rec.Address, rec.Label, strings.Join(rec.Tags, csvTagSeparator)}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case WalletFileJSON:
+		if records == nil {
+			records = []WalletRecord{}
+		}
+		return json.NewEncoder(w).Encode(records)
+	default:
+		return fmt.Errorf("unsupported wallet file format '%s'", format)
+	}
+}
+
+// parseWalletCSV reads a CSV with a header row; columns are matched by name so their order is free
+func parseWalletCSV(r io.Reader) ([]WalletRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("empty CSV file")
+		}
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet exports often start with a UTF-8 byte order mark
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"chain", "address"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain '%s'", required)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var records []WalletRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		rec := WalletRecord{
+			Chain:   field(row, "chain"),
+			Address: field(row, "address"),
+			Label:   field(row, "label"),
+		}
+		if tags := field(row, "tags"); tags != "" {
+			rec.Tags = strings.Split(tags, csvTagSeparator)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
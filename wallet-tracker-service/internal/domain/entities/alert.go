@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Alert rule types
+const (
+	AlertValueBelow    = "value_below"    // the wallet's USD value drops below Threshold
+	AlertBalanceChange = "balance_change" // the balance of Token, or of any token, changes
+	AlertPriceChange   = "price_change"   // the price of a held token moves by Percent or more in a day
+)
+
+// AlertTypes lists every alert rule type
+var AlertTypes = []string{AlertValueBelow, AlertBalanceChange, AlertPriceChange}
+
+// AlertRule is a condition on one of the user's wallets that raises an alert when it is met
+type AlertRule struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID          string             `bson:"user_id" json:"user_id"`
+	Name            string             `bson:"name" json:"name"`
+	Type            string             `bson:"type" json:"type"`
+	Blockchain      string             `bson:"blockchain" json:"blockchain"`
+	Address         string             `bson:"address" json:"address"`
+	Token           string             `bson:"token,omitempty" json:"token,omitempty"` // symbol or contract, empty for every token
+	Threshold       USD                `bson:"threshold,omitempty" json:"threshold"`
+	Percent         float64            `bson:"percent,omitempty" json:"percent,omitempty"`
+	CooldownSeconds int64              `bson:"cooldownSeconds" json:"cooldownSeconds"`
+	Enabled         bool               `bson:"enabled" json:"enabled"`
+	// Active records that the condition of a value rule held at the last evaluation, so the
+	// alert is raised once when the value crosses the threshold rather than on every refresh
+	Active          bool       `bson:"active" json:"active"`
+	LastTriggeredAt *time.Time `bson:"lastTriggeredAt,omitempty" json:"lastTriggeredAt,omitempty"`
+	CreatedAt       time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Alert is one triggering of a rule, kept in the user's alert history
+type Alert struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	RuleID      primitive.ObjectID `bson:"rule_id" json:"ruleId"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	RuleName    string             `bson:"ruleName,omitempty" json:"ruleName,omitempty"`
+	Type        string             `bson:"type" json:"type"`
+	Blockchain  string             `bson:"blockchain" json:"blockchain"`
+	Address     string             `bson:"address" json:"address"`
+	Token       string             `bson:"token,omitempty" json:"token,omitempty"`
+	Message     string             `bson:"message" json:"message"`
+	DedupKey    string             `bson:"dedupKey" json:"-"` // unique, so an occurrence is recorded once
+	TriggeredAt time.Time          `bson:"triggeredAt" json:"triggeredAt"`
+}
@@ -0,0 +1,293 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// usdScale is the number of decimal places kept when USD values are serialized
+const usdScale = 18
+
+// RawAmount is an on-chain integer amount in the token's smallest unit.
+// It is stored as a decimal string, like the original Amount field.
+type RawAmount struct {
+	v *big.Int
+}
+
+// NewRawAmount wraps a big integer; the value is copied
+func NewRawAmount(v *big.Int) RawAmount {
+	if v == nil {
+		return RawAmount{}
+	}
+	return RawAmount{v: new(big.Int).Set(v)}
+}
+
+// ParseRawAmount parses a base-10 integer string
+func ParseRawAmount(s string) (RawAmount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return RawAmount{}, nil
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return RawAmount{}, fmt.Errorf("invalid amount '%s'", s)
+	}
+	return RawAmount{v: v}, nil
+}
+
+// BigInt returns a copy of the amount
+func (a RawAmount) BigInt() *big.Int {
+	if a.v == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.v)
+}
+
+func (a RawAmount) IsZero() bool {
+	return a.v == nil || a.v.Sign() == 0
+}
+
+func (a RawAmount) Add(b RawAmount) RawAmount {
+	return RawAmount{v: new(big.Int).Add(a.BigInt(), b.BigInt())}
+}
+
+func (a RawAmount) Cmp(b RawAmount) int {
+	return a.BigInt().Cmp(b.BigInt())
+}
+
+func (a RawAmount) String() string {
+	if a.v == nil {
+		return "0"
+	}
+	return a.v.String()
+}
+
+// Rat returns the amount in whole token units
+func (a RawAmount) Rat(decimals int) *big.Rat {
+	r := new(big.Rat).SetInt(a.BigInt())
+	if decimals > 0 {
+		r.Quo(r, new(big.Rat).SetInt(pow10(decimals)))
+	}
+	return r
+}
+
+// Format renders the amount in whole token units without trailing zeros
+func (a RawAmount) Format(decimals int) string {
+	if decimals <= 0 {
+		return a.String()
+	}
+	return trimDecimal(a.Rat(decimals).FloatString(decimals))
+}
+
+func (a RawAmount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts both a string and a bare JSON number
+func (a *RawAmount) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		*a = RawAmount{}
+		return nil
+	}
+	parsed, err := ParseRawAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+func (a RawAmount) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bsontype.String, bsoncore.AppendString(nil, a.String()), nil
+}
+
+// UnmarshalBSONValue accepts strings as well as numeric types written by older code
+func (a *RawAmount) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	rv := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*a = RawAmount{}
+		return nil
+	case bsontype.String:
+		parsed, err := ParseRawAmount(rv.StringValue())
+		if err != nil {
+			return err
+		}
+		*a = parsed
+		return nil
+	case bsontype.Int32:
+		*a = RawAmount{v: big.NewInt(int64(rv.Int32()))}
+		return nil
+	case bsontype.Int64:
+		*a = RawAmount{v: big.NewInt(rv.Int64())}
+		return nil
+	case bsontype.Decimal128:
+		v, exp, err := rv.Decimal128().BigInt()
+		if err != nil {
+			return err
+		}
+		if exp < 0 {
+			return fmt.Errorf("amount %s is not an integer", rv.Decimal128())
+		}
+		*a = RawAmount{v: v.Mul(v, pow10(exp))}
+		return nil
+	default:
+		return fmt.Errorf("cannot decode %s into RawAmount", t)
+	}
+}
+
+// USD is an exact decimal dollar amount. The zero value is $0.
+// Values are immutable: arithmetic returns a new USD.
+type USD struct {
+	r *big.Rat
+}
+
+// NewUSDFromRat wraps a rational value; the value is copied
+func NewUSDFromRat(r *big.Rat) USD {
+	if r == nil {
+		return USD{}
+	}
+	return USD{r: new(big.Rat).Set(r)}
+}
+
+// NewUSDFromFloat converts a float using its shortest decimal representation
+func NewUSDFromFloat(f float64) USD {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return USD{r: r}
+}
+
+// ParseUSD parses a decimal string such as "1234.5678"
+func ParseUSD(s string) (USD, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return USD{}, fmt.Errorf("invalid USD value '%s'", s)
+	}
+	return USD{r: r}, nil
+}
+
+func (u USD) Rat() *big.Rat {
+	if u.r == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(u.r)
+}
+
+func (u USD) IsZero() bool {
+	return u.r == nil || u.r.Sign() == 0
+}
+
+func (u USD) Add(v USD) USD {
+	return USD{r: new(big.Rat).Add(u.Rat(), v.Rat())}
+}
+
+func (u USD) Sub(v USD) USD {
+	return USD{r: new(big.Rat).Sub(u.Rat(), v.Rat())}
+}
+
+// MulRat multiplies by an exact quantity, e.g. a token amount
+func (u USD) MulRat(q *big.Rat) USD {
+	return USD{r: new(big.Rat).Mul(u.Rat(), q)}
+}
+
+func (u USD) Cmp(v USD) int {
+	return u.Rat().Cmp(v.Rat())
+}
+
+func (u USD) Float64() float64 {
+	f, _ := u.Rat().Float64()
+	return f
+}
+
+// String renders the value with up to usdScale decimals, limited to 34 significant digits
+func (u USD) String() string {
+	r := u.Rat()
+	intDigits := len(new(big.Int).Quo(new(big.Int).Abs(r.Num()), r.Denom()).String())
+	scale := 34 - intDigits
+	if scale > usdScale {
+		scale = usdScale
+	}
+	if scale < 0 {
+		scale = 0
+	}
+	return trimDecimal(r.FloatString(scale))
+}
+
+// MarshalJSON emits a JSON number so clients that read usdValue as a number keep working
+func (u USD) MarshalJSON() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a decimal string
+func (u *USD) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*u = USD{}
+		return nil
+	}
+	parsed, err := ParseUSD(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// MarshalBSONValue stores the value as Decimal128 so Mongo aggregations stay exact
+func (u USD) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(u.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	return bsontype.Decimal128, bsoncore.AppendDecimal128(nil, d), nil
+}
+
+// UnmarshalBSONValue accepts Decimal128 as well as the doubles stored by older versions
+func (u *USD) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	rv := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*u = USD{}
+	case bsontype.Double:
+		*u = NewUSDFromFloat(rv.Double())
+	case bsontype.Int32:
+		*u = USD{r: new(big.Rat).SetInt64(int64(rv.Int32()))}
+	case bsontype.Int64:
+		*u = USD{r: new(big.Rat).SetInt64(rv.Int64())}
+	case bsontype.Decimal128:
+		parsed, err := ParseUSD(rv.Decimal128().String())
+		if err != nil {
+			return err
+		}
+		*u = parsed
+	case bsontype.String:
+		parsed, err := ParseUSD(rv.StringValue())
+		if err != nil {
+			return err
+		}
+		*u = parsed
+	default:
+		return fmt.Errorf("cannot decode %s into USD", t)
+	}
+	return nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func trimDecimal(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,266 @@
+package entities
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRawAmountFormat(t *testing.T) {
+	tests := []struct {
+		raw      string
+		decimals int
+		want     string
+	}{
+		{"0", 18, "0"},
+		{"1", 18, "0.000000000000000001"},
+		{"1000000000000000000", 18, "1"},
+		{"1234500", 6, "1.2345"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", 18,
+			"115792089237316195423570985008687907853269984665640564039457.584007913129639935"},
+		{"42", 0, "42"},
+	}
+	for _, tt := range tests {
+		a, err := ParseRawAmount(tt.raw)
+		if err != nil {
+			t.Fatalf("ParseRawAmount(%s): %v", tt.raw, err)
+		}
+		if got := a.Format(tt.decimals); got != tt.want {
+			t.Errorf("Format(%s, %d) = %s, want %s", tt.raw, tt.decimals, got, tt.want)
+		}
+	}
+
+	if _, err := ParseRawAmount("1.5"); err == nil {
+		t.Error("ParseRawAmount(1.5) should fail")
+	}
+}
+
+func TestRawAmountJSONRoundTrip(t *testing.T) {
+	max := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	for _, raw := range []string{"0", "1", max} {
+		a, _ := ParseRawAmount(raw)
+		data, err := json.Marshal(a)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if string(data) != `"`+raw+`"` {
+			t.Errorf("Marshal(%s) = %s", raw, data)
+		}
+		var back RawAmount
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if back.Cmp(a) != 0 {
+			t.Errorf("round trip of %s gave %s", raw, back)
+		}
+	}
+
+	var bare RawAmount
+	if err := json.Unmarshal([]byte("12345"), &bare); err != nil || bare.String() != "12345" {
+		t.Errorf("bare number decoded as %s, %v", bare, err)
+	}
+}
+
+type rawAmountDoc struct {
+	Amount RawAmount `bson:"amount"`
+}
+
+func TestRawAmountBSONRoundTrip(t *testing.T) {
+	a, _ := ParseRawAmount("340282366920938463463374607431768211457")
+	data, err := bson.Marshal(rawAmountDoc{Amount: a})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var back rawAmountDoc
+	if err := bson.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if back.Amount.Cmp(a) != 0 {
+		t.Errorf("round trip gave %s", back.Amount)
+	}
+}
+
+func TestRawAmountBSONLegacyTypes(t *testing.T) {
+	d, _ := primitive.ParseDecimal128("12E3")
+	fraction, _ := primitive.ParseDecimal128("1.5")
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{"int32", int32(7), "7", false},
+		{"int64", int64(9007199254740993), "9007199254740993", false},
+		{"decimal128", d, "12000", false},
+		{"null", nil, "0", false},
+		{"fractional decimal128", fraction, "", true},
+		{"double", 1.5, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := bson.Marshal(bson.M{"amount": tt.value})
+			var doc rawAmountDoc
+			err := bson.Unmarshal(data, &doc)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("decoding %v should fail", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if doc.Amount.String() != tt.want {
+				t.Errorf("decoded %s, want %s", doc.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestUSDArithmeticIsExact(t *testing.T) {
+	a, _ := ParseUSD("0.1")
+	b, _ := ParseUSD("0.2")
+	if got := a.Add(b).String(); got != "0.3" {
+		t.Errorf("0.1 + 0.2 = %s", got)
+	}
+
+	// 1.234567 tokens of 6 decimals at $3,456.78
+	price, _ := ParseUSD("3456.78")
+	amount, _ := ParseRawAmount("1234567")
+	if got := price.MulRat(amount.Rat(6)).String(); got != "4267.62651426" {
+		t.Errorf("value = %s, want 4267.62651426", got)
+	}
+
+	if got := NewUSDFromFloat(0.1).String(); got != "0.1" {
+		t.Errorf("NewUSDFromFloat(0.1) = %s", got)
+	}
+	if got := b.Sub(a).Sub(b).String(); got != "-0.1" {
+		t.Errorf("0.2 - 0.1 - 0.2 = %s", got)
+	}
+}
+
+func TestUSDStringPrecision(t *testing.T) {
+	third := NewUSDFromRat(big.NewRat(1, 3))
+	if got := third.String(); got != "0."+strings.Repeat("3", usdScale) {
+		t.Errorf("1/3 = %s", got)
+	}
+
+	// 20 integer digits leave 14 decimals for a 34-digit Decimal128
+	large, _ := ParseUSD("12345678901234567890.123456789012345678")
+	if got := large.String(); got != "12345678901234567890.12345678901235" {
+		t.Errorf("large = %s", got)
+	}
+}
+
+func TestUSDJSONRoundTrip(t *testing.T) {
+	for _, s := range []string{"0", "1234.5678", "0.000000000000000001", "-42.5"} {
+		u, _ := ParseUSD(s)
+		data, err := json.Marshal(u)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if string(data) != s {
+			t.Errorf("Marshal(%s) = %s, want a JSON number", s, data)
+		}
+		var back USD
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if back.Cmp(u) != 0 {
+			t.Errorf("round trip of %s gave %s", s, back)
+		}
+	}
+
+	var quoted USD
+	if err := json.Unmarshal([]byte(`"99.99"`), &quoted); err != nil || quoted.String() != "99.99" {
+		t.Errorf("quoted value decoded as %s, %v", quoted, err)
+	}
+}
+
+type usdDoc struct {
+	Value USD `bson:"value"`
+}
+
+func TestUSDBSONRoundTrip(t *testing.T) {
+	for _, s := range []string{"0", "1234.5678", "0.000000000000000001", "98765432109876543210.5"} {
+		u, _ := ParseUSD(s)
+		data, err := bson.Marshal(usdDoc{Value: u})
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+
+		var raw bson.Raw = data
+		if typ := raw.Lookup("value").Type; typ != bson.TypeDecimal128 {
+			t.Errorf("%s stored as %s, want decimal128", s, typ)
+		}
+
+		var back usdDoc
+		if err := bson.Unmarshal(data, &back); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if back.Value.Cmp(u) != 0 {
+			t.Errorf("round trip of %s gave %s", s, back.Value)
+		}
+	}
+}
+
+func TestUSDBSONLegacyDouble(t *testing.T) {
+	data, _ := bson.Marshal(bson.M{"value": 1234.56})
+	var doc usdDoc
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if doc.Value.String() != "1234.56" {
+		t.Errorf("legacy double decoded as %s", doc.Value)
+	}
+}
+
+func TestAssetJSONOmitsUnsetPrice(t *testing.T) {
+	data, err := json.Marshal(Asset{Symbol: "ETH", Decimals: 18})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if strings.Contains(string(data), "usdPrice") {
+		t.Errorf("unpriced asset = %s", data)
+	}
+
+	price, _ := ParseUSD("3000.5")
+	data, _ = json.Marshal(Asset{Symbol: "ETH", Decimals: 18, USDPrice: price})
+	if !strings.Contains(string(data), `"usdPrice":3000.5`) {
+		t.Errorf("priced asset = %s", data)
+	}
+
+	var back Asset
+	if err := json.Unmarshal(data, &back); err != nil || back.USDPrice.Cmp(price) != 0 || back.Symbol != "ETH" {
+		t.Errorf("round trip gave %+v, %v", back, err)
+	}
+}
+
+func TestBalanceOmitsUnsetPricedAt(t *testing.T) {
+	b := NewBalance(Asset{Symbol: "ETH", Decimals: 18}, NewRawAmount(big.NewInt(1)))
+	data, _ := json.Marshal(b)
+	if strings.Contains(string(data), "pricedAt") {
+		t.Errorf("unpriced balance JSON = %s", data)
+	}
+	raw, _ := bson.Marshal(b)
+	if _, err := bson.Raw(raw).LookupErr("pricedAt"); err == nil {
+		t.Error("unpriced balance BSON has a pricedAt")
+	}
+
+	pricedAt := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	b.PricedAt = &pricedAt
+	data, _ = json.Marshal(b)
+	if !strings.Contains(string(data), `"pricedAt":"2024-05-01T12:00:00Z"`) {
+		t.Errorf("priced balance JSON = %s", data)
+	}
+	var back Balance
+	raw, _ = bson.Marshal(b)
+	if err := bson.Unmarshal(raw, &back); err != nil || back.PricedAt == nil || !back.PricedAt.Equal(pricedAt) {
+		t.Errorf("BSON round trip gave %v, %v", back.PricedAt, err)
+	}
+}
@@ -0,0 +1,52 @@
+package entities
+
+import "strings"
+
+// Chain describes a blockchain supported by the service
+type Chain struct {
+	Key           string        `json:"key" yaml:"key"`
+	Name          string        `json:"name" yaml:"name"`
+	CAIP2         string        `json:"caip2" yaml:"caip2"`
+	EVMChainID    int64         `json:"evmChainId,omitempty" yaml:"evmChainId"`
+	AddressFormat string        `json:"addressFormat" yaml:"addressFormat"`
+	ExplorerURL   string        `json:"explorerUrl,omitempty" yaml:"explorerUrl"` // template, e.g. https://etherscan.io/address/{address}
+	NativeAsset   ChainAsset    `json:"nativeAsset" yaml:"nativeAsset"`
+	Provider      ChainProvider `json:"provider" yaml:"provider"`
+}
+
+// ChainAsset is the native asset of a chain
+type ChainAsset struct {
+	Symbol      string `json:"symbol" yaml:"symbol"`
+	Name        string `json:"name" yaml:"name"`
+	Decimals    int    `json:"decimals" yaml:"decimals"`
+	LogoURI     string `json:"logoURI,omitempty" yaml:"logoURI"`
+	CoingeckoID string `json:"coingeckoId,omitempty" yaml:"coingeckoId"`
+}
+
+// ChainProvider holds the balance provider settings of a chain.
+// Endpoints may embed API keys, so only the provider name is exposed over HTTP.
+type ChainProvider struct {
+	Name      string `json:"name,omitempty" yaml:"name"`
+	RPCURL    string `json:"-" yaml:"rpcUrl"`
+	RPCURLEnv string `json:"-" yaml:"rpcUrlEnv"` // env var that overrides RPCURL
+	APIURL    string `json:"-" yaml:"apiUrl"`
+}
+
+// Asset converts the native asset metadata into an Asset
+func (a ChainAsset) Asset() Asset {
+	return Asset{
+		Symbol:      a.Symbol,
+		Name:        a.Name,
+		Decimals:    a.Decimals,
+		LogoURI:     a.LogoURI,
+		CoingeckoID: a.CoingeckoID,
+	}
+}
+
+// ExplorerAddressURL renders the explorer link of an address, or "" when no template is set
+func (c Chain) ExplorerAddressURL(address string) string {
+	if c.ExplorerURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(c.ExplorerURL, "{address}", address)
+}
@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WalletChallenge is a pending proof-of-ownership request: the user must sign Message with the wallet's key
+type WalletChallenge struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID     string             `bson:"user_id" json:"-"`
+	Blockchain string             `bson:"blockchain" json:"blockchain"`
+	Address    string             `bson:"address" json:"address"`
+	Nonce      string             `bson:"nonce" json:"nonce"`
+	Message    string             `bson:"message" json:"message"`
+	IssuedAt   time.Time          `bson:"issuedAt" json:"issuedAt"`
+	ExpiresAt  time.Time          `bson:"expiresAt" json:"expiresAt"`
+}
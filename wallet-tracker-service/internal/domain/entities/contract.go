@@ -0,0 +1,20 @@
+package entities
+
+// Known contract categories
+const (
+	ContractCategoryDEX      = "dex"
+	ContractCategoryBridge   = "bridge"
+	ContractCategoryStaking  = "staking"
+	ContractCategoryLending  = "lending"
+	ContractCategoryApproval = "approval"
+)
+
+// KnownContract is a contract or program the classifier recognises by address
+type KnownContract struct {
+	Chain    string `json:"chain" yaml:"chain"`
+	Address  string `json:"address" yaml:"address"`
+	Name     string `json:"name" yaml:"name"`
+	Protocol string `json:"protocol" yaml:"protocol"`
+	Category string `json:"category" yaml:"category"`
+	Service  string `json:"service,omitempty" yaml:"service"` // our service that routes through it, if any
+}
@@ -0,0 +1,92 @@
+package entities
+
+import "time"
+
+// Domain event types
+const (
+	EventTokenAdded        = "token.added"
+	EventTokenRemoved      = "token.removed"
+	EventBalanceIncreased  = "balance.increased"
+	EventBalanceDecreased  = "balance.decreased"
+	EventValueChanged      = "value.changed"
+	EventBalancesRefreshed = "balances.refreshed"
+	EventAlertTriggered    = "alert.triggered"
+)
+
+// EventTypes lists every domain event type
+var EventTypes = []string{
+	EventTokenAdded, EventTokenRemoved, EventBalanceIncreased, EventBalanceDecreased, EventValueChanged,
+	EventBalancesRefreshed, EventAlertTriggered,
+}
+
+// DomainEvent is something that happened to a tracked wallet. Events concern the wallet, not the
+// user whose refresh noticed them; every user tracking the wallet is interested.
+type DomainEvent interface {
+	EventType() string
+	Wallet() WalletRef
+	OccurredAt() time.Time
+}
+
+// WalletEvent holds the fields every event carries
+type WalletEvent struct {
+	Type       string    `json:"type"`
+	Blockchain string    `json:"blockchain"`
+	Address    string    `json:"address"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+func (e WalletEvent) EventType() string {
+	return e.Type
+}
+
+func (e WalletEvent) Wallet() WalletRef {
+	return WalletRef{Blockchain: e.Blockchain, Address: e.Address}
+}
+
+func (e WalletEvent) OccurredAt() time.Time {
+	return e.Timestamp
+}
+
+// TokenAdded is an asset that was not in the wallet at the previous refresh
+type TokenAdded struct {
+	WalletEvent
+	Balance Balance `json:"balance"`
+}
+
+// TokenRemoved is an asset that is no longer in the wallet
+type TokenRemoved struct {
+	WalletEvent
+	Previous Balance `json:"previous"`
+}
+
+// BalanceChanged is a held asset whose amount went up (balance.increased) or down (balance.decreased)
+type BalanceChanged struct {
+	WalletEvent
+	Asset          Asset     `json:"asset"`
+	PreviousAmount RawAmount `json:"previousAmount"`
+	Amount         RawAmount `json:"amount"`
+	PreviousUSD    USD       `json:"previousUsdValue"`
+	USDValue       USD       `json:"usdValue"`
+}
+
+// ValueChanged is a move of the wallet's total USD value, in amount or price
+type ValueChanged struct {
+	WalletEvent
+	PreviousUSD   USD     `json:"previousUsdValue"`
+	USDValue      USD     `json:"usdValue"`
+	ChangePercent float64 `json:"changePercent"`
+}
+
+// BalancesRefreshed is a refresh that stored new balances, whether or not anything changed
+type BalancesRefreshed struct {
+	WalletEvent
+	TotalUSD USD       `json:"totalUsd"`
+	Balances []Balance `json:"balances"`
+}
+
+// AlertTriggered is an alert raised by a rule; unlike other events it only concerns the rule's owner
+type AlertTriggered struct {
+	WalletEvent
+	UserID string `json:"user_id"`
+	Alert  Alert  `json:"alert"`
+}
@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+// Lease gives one replica the exclusive right to run a scheduled job until it expires
+type Lease struct {
+	Name       string    `bson:"_id" json:"name"`
+	Holder     string    `bson:"holder" json:"holder"`
+	AcquiredAt time.Time `bson:"acquiredAt" json:"acquiredAt"`
+	ExpiresAt  time.Time `bson:"expiresAt" json:"expiresAt"`
+}
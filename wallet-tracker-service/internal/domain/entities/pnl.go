@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// TokenPnL is the cost basis and profit and loss of one asset in a wallet.
+// Assets that were fully sold are kept with a zero Amount so their realized PnL is not lost.
+// UntrackedAmount is the part of Amount the synced history does not account for; it has no cost
+// basis, so its value is reported apart and left out of UnrealizedPnL.
+type TokenPnL struct {
+	Asset           Asset     `json:"asset"`
+	Amount          RawAmount `json:"amount"`
+	CostBasis       USD       `json:"costBasis"`
+	AverageCost     USD       `json:"averageCost"`
+	Value           USD       `json:"value"`
+	RealizedPnL     USD       `json:"realizedPnl"`
+	UnrealizedPnL   USD       `json:"unrealizedPnl"`
+	UntrackedAmount RawAmount `json:"untrackedAmount"`
+	UntrackedValue  USD       `json:"untrackedValue"`
+}
+
+// WalletPnL is the PnL of every asset in a wallet's synced transaction history.
+// Notes explain where the figures are incomplete, such as holdings without a known cost.
+type WalletPnL struct {
+	Blockchain     string     `json:"blockchain"`
+	Address        string     `json:"address"`
+	Method         string     `json:"method"`
+	Since          *time.Time `json:"since,omitempty"`
+	TotalCostBasis USD        `json:"totalCostBasis"`
+	TotalValue     USD        `json:"totalValue"`
+	RealizedPnL    USD        `json:"realizedPnl"`
+	UnrealizedPnL  USD        `json:"unrealizedPnl"`
+	UntrackedValue USD        `json:"untrackedValue"`
+	Tokens         []TokenPnL `json:"tokens"`
+	Notes          []string   `json:"notes,omitempty"`
+}
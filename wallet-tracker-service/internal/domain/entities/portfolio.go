@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Portfolio is a named group of a user's tracked wallets
+type Portfolio struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Wallets     []WalletRef        `bson:"wallets" json:"wallets"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// WalletRef points to a tracked wallet by chain and canonical address
+type WalletRef struct {
+	Blockchain string `bson:"blockchain" json:"blockchain"`
+	Address    string `bson:"address" json:"address"`
+	CAIP10     string `bson:"caip10,omitempty" json:"caip10,omitempty"`
+}
+
+// PortfolioSummary is the combined valuation of a portfolio's wallets
+type PortfolioSummary struct {
+	PortfolioID string           `json:"portfolioId"`
+	Name        string           `json:"name"`
+	TotalUSD    USD              `json:"totalUsd"`
+	Chains      []ChainTotal     `json:"chains"`
+	Tokens      []PortfolioToken `json:"tokens"`
+	UpdatedAt   time.Time        `json:"updatedAt,omitempty"`
+}
+
+// ChainTotal is the USD value held on one chain
+type ChainTotal struct {
+	Blockchain string `bson:"_id" json:"blockchain"`
+	USDValue   USD    `bson:"usdValue" json:"usdValue"`
+	Wallets    int    `bson:"wallets" json:"wallets"`
+}
+
+// PortfolioToken is an asset merged across every wallet of a portfolio on the same chain
+type PortfolioToken struct {
+	Blockchain string  `json:"blockchain"`
+	Balance    Balance `json:"balance"`
+	Wallets    int     `json:"wallets"`
+}
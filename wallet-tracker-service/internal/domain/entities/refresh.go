@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Refresh run states
+const (
+	RefreshRunning          = "running"
+	RefreshCompleted        = "completed"
+	RefreshDeadlineExceeded = "deadline_exceeded" // stopped starting wallets at the run deadline
+	RefreshOverlapped       = "overlapped"        // not started, the previous run was still going
+	RefreshLeased           = "leased"            // not started, another replica holds the refresh lease
+	RefreshFailed           = "failed"            // the wallet list could not be read
+)
+
+// RefreshRun records one scheduled balance refresh of every tracked wallet
+type RefreshRun struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Host       string             `bson:"host" json:"host"` // replica that ran it
+	Status     string             `bson:"status" json:"status"`
+	StartedAt  time.Time          `bson:"startedAt" json:"startedAt"`
+	FinishedAt *time.Time         `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	DurationMs int64              `bson:"durationMs" json:"durationMs"`
+	Wallets    int64              `bson:"wallets" json:"wallets"`
+	Refreshed  int64              `bson:"refreshed" json:"refreshed"`
+	Failed     int64              `bson:"failed" json:"failed"`
+	// Skipped counts wallets deleted during the run and wallets not reached before the deadline
+	Skipped int64 `bson:"skipped" json:"skipped"`
+	// LeaseHolder is the replica that held the refresh lease when this one was leased out
+	LeaseHolder string `bson:"leaseHolder,omitempty" json:"leaseHolder,omitempty"`
+	Error       string `bson:"error,omitempty" json:"error,omitempty"`
+}
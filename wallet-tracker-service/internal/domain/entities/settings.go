@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserSettings holds per-user preferences
+type UserSettings struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID          string             `bson:"user_id" json:"user_id"`
+	CostBasisMethod string             `bson:"costBasisMethod" json:"costBasisMethod"`
+	UpdatedAt       time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Snapshot resolutions. Raw snapshots are written by refreshes; older ones are rolled up by compaction.
+const (
+	ResolutionRaw  = ""
+	ResolutionHour = "hour"
+	ResolutionDay  = "day"
+)
+
+// BalanceSnapshot is the state of a wallet's balances at one refresh. Snapshots are never updated.
+// A rollup snapshot stands for a whole hour or day: Timestamp, TotalUSD and Balances are those of
+// the last snapshot in the bucket, and MinUSD/MaxUSD the range of TotalUSD over the bucket.
+type BalanceSnapshot struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Blockchain string             `bson:"blockchain" json:"blockchain"`
+	Address    string             `bson:"address" json:"address"`
+	Timestamp  time.Time          `bson:"timestamp" json:"timestamp"`
+	TotalUSD   USD                `bson:"totalUsd" json:"totalUsd"`
+	Balances   []Balance          `bson:"balances" json:"balances"`
+	Resolution string             `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	Bucket     *time.Time         `bson:"bucket,omitempty" json:"bucket,omitempty"`
+	MinUSD     *USD               `bson:"minUsd,omitempty" json:"minUsd,omitempty"`
+	MaxUSD     *USD               `bson:"maxUsd,omitempty" json:"maxUsd,omitempty"`
+}
+
+// ValuePoint is the USD value of one or more wallets at the start of a time bucket
+type ValuePoint struct {
+	Timestamp time.Time `bson:"_id" json:"timestamp"`
+	USDValue  USD       `bson:"usdValue" json:"usdValue"`
+}
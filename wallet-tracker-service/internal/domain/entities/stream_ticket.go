@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// StreamTicket lets a browser's EventSource, which cannot send an Authorization header, open one
+// update stream. It is spent on use and expires within seconds; only its SHA-256 is stored.
+type StreamTicket struct {
+	ID     string `bson:"_id"`
+	UserID string `bson:"user_id"`
+	// TokenExpiresAt is the expiry of the token the ticket was issued under, which also ends the stream
+	TokenExpiresAt *time.Time `bson:"tokenExpiresAt,omitempty"`
+	ExpiresAt      time.Time  `bson:"expiresAt"`
+}
@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+// Holding periods of a disposal, for jurisdictions that tax them differently
+const (
+	TermShort = "short"
+	TermLong  = "long"
+)
+
+// TaxDisposal is the part of a disposal matched to one acquisition lot.
+// Acquired is nil when the disposed quantity could not be matched to a known acquisition.
+type TaxDisposal struct {
+	Asset      Asset      `json:"asset"`
+	Blockchain string     `json:"blockchain"`
+	Quantity   string     `json:"quantity"` // in token units
+	Acquired   *time.Time `json:"acquired,omitempty"`
+	Sold       time.Time  `json:"sold"`
+	Proceeds   USD        `json:"proceeds"`
+	Cost       USD        `json:"cost"`
+	Gain       USD        `json:"gain"`
+	Term       string     `json:"term,omitempty"`
+	Hash       string     `json:"hash"`
+	Label      string     `json:"label"`
+	Note       string     `json:"note,omitempty"`
+}
+
+// TaxReport lists a user's disposals in one tax year
+type TaxReport struct {
+	Jurisdiction string        `json:"jurisdiction"`
+	Year         int           `json:"year"`
+	Method       string        `json:"method"`
+	From         time.Time     `json:"from"`
+	To           time.Time     `json:"to"` // exclusive
+	Proceeds     USD           `json:"proceeds"`
+	Cost         USD           `json:"cost"`
+	Gain         USD           `json:"gain"`
+	Disposals    []TaxDisposal `json:"disposals"`
+}
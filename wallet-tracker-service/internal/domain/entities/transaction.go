@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Transfer directions, relative to the tracked wallet
+const (
+	DirectionIn   = "in"
+	DirectionOut  = "out"
+	DirectionSelf = "self"
+)
+
+// Transaction labels set by the classifier or by the user
+const (
+	LabelSwap     = "swap"
+	LabelSend     = "send"
+	LabelReceive  = "receive"
+	LabelBridge   = "bridge"
+	LabelStake    = "stake"
+	LabelUnstake  = "unstake"
+	LabelLend     = "lend"
+	LabelWithdraw = "withdraw"
+	LabelBorrow   = "borrow"
+	LabelRepay    = "repay"
+	LabelApprove  = "approve"
+	LabelMint     = "mint"
+	LabelBurn     = "burn"
+	LabelOther    = "other"
+)
+
+// Labels lists every transaction label
+var Labels = []string{
+	LabelSwap, LabelSend, LabelReceive, LabelBridge, LabelStake, LabelUnstake, LabelLend,
+	LabelWithdraw, LabelBorrow, LabelRepay, LabelApprove, LabelMint, LabelBurn, LabelOther,
+}
+
+// Transaction is one transfer leg of an on-chain transaction, as seen from a tracked wallet.
+// A transaction that moves several assets is stored as one document per leg, all sharing Hash;
+// LegID tells them apart ("tx" for the transaction itself, "log:N" for token transfer events, ...).
+type Transaction struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Blockchain  string             `bson:"blockchain" json:"blockchain"`
+	Address     string             `bson:"address" json:"address"`
+	Hash        string             `bson:"hash" json:"hash"`
+	LegID       string             `bson:"legId" json:"legId"`
+	BlockNumber int64              `bson:"blockNumber" json:"blockNumber"`
+	Timestamp   time.Time          `bson:"timestamp" json:"timestamp"`
+	Direction   string             `bson:"direction" json:"direction"`
+	From        string             `bson:"from,omitempty" json:"from,omitempty"`
+	To          string             `bson:"to,omitempty" json:"to,omitempty"`
+	Contract    string             `bson:"contract,omitempty" json:"contract,omitempty"` // contract or program called
+	Method      string             `bson:"method,omitempty" json:"method,omitempty"`     // EVM 4-byte selector
+	Asset       Asset              `bson:"asset" json:"asset"`
+	Amount      RawAmount          `bson:"amount" json:"amount"`
+	Fee         RawAmount          `bson:"fee" json:"fee"` // native fee paid by the wallet, on one leg only
+	Failed      bool               `bson:"failed,omitempty" json:"failed,omitempty"`
+	Label       string             `bson:"label,omitempty" json:"label,omitempty"`       // set by the classifier
+	Protocol    string             `bson:"protocol,omitempty" json:"protocol,omitempty"` // known contract involved
+	ManualLabel string             `bson:"-" json:"manualLabel,omitempty"`               // the user's override
+}
+
+// Classification is the user's label if they set one, else the classifier's
+func (t Transaction) Classification() string {
+	if t.ManualLabel != "" {
+		return t.ManualLabel
+	}
+	return t.Label
+}
+
+// TransactionLabel is a user's manual label for every leg of a transaction of one of their wallets
+type TransactionLabel struct {
+	UserID     string    `bson:"user_id" json:"user_id"`
+	Blockchain string    `bson:"blockchain" json:"blockchain"`
+	Address    string    `bson:"address" json:"address"`
+	Hash       string    `bson:"hash" json:"hash"`
+	Label      string    `bson:"label" json:"label"`
+	UpdatedAt  time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// TransactionCursor records how far ingestion has got for a wallet.
+// Cursor is provider specific: a block number for EVM and BTC, a signature for Solana.
+type TransactionCursor struct {
+	Blockchain string    `bson:"blockchain" json:"blockchain"`
+	Address    string    `bson:"address" json:"address"`
+	Cursor     string    `bson:"cursor" json:"cursor"`
+	SyncedAt   time.Time `bson:"syncedAt" json:"syncedAt"`
+}
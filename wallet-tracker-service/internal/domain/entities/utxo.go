@@ -0,0 +1,10 @@
+package entities
+
+// UTXO represents an unspent Bitcoin transaction output
+type UTXO struct {
+	TxID        string `bson:"txid" json:"txid"`
+	Vout        uint32 `bson:"vout" json:"vout"`
+	Value       uint64 `bson:"value" json:"value"` // satoshis
+	Confirmed   bool   `bson:"confirmed" json:"confirmed"`
+	BlockHeight int64  `bson:"blockHeight,omitempty" json:"blockHeight,omitempty"`
+}
@@ -1,8 +1,10 @@
 package entities
 
 import (
+	"encoding/json"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -12,7 +14,12 @@ type Wallet struct {
 	UserID      string             `bson:"user_id" json:"user_id"`
 	Blockchain  string             `bson:"blockchain" json:"blockchain"`
 	Address     string             `bson:"address" json:"address"`
-	Balance     float64            `bson:"balance" json:"balance"`
+	CAIP10      string             `bson:"caip10,omitempty" json:"caip10,omitempty"`
+	Label       string             `bson:"label,omitempty" json:"label,omitempty"`
+	Tags        []string           `bson:"tags,omitempty" json:"tags,omitempty"`
+	Verified    bool               `bson:"verified" json:"verified"`
+	VerifiedAt  *time.Time         `bson:"verifiedAt,omitempty" json:"verifiedAt,omitempty"`
+	Balance     USD                `bson:"balance" json:"balance"`
 	Balances    []Balance          `bson:"balances" json:"balances,omitempty"`
 	LastUpdated time.Time          `bson:"lastUpdated" json:"lastUpdated"`
 	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
@@ -20,20 +27,77 @@ type Wallet struct {
 
 // Asset represents a token/coin in a wallet
 type Asset struct {
-	Symbol      string  `bson:"symbol" json:"symbol"`
-	Name        string  `bson:"name" json:"name"`
-	Decimals    int     `bson:"decimals" json:"decimals"`
-	LogoURI     string  `bson:"logoURI,omitempty" json:"logoURI,omitempty"`
-	CoingeckoID string  `bson:"coingeckoId,omitempty" json:"coingeckoId,omitempty"`
-	USDPrice    float64 `bson:"usdPrice,omitempty" json:"usdPrice,omitempty"`
+	Symbol      string `bson:"symbol" json:"symbol"`
+	Name        string `bson:"name" json:"name"`
+	Address     string `bson:"address,omitempty" json:"address,omitempty"`
+	Decimals    int    `bson:"decimals" json:"decimals"`
+	LogoURI     string `bson:"logoURI,omitempty" json:"logoURI,omitempty"`
+	CoingeckoID string `bson:"coingeckoId,omitempty" json:"coingeckoId,omitempty"`
+	USDPrice    USD    `bson:"usdPrice,omitempty" json:"usdPrice,omitempty"`
 }
 
-// Balance represents a token balance in a wallet
+// MarshalJSON leaves out an unset price; encoding/json ignores omitempty on struct fields
+func (a Asset) MarshalJSON() ([]byte, error) {
+	type asset Asset
+	doc := struct {
+		asset
+		USDPrice *USD `json:"usdPrice,omitempty"`
+	}{asset: asset(a)}
+	if !a.USDPrice.IsZero() {
+		doc.USDPrice = &a.USDPrice
+	}
+	return json.Marshal(doc)
+}
+
+// Balance represents a token balance in a wallet.
+// FormattedAmount is not stored on the struct; it is always derived from Amount and Asset.Decimals.
 type Balance struct {
-	Asset           Asset   `bson:"asset" json:"asset"`
-	Amount          string  `bson:"amount" json:"amount"`
-	FormattedAmount string  `bson:"formattedAmount" json:"formattedAmount"`
-	USDValue        float64 `bson:"usdValue" json:"usdValue"`
+	Asset    Asset      `bson:"asset" json:"asset"`
+	Amount   RawAmount  `bson:"amount" json:"amount"`
+	USDValue USD        `bson:"usdValue" json:"usdValue"`
+	PricedAt *time.Time `bson:"pricedAt,omitempty" json:"pricedAt,omitempty"`
+	// Unpriced is set when no price source answered and the balance had no earlier price,
+	// so its USDValue is missing rather than zero
+	Unpriced bool `bson:"unpriced,omitempty" json:"unpriced,omitempty"`
+}
+
+// balanceDocument is the serialized form of Balance, including the derived formattedAmount
+type balanceDocument struct {
+	Asset           Asset      `bson:"asset" json:"asset"`
+	Amount          RawAmount  `bson:"amount" json:"amount"`
+	FormattedAmount string     `bson:"formattedAmount" json:"formattedAmount"`
+	USDValue        USD        `bson:"usdValue" json:"usdValue"`
+	PricedAt        *time.Time `bson:"pricedAt,omitempty" json:"pricedAt,omitempty"`
+	Unpriced        bool       `bson:"unpriced,omitempty" json:"unpriced,omitempty"`
+}
+
+// NewBalance builds a balance from a raw amount
+func NewBalance(asset Asset, amount RawAmount) Balance {
+	return Balance{Asset: asset, Amount: amount}
+}
+
+// FormattedAmount returns the amount in whole token units
+func (b Balance) FormattedAmount() string {
+	return b.Amount.Format(b.Asset.Decimals)
+}
+
+func (b Balance) document() balanceDocument {
+	return balanceDocument{
+		Asset:           b.Asset,
+		Amount:          b.Amount,
+		FormattedAmount: b.FormattedAmount(),
+		USDValue:        b.USDValue,
+		PricedAt:        b.PricedAt,
+		Unpriced:        b.Unpriced,
+	}
+}
+
+func (b Balance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.document())
+}
+
+func (b Balance) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(b.document())
 }
 
 // WalletBalances represents all balances for a wallet
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription is an endpoint of the user's that receives the events of their wallets
+type WebhookSubscription struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID string             `bson:"user_id" json:"user_id"`
+	URL    string             `bson:"url" json:"url"`
+	// Secret keys the HMAC signature of deliveries; it is only returned when the subscription is created
+	Secret     string    `bson:"secret" json:"secret,omitempty"`
+	EventTypes []string  `bson:"eventTypes" json:"eventTypes"` // empty for every type
+	Enabled    bool      `bson:"enabled" json:"enabled"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Wants reports whether the subscription receives events of a type
+func (s WebhookSubscription) Wants(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event on its way to a subscription. Pending deliveries form the retry
+// queue; after the last failed attempt a delivery moves to the dead-letter store.
+type WebhookDelivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	SubscriptionID primitive.ObjectID `bson:"subscription_id" json:"subscriptionId"`
+	UserID         string             `bson:"user_id" json:"user_id"`
+	URL            string             `bson:"url" json:"url"`
+	EventType      string             `bson:"eventType" json:"eventType"`
+	Payload        string             `bson:"payload" json:"payload"` // the JSON body, identical on every attempt
+	Status         string             `bson:"status" json:"status"`
+	Attempts       int                `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time          `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LastError      string             `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	LastStatusCode int                `bson:"lastStatusCode,omitempty" json:"lastStatusCode,omitempty"`
+	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
+	DeliveredAt    *time.Time         `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+	DeadAt         *time.Time         `bson:"deadAt,omitempty" json:"deadAt,omitempty"`
+}
@@ -0,0 +1,102 @@
+package addresses
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	ErrInvalidBase58 = errors.New("invalid base58 string")
+	ErrBadChecksum   = errors.New("invalid checksum")
+
+	base58Index = func() [256]int {
+		var idx [256]int
+		for i := range idx {
+			idx[i] = -1
+		}
+		for i, c := range base58Alphabet {
+			idx[c] = i
+		}
+		return idx
+	}()
+)
+
+// Base58Encode encodes bytes with the Bitcoin base58 alphabet
+func Base58Encode(input []byte) string {
+	num := new(big.Int).SetBytes(input)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for num.Sign() > 0 {
+		num.DivMod(num, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// Base58Decode decodes a base58 string
+func Base58Decode(input string) ([]byte, error) {
+	if input == "" {
+		return nil, ErrInvalidBase58
+	}
+
+	num := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(input); i++ {
+		v := base58Index[input[i]]
+		if v < 0 {
+			return nil, ErrInvalidBase58
+		}
+		num.Mul(num, radix)
+		num.Add(num, big.NewInt(int64(v)))
+	}
+
+	decoded := num.Bytes()
+	leading := 0
+	for leading < len(input) && input[leading] == base58Alphabet[0] {
+		leading++
+	}
+	return append(make([]byte, leading), decoded...), nil
+}
+
+// Base58CheckEncode appends a double-SHA256 checksum and base58-encodes the payload
+func Base58CheckEncode(payload []byte) string {
+	sum := doubleSHA256(payload)
+	return Base58Encode(append(append([]byte{}, payload...), sum[:4]...))
+}
+
+// Base58CheckDecode decodes a base58check string and verifies its checksum
+func Base58CheckDecode(input string) ([]byte, error) {
+	decoded, err := Base58Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 5 {
+		return nil, ErrInvalidBase58
+	}
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	sum := doubleSHA256(payload)
+	if !bytes.Equal(sum[:4], checksum) {
+		return nil, ErrBadChecksum
+	}
+	return payload, nil
+}
+
+func doubleSHA256(data []byte) [32]byte {
+	first := sha256.Sum256(data)
+	return sha256.Sum256(first[:])
+}
@@ -0,0 +1,78 @@
+package addresses
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// Vectors from Bitcoin Core's base58_encode_decode.json
+func TestBase58EncodeDecode(t *testing.T) {
+	tests := []struct {
+		hex     string
+		encoded string
+	}{
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+		{"572e4794", "3EFU7m"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"10c8511e", "Rt5zm"},
+		{"00000000000000000000", "1111111111"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoded, func(t *testing.T) {
+			raw, _ := hex.DecodeString(tt.hex)
+			if got := Base58Encode(raw); got != tt.encoded {
+				t.Errorf("Base58Encode(%s) = %s, want %s", tt.hex, got, tt.encoded)
+			}
+			decoded, err := Base58Decode(tt.encoded)
+			if err != nil {
+				t.Fatalf("Base58Decode(%s): %v", tt.encoded, err)
+			}
+			if got := hex.EncodeToString(decoded); got != tt.hex {
+				t.Errorf("Base58Decode(%s) = %s, want %s", tt.encoded, got, tt.hex)
+			}
+		})
+	}
+}
+
+func TestBase58DecodeInvalid(t *testing.T) {
+	for _, input := range []string{"", "0OIl", "3SEo3LWLoPntC0", "abc def"} {
+		if _, err := Base58Decode(input); !errors.Is(err, ErrInvalidBase58) {
+			t.Errorf("Base58Decode(%q) error = %v, want ErrInvalidBase58", input, err)
+		}
+	}
+}
+
+func TestBase58Check(t *testing.T) {
+	payload, _ := hex.DecodeString("00eb15231dfceb60925886b67d065299925915aeb1")
+	encoded := Base58CheckEncode(payload)
+	decoded, err := Base58CheckDecode(encoded)
+	if err != nil {
+		t.Fatalf("Base58CheckDecode(%s): %v", encoded, err)
+	}
+	if got := hex.EncodeToString(decoded); got != "00eb15231dfceb60925886b67d065299925915aeb1" {
+		t.Errorf("payload = %s", got)
+	}
+
+	// A P2PKH address derived in the BIP-44 test vectors
+	decoded, err = Base58CheckDecode("1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA")
+	if err != nil {
+		t.Fatalf("Base58CheckDecode: %v", err)
+	}
+	if len(decoded) != 21 || decoded[0] != 0x00 {
+		t.Errorf("payload = %x, want a version 0 hash160", decoded)
+	}
+
+	if _, err := Base58CheckDecode("1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabB"); !errors.Is(err, ErrBadChecksum) {
+		t.Errorf("altered address error = %v, want ErrBadChecksum", err)
+	}
+	if _, err := Base58CheckDecode("2g"); !errors.Is(err, ErrInvalidBase58) {
+		t.Errorf("short input error = %v, want ErrInvalidBase58", err)
+	}
+}
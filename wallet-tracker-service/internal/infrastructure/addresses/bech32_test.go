@@ -0,0 +1,140 @@
+package addresses
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Valid strings from BIP-173 (bech32) and BIP-350 (bech32m)
+func TestBech32DecodeValid(t *testing.T) {
+	tests := []struct {
+		input    string
+		constant uint32
+	}{
+		{"A12UEL5L", bech32Const},
+		{"a12uel5l", bech32Const},
+		{"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs", bech32Const},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", bech32Const},
+		{"11" + strings.Repeat("q", 82) + "c8247j", bech32Const},
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", bech32Const},
+		{"?1ezyfcl", bech32Const},
+		{"A1LQFN3A", bech32mConst},
+		{"a1lqfn3a", bech32mConst},
+		{"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6", bech32mConst},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", bech32mConst},
+		{"11" + strings.Repeat("l", 83) + "udsr8", bech32mConst},
+		{"split1checkupstagehandshakeupstreamerranterredcaperredlc445v", bech32mConst},
+		{"?1v759aa", bech32mConst},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			hrp, data, constant, err := bech32Decode(tt.input)
+			if err != nil {
+				t.Fatalf("bech32Decode: %v", err)
+			}
+			if constant != tt.constant {
+				t.Errorf("constant = %#x, want %#x", constant, tt.constant)
+			}
+			if got := bech32Encode(hrp, data, constant); got != strings.ToLower(tt.input) {
+				t.Errorf("re-encoded as %s", got)
+			}
+		})
+	}
+}
+
+func TestBech32DecodeInvalid(t *testing.T) {
+	tests := []string{
+		"\x201nwldj5",   // hrp character out of range
+		"pzry9x0s0muk",  // no separator
+		"1pzry9x0s0muk", // empty hrp
+		"x1b4n0q5v",     // invalid data character
+		"li1dgmt3",      // checksum too short
+		"A1G7SGD8",      // checksum computed with an uppercase hrp
+		"10a06t8",       // empty hrp
+		"1qzzfhee",      // empty hrp
+		"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx", // too long
+		"M1VUXWEZ",   // bech32m checksum computed with an uppercase hrp
+		"Aa1qqqqqqq", // mixed case
+	}
+	for _, input := range tests {
+		if _, _, _, err := bech32Decode(input); err == nil {
+			t.Errorf("bech32Decode(%q) should fail", input)
+		}
+	}
+}
+
+// witnessScript builds the scriptPubKey of a witness program as listed in BIP-173 and BIP-350
+func witnessScript(version byte, program []byte) string {
+	op := version
+	if version > 0 {
+		op = 0x50 + version
+	}
+	return hex.EncodeToString(append([]byte{op, byte(len(program))}, program...))
+}
+
+func TestSegwitAddressValid(t *testing.T) {
+	tests := []struct {
+		hrp     string
+		address string
+		script  string
+	}{
+		{"bc", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"tb", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{"bc", "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"bc", "BC1SW50QGDZ25J", "6002751e"},
+		{"bc", "bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", "5210751e76e8199196d454941c45d1b3a323"},
+		{"tb", "tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", "0020000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"bc", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			version, program, err := DecodeSegwitAddress(tt.hrp, tt.address)
+			if err != nil {
+				t.Fatalf("DecodeSegwitAddress: %v", err)
+			}
+			if got := witnessScript(version, program); got != tt.script {
+				t.Errorf("script = %s, want %s", got, tt.script)
+			}
+			encoded, err := EncodeSegwitAddress(tt.hrp, version, program)
+			if err != nil {
+				t.Fatalf("EncodeSegwitAddress: %v", err)
+			}
+			if encoded != strings.ToLower(tt.address) {
+				t.Errorf("re-encoded as %s", encoded)
+			}
+		})
+	}
+}
+
+func TestSegwitAddressInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+	}{
+		{"invalid hrp", "tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut"},
+		{"bech32 checksum on v1", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd"},
+		{"bech32 checksum on v16", "BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL"},
+		{"bech32m checksum on v0", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh"},
+		{"invalid character", "bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4"},
+		{"invalid version", "BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R"},
+		{"program too short", "bc1pw5dgrnzv"},
+		{"program too long", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0muaewav253zgeav"},
+		{"invalid v0 program length", "BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P"},
+		{"mixed case", "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47Zagq"},
+		{"zero padding of more than 4 bits", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v07qwwzcrf"},
+		{"non-zero padding", "tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vpggkg4j"},
+		{"empty data", "bc1gmk9yu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hrp := "bc"
+			if strings.HasPrefix(strings.ToLower(tt.address), "tb") {
+				hrp = "tb"
+			}
+			if _, _, err := DecodeSegwitAddress(hrp, tt.address); err == nil {
+				t.Errorf("DecodeSegwitAddress(%s) should fail", tt.address)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package addresses
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Address formats understood by Normalize
+const (
+	FormatEVM    = "evm"
+	FormatSolana = "solana"
+	FormatTron   = "tron"
+	FormatBTC    = "btc"
+)
+
+// btcExtendedKeyVersions are the mainnet xpub/ypub/zpub version bytes
+var btcExtendedKeyVersions = map[string]bool{
+	"0488b21e": true,
+	"049d7cb2": true,
+	"04b24746": true,
+}
+
+// Normalize validates an address in the given format and returns its canonical form
+func Normalize(format, address string) (string, error) {
+	address = strings.TrimSpace(address)
+	switch format {
+	case FormatEVM:
+		return normalizeEVM(address)
+	case FormatSolana:
+		return address, validateSolana(address)
+	case FormatTron:
+		return address, validateTron(address)
+	case FormatBTC:
+		return normalizeBTC(address)
+	default:
+		return "", fmt.Errorf("unknown address format '%s'", format)
+	}
+}
+
+// ToChecksumAddress returns the EIP-55 mixed-case form of a 20-byte hex address
+func ToChecksumAddress(address string) string {
+	lower := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X"))
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	out := make([]byte, len(lower))
+	for i := 0; i < len(lower); i++ {
+		c := lower[i]
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			c -= 'a' - 'A'
+		}
+		out[i] = c
+	}
+	return "0x" + string(out)
+}
+
+// normalizeEVM accepts all-lower or all-upper hex, and mixed case only with a valid EIP-55 checksum
+func normalizeEVM(address string) (string, error) {
+	if len(address) != 42 || !strings.HasPrefix(address, "0x") {
+		return "", fmt.Errorf("invalid EVM address: %s", address)
+	}
+	body := address[2:]
+	if _, err := hex.DecodeString(body); err != nil {
+		return "", fmt.Errorf("invalid EVM address: %s", address)
+	}
+
+	checksummed := ToChecksumAddress(address)
+	if body != strings.ToLower(body) && body != strings.ToUpper(body) && address != checksummed {
+		return "", fmt.Errorf("invalid EIP-55 checksum for address: %s", address)
+	}
+	return checksummed, nil
+}
+
+// validateSolana checks for a base58-encoded 32-byte public key
+func validateSolana(address string) error {
+	decoded, err := Base58Decode(address)
+	if err != nil || len(decoded) != 32 {
+		return fmt.Errorf("invalid Solana address: %s", address)
+	}
+	return nil
+}
+
+// validateTron checks for a base58check T-address: 0x41 followed by a 20-byte account ID
+func validateTron(address string) error {
+	if !strings.HasPrefix(address, "T") {
+		return fmt.Errorf("invalid TRON address: %s", address)
+	}
+	payload, err := Base58CheckDecode(address)
+	if err != nil || len(payload) != 21 || payload[0] != 0x41 {
+		return fmt.Errorf("invalid TRON address: %s", address)
+	}
+	return nil
+}
+
+// normalizeBTC accepts segwit (bech32/bech32m), legacy P2PKH/P2SH and extended public keys.
+// Segwit addresses are lower-cased since bech32 is case-insensitive.
+func normalizeBTC(address string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(address), "bc1") {
+		if _, _, err := DecodeSegwitAddress("bc", address); err != nil {
+			return "", fmt.Errorf("invalid BTC address %s: %w", address, err)
+		}
+		return strings.ToLower(address), nil
+	}
+
+	payload, err := Base58CheckDecode(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid BTC address %s: %w", address, err)
+	}
+	switch {
+	case len(payload) == 21 && (payload[0] == 0x00 || payload[0] == 0x05):
+		return address, nil
+	case len(payload) == 78 && btcExtendedKeyVersions[hex.EncodeToString(payload[:4])]:
+		return address, nil
+	default:
+		return "", fmt.Errorf("invalid BTC address: %s", address)
+	}
+}
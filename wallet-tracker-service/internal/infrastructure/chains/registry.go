@@ -0,0 +1,176 @@
+package chains
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed chains.yaml
+var defaultChainsYAML []byte
+
+// caip2Pattern is the CAIP-2 chain ID syntax, namespace:reference
+var caip2Pattern = regexp.MustCompile(`^[-a-z0-9]{3,8}:[-_a-zA-Z0-9]{1,32}$`)
+
+// addressFormats are the formats addresses.Normalize understands
+var addressFormats = map[string]bool{
+	addresses.FormatEVM:    true,
+	addresses.FormatSolana: true,
+	addresses.FormatTron:   true,
+	addresses.FormatBTC:    true,
+}
+
+// Registry holds the chains supported by the service, in file order
+type Registry struct {
+	chains  []entities.Chain
+	byKey   map[string]entities.Chain
+	byCAIP2 map[string]entities.Chain
+}
+
+type registryFile struct {
+	Chains []entities.Chain `yaml:"chains"`
+}
+
+var (
+	instance *Registry
+	mu       sync.RWMutex
+)
+
+// Init loads the registry from path (YAML or JSON) and makes it the process-wide registry.
+// An empty path loads the built-in chains.
+func Init(path string) (*Registry, error) {
+	registry, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	instance = registry
+	mu.Unlock()
+	return registry, nil
+}
+
+// Get returns the process-wide registry, falling back to the built-in chains
+func Get() *Registry {
+	mu.RLock()
+	registry := instance
+	mu.RUnlock()
+	if registry != nil {
+		return registry
+	}
+
+	registry, err := Parse(defaultChainsYAML)
+	if err != nil {
+		panic(fmt.Sprintf("built-in chain registry is invalid: %v", err))
+	}
+	mu.Lock()
+	if instance == nil {
+		instance = registry
+	}
+	registry = instance
+	mu.Unlock()
+	return registry
+}
+
+// Load reads a registry file, or the built-in chains when path is empty
+func Load(path string) (*Registry, error) {
+	if path == "" {
+		return Parse(defaultChainsYAML)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chain registry: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a registry document.
+// JSON is valid YAML, so both formats go through the YAML decoder and its field names.
+func Parse(data []byte) (*Registry, error) {
+	var file registryFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse chain registry: %w", err)
+	}
+
+	registry := &Registry{
+		byKey:   make(map[string]entities.Chain, len(file.Chains)),
+		byCAIP2: make(map[string]entities.Chain, len(file.Chains)),
+	}
+	for _, chain := range file.Chains {
+		chain.Key = strings.ToUpper(strings.TrimSpace(chain.Key))
+		if chain.Key == "" {
+			return nil, fmt.Errorf("chain registry entry without key")
+		}
+		if chain.AddressFormat == "" {
+			return nil, fmt.Errorf("chain %s has no addressFormat", chain.Key)
+		}
+		if !addressFormats[chain.AddressFormat] {
+			return nil, fmt.Errorf("chain %s has unknown addressFormat '%s'", chain.Key, chain.AddressFormat)
+		}
+		if _, dup := registry.byKey[chain.Key]; dup {
+			return nil, fmt.Errorf("chain %s is defined twice", chain.Key)
+		}
+		if chain.CAIP2 != "" {
+			if !caip2Pattern.MatchString(chain.CAIP2) {
+				return nil, fmt.Errorf("chain %s has invalid caip2 '%s'", chain.Key, chain.CAIP2)
+			}
+			if other, dup := registry.byCAIP2[chain.CAIP2]; dup {
+				return nil, fmt.Errorf("chains %s and %s share caip2 '%s'", other.Key, chain.Key, chain.CAIP2)
+			}
+		}
+		if chain.EVMChainID != 0 && chain.CAIP2 != "" && chain.CAIP2 != fmt.Sprintf("eip155:%d", chain.EVMChainID) {
+			return nil, fmt.Errorf("chain %s has evmChainId %d but caip2 '%s'", chain.Key, chain.EVMChainID, chain.CAIP2)
+		}
+
+		registry.chains = append(registry.chains, chain)
+		registry.byKey[chain.Key] = chain
+		if chain.CAIP2 != "" {
+			registry.byCAIP2[chain.CAIP2] = chain
+		}
+	}
+	return registry, nil
+}
+
+// All returns every chain in registry order
+func (r *Registry) All() []entities.Chain {
+	return append([]entities.Chain(nil), r.chains...)
+}
+
+// Chain returns the chain with the given key
+func (r *Registry) Chain(key string) (entities.Chain, bool) {
+	chain, ok := r.byKey[key]
+	return chain, ok
+}
+
+// IsSupported reports whether the chain key is in the registry
+func (r *Registry) IsSupported(key string) bool {
+	_, ok := r.byKey[key]
+	return ok
+}
+
+// ChainByCAIP2 returns the chain with the given CAIP-2 ID
+func (r *Registry) ChainByCAIP2(caip2 string) (entities.Chain, bool) {
+	chain, ok := r.byCAIP2[caip2]
+	return chain, ok
+}
+
+// RPCURL resolves the RPC endpoint of a chain, preferring the env var named in the registry
+func (r *Registry) RPCURL(key string) string {
+	chain, ok := r.byKey[key]
+	if !ok {
+		return ""
+	}
+	if chain.Provider.RPCURLEnv != "" {
+		if url := os.Getenv(chain.Provider.RPCURLEnv); url != "" {
+			return url
+		}
+	}
+	return chain.Provider.RPCURL
+}
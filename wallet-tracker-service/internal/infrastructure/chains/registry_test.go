@@ -0,0 +1,126 @@
+package chains
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBuiltInRegistry(t *testing.T) {
+	registry, err := Parse(defaultChainsYAML)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	all := registry.All()
+	if len(all) == 0 || all[0].Key != "ETH" {
+		t.Fatalf("chains out of file order: %v", all)
+	}
+	for _, chain := range all {
+		if chain.CAIP2 == "" {
+			continue
+		}
+		if byCAIP2, ok := registry.ChainByCAIP2(chain.CAIP2); !ok || byCAIP2.Key != chain.Key {
+			t.Errorf("ChainByCAIP2(%s) = %s, want %s", chain.CAIP2, byCAIP2.Key, chain.Key)
+		}
+	}
+}
+
+func TestParseRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{
+			name: "JSON with a lower-case key",
+			doc:  `{"chains": [{"key": " eth ", "caip2": "eip155:1", "evmChainId": 1, "addressFormat": "evm"}]}`,
+		},
+		{
+			name: "no CAIP-2 ID",
+			doc:  "chains:\n  - {key: LOCAL, addressFormat: evm}",
+		},
+		{
+			name:    "no key",
+			doc:     "chains:\n  - {caip2: 'eip155:1', addressFormat: evm}",
+			wantErr: "without key",
+		},
+		{
+			name:    "no address format",
+			doc:     "chains:\n  - {key: ETH, caip2: 'eip155:1'}",
+			wantErr: "no addressFormat",
+		},
+		{
+			name:    "unknown address format",
+			doc:     "chains:\n  - {key: ADA, addressFormat: cardano}",
+			wantErr: "unknown addressFormat",
+		},
+		{
+			name:    "duplicate key",
+			doc:     "chains:\n  - {key: ETH, addressFormat: evm}\n  - {key: eth, addressFormat: evm}",
+			wantErr: "defined twice",
+		},
+		{
+			name:    "duplicate CAIP-2 ID",
+			doc:     "chains:\n  - {key: ETH, caip2: 'eip155:1', addressFormat: evm}\n  - {key: MAINNET, caip2: 'eip155:1', addressFormat: evm}",
+			wantErr: "share caip2",
+		},
+		{
+			name:    "CAIP-2 ID without a reference",
+			doc:     "chains:\n  - {key: ETH, caip2: eip155, addressFormat: evm}",
+			wantErr: "invalid caip2",
+		},
+		{
+			name:    "CAIP-2 namespace too short",
+			doc:     "chains:\n  - {key: ETH, caip2: 'ev:1', addressFormat: evm}",
+			wantErr: "invalid caip2",
+		},
+		{
+			name:    "CAIP-2 ID and EVM chain ID disagree",
+			doc:     "chains:\n  - {key: BSC, caip2: 'eip155:1', evmChainId: 56, addressFormat: evm}",
+			wantErr: "evmChainId 56",
+		},
+		{
+			name:    "not a registry document",
+			doc:     "chains: [",
+			wantErr: "failed to parse",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := Parse([]byte(tt.doc))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Parse error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(registry.All()) != 1 {
+				t.Errorf("%d chains, want 1", len(registry.All()))
+			}
+		})
+	}
+
+	registry, _ := Parse([]byte(`{"chains": [{"key": " eth ", "caip2": "eip155:1", "evmChainId": 1, "addressFormat": "evm"}]}`))
+	if !registry.IsSupported("ETH") || registry.IsSupported("eth") {
+		t.Error("keys should be trimmed and upper-cased")
+	}
+}
+
+func TestRegistryRPCURL(t *testing.T) {
+	registry, err := Parse([]byte("chains:\n  - {key: ETH, addressFormat: evm, provider: {rpcUrl: 'https://default', rpcUrlEnv: TEST_ETH_RPC_URL}}"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := registry.RPCURL("ETH"); got != "https://default" {
+		t.Errorf("RPCURL = %s, want the registry URL", got)
+	}
+	t.Setenv("TEST_ETH_RPC_URL", "https://override")
+	if got := registry.RPCURL("ETH"); got != "https://override" {
+		t.Errorf("RPCURL = %s, want the env override", got)
+	}
+	if got := registry.RPCURL("SOLANA"); got != "" {
+		t.Errorf("RPCURL of an unknown chain = %s", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -23,6 +25,57 @@ type Config struct {
 	// Auth Service
 	AuthServiceURL string
 
+	// Chain registry file (YAML or JSON); empty uses the built-in chains
+	ChainsConfigPath string
+
+	// Known contracts file (YAML or JSON) used to classify transactions; empty uses the built-in list
+	KnownContractsPath string
+
+	// Balance providers
+	BalanceProvider string            // default provider for every chain
+	ChainProviders  map[string]string // per-chain overrides, e.g. ETH=evm
+	RangoBaseURL    string
+	TokenListPath   string
+	BTCEsploraURL   string
+	BTCGapLimit     int
+
+	// Prices
+	PriceSources        []string
+	CoingeckoAPIKey     string // pro plan key, served by pro-api.coingecko.com
+	CoingeckoDemoAPIKey string // demo plan key, served by api.coingecko.com
+	CoingeckoBaseURL    string
+	PriceCacheTTL       time.Duration
+
+	// Transaction ingestion
+	EtherscanAPIKey     string // enables EVM transaction history
+	EtherscanAPIURL     string
+	TransactionSyncCron string
+
+	// Scheduled balance refresh
+	BalanceRefreshCron string
+	RefreshWorkers     int
+	RefreshDeadline    time.Duration      // a run stops starting wallets after this long
+	ProviderRateLimits map[string]float64 // wallets per second by balance provider, e.g. RANGO=2; unlisted providers are not limited
+
+	// Balance snapshot compaction
+	SnapshotRawRetention    time.Duration // full-resolution window
+	SnapshotHourlyRetention time.Duration // hourly window, daily beyond
+	SnapshotCompactionCron  string
+
+	// Alerts
+	PriceAlertCron string // evaluates price_change rules between balance refreshes
+
+	// Webhook delivery
+	WebhookDeliveryCron string
+	WebhookMaxAttempts  int // attempts before a delivery goes to the dead-letter store
+
+	// Wallet ownership verification (SIWE message fields)
+	SIWEDomain string
+	SIWEURI    string
+
+	// Addresses allowed on the admin endpoints
+	AdminAddresses []string
+
 	// Debug
 	Debug bool
 }
@@ -47,6 +100,79 @@ func LoadConfig() *Config {
 
 	debug := os.Getenv("DEBUG") == "true"
 
+	btcGapLimit, _ := strconv.Atoi(os.Getenv("BTC_GAP_LIMIT"))
+
+	priceSources := os.Getenv("PRICE_SOURCES")
+	if priceSources == "" {
+		priceSources = "coingecko"
+	}
+	priceCacheTTL, _ := strconv.Atoi(os.Getenv("PRICE_CACHE_TTL_SECONDS"))
+
+	rawRetentionHours, err := strconv.Atoi(os.Getenv("SNAPSHOT_RAW_RETENTION_HOURS"))
+	if err != nil || rawRetentionHours <= 0 {
+		rawRetentionHours = 48
+	}
+	hourlyRetentionDays, err := strconv.Atoi(os.Getenv("SNAPSHOT_HOURLY_RETENTION_DAYS"))
+	if err != nil || hourlyRetentionDays <= 0 {
+		hourlyRetentionDays = 30
+	}
+	compactionCron := os.Getenv("SNAPSHOT_COMPACTION_CRON")
+	if compactionCron == "" {
+		compactionCron = "@every 1h"
+	}
+
+	transactionSyncCron := os.Getenv("TRANSACTION_SYNC_CRON")
+	if transactionSyncCron == "" {
+		transactionSyncCron = "@every 15m"
+	}
+
+	balanceRefreshCron := os.Getenv("BALANCE_REFRESH_CRON")
+	if balanceRefreshCron == "" {
+		balanceRefreshCron = "@every 30m"
+	}
+	refreshWorkers, err := strconv.Atoi(os.Getenv("REFRESH_WORKERS"))
+	if err != nil || refreshWorkers <= 0 {
+		refreshWorkers = 8
+	}
+	refreshDeadlineMinutes, err := strconv.Atoi(os.Getenv("REFRESH_DEADLINE_MINUTES"))
+	if err != nil || refreshDeadlineMinutes <= 0 {
+		refreshDeadlineMinutes = 25
+	}
+	providerRateLimits := make(map[string]float64)
+	for name, raw := range parseKeyValueList(os.Getenv("REFRESH_RATE_LIMITS")) {
+		if rate, err := strconv.ParseFloat(raw, 64); err == nil && rate > 0 {
+			providerRateLimits[name] = rate
+		}
+	}
+
+	priceAlertCron := os.Getenv("PRICE_ALERT_CRON")
+	if priceAlertCron == "" {
+		priceAlertCron = "@every 5m"
+	}
+
+	webhookDeliveryCron := os.Getenv("WEBHOOK_DELIVERY_CRON")
+	if webhookDeliveryCron == "" {
+		webhookDeliveryCron = "@every 15s"
+	}
+	webhookMaxAttempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if err != nil || webhookMaxAttempts <= 0 {
+		webhookMaxAttempts = 8
+	}
+
+	siweDomain := os.Getenv("SIWE_DOMAIN")
+	if siweDomain == "" {
+		siweDomain = "panoramablock.com"
+	}
+	siweURI := os.Getenv("SIWE_URI")
+	if siweURI == "" {
+		siweURI = "https://" + siweDomain
+	}
+
+	balanceProvider := os.Getenv("BALANCE_PROVIDER")
+	if balanceProvider == "" {
+		balanceProvider = "rango"
+	}
+
 	config := &Config{
 		ServerPort:     port,
 		RangoAPIKey:    os.Getenv("X_RANGO_ID"),
@@ -57,7 +183,46 @@ func LoadConfig() *Config {
 		RedisPassword:  os.Getenv("REDIS_PASS"),
 		AuthServiceURL: authServiceURL,
 		Debug:          debug,
-}
+
+		ChainsConfigPath:   os.Getenv("CHAINS_CONFIG_PATH"),
+		KnownContractsPath: os.Getenv("KNOWN_CONTRACTS_PATH"),
+
+		BalanceProvider: balanceProvider,
+		ChainProviders:  parseKeyValueList(os.Getenv("CHAIN_PROVIDERS")),
+		RangoBaseURL:    os.Getenv("RANGO_BASE_URL"),
+		TokenListPath:   os.Getenv("TOKEN_LIST_PATH"),
+		BTCEsploraURL:   os.Getenv("BTC_ESPLORA_URL"),
+		BTCGapLimit:     btcGapLimit,
+
+		PriceSources:        splitList(priceSources),
+		CoingeckoAPIKey:     os.Getenv("COINGECKO_API_KEY"),
+		CoingeckoDemoAPIKey: os.Getenv("COINGECKO_DEMO_API_KEY"),
+		CoingeckoBaseURL:    os.Getenv("COINGECKO_BASE_URL"),
+		PriceCacheTTL:       time.Duration(priceCacheTTL) * time.Second,
+
+		EtherscanAPIKey:     os.Getenv("ETHERSCAN_API_KEY"),
+		EtherscanAPIURL:     os.Getenv("ETHERSCAN_API_URL"),
+		TransactionSyncCron: transactionSyncCron,
+
+		BalanceRefreshCron: balanceRefreshCron,
+		RefreshWorkers:     refreshWorkers,
+		RefreshDeadline:    time.Duration(refreshDeadlineMinutes) * time.Minute,
+		ProviderRateLimits: providerRateLimits,
+
+		SnapshotRawRetention:    time.Duration(rawRetentionHours) * time.Hour,
+		SnapshotHourlyRetention: time.Duration(hourlyRetentionDays) * 24 * time.Hour,
+		SnapshotCompactionCron:  compactionCron,
+
+		PriceAlertCron: priceAlertCron,
+
+		WebhookDeliveryCron: webhookDeliveryCron,
+		WebhookMaxAttempts:  webhookMaxAttempts,
+
+		SIWEDomain: siweDomain,
+		SIWEURI:    siweURI,
+
+		AdminAddresses: splitList(os.Getenv("ADMIN_ADDRESSES")),
+	}
 
 	if config.Debug {
 		fmt.Printf("[Config] Loaded configuration:\n")
@@ -68,11 +233,39 @@ func LoadConfig() *Config {
 		fmt.Printf("- RedisPort: %s\n", config.RedisPort)
 		fmt.Printf("- AuthServiceURL: %s\n", config.AuthServiceURL)
 		fmt.Printf("- RangoAPIKey: %s\n", config.RangoAPIKey)
+		fmt.Printf("- ChainsConfigPath: %s\n", config.ChainsConfigPath)
+		fmt.Printf("- BalanceProvider: %s\n", config.BalanceProvider)
+		fmt.Printf("- ChainProviders: %v\n", config.ChainProviders)
+		fmt.Printf("- PriceSources: %v\n", config.PriceSources)
 	}
 
 	return config
 }
 
+// splitList parses a comma separated list, dropping empty entries
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseKeyValueList parses "KEY=value,KEY2=value2" into a map with upper-cased keys
+func parseKeyValueList(raw string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || key == "" {
+			continue
+		}
+		result[strings.ToUpper(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return result
+}
+
 // ConnectRedis connects to Redis with proper configuration
 func ConnectRedis(conf *Config) (*redis.Client, error) {
 	if conf.RedisHost == "" {
@@ -100,4 +293,4 @@ func ConnectRedis(conf *Config) (*redis.Client, error) {
 	}
 
 	return client, nil
-} 
\ No newline at end of file
+}
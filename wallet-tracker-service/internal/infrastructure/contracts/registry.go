@@ -0,0 +1,120 @@
+package contracts
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed known_contracts.yaml
+var defaultContractsYAML []byte
+
+// Registry indexes known contracts by chain and address
+type Registry struct {
+	contracts []entities.KnownContract
+	byKey     map[string]entities.KnownContract
+}
+
+type registryFile struct {
+	Contracts []entities.KnownContract `yaml:"contracts"`
+}
+
+var (
+	instance *Registry
+	mu       sync.RWMutex
+)
+
+// Init loads the registry from path (YAML or JSON) and makes it the process-wide registry.
+// An empty path loads the built-in contracts.
+func Init(path string) (*Registry, error) {
+	registry, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	instance = registry
+	mu.Unlock()
+	return registry, nil
+}
+
+// Get returns the process-wide registry, falling back to the built-in contracts
+func Get() *Registry {
+	mu.RLock()
+	registry := instance
+	mu.RUnlock()
+	if registry != nil {
+		return registry
+	}
+
+	registry, err := Parse(defaultContractsYAML)
+	if err != nil {
+		panic(fmt.Sprintf("built-in contract registry is invalid: %v", err))
+	}
+	mu.Lock()
+	if instance == nil {
+		instance = registry
+	}
+	registry = instance
+	mu.Unlock()
+	return registry
+}
+
+// Load reads a registry file, or the built-in contracts when path is empty
+func Load(path string) (*Registry, error) {
+	if path == "" {
+		return Parse(defaultContractsYAML)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contract registry: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a registry document
+func Parse(data []byte) (*Registry, error) {
+	var file registryFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse contract registry: %w", err)
+	}
+
+	registry := &Registry{byKey: make(map[string]entities.KnownContract, len(file.Contracts))}
+	for _, contract := range file.Contracts {
+		contract.Chain = strings.ToUpper(strings.TrimSpace(contract.Chain))
+		contract.Address = strings.TrimSpace(contract.Address)
+		if contract.Chain == "" || contract.Address == "" {
+			return nil, fmt.Errorf("contract registry entry '%s' needs a chain and an address", contract.Name)
+		}
+		if contract.Category == "" {
+			return nil, fmt.Errorf("contract %s on %s has no category", contract.Address, contract.Chain)
+		}
+		registry.contracts = append(registry.contracts, contract)
+		registry.byKey[contractKey(contract.Chain, contract.Address)] = contract
+	}
+	return registry, nil
+}
+
+// All returns every known contract in file order
+func (r *Registry) All() []entities.KnownContract {
+	return append([]entities.KnownContract(nil), r.contracts...)
+}
+
+// Lookup finds a contract by chain and address; hex addresses match case-insensitively
+func (r *Registry) Lookup(chain, address string) (entities.KnownContract, bool) {
+	contract, ok := r.byKey[contractKey(chain, address)]
+	return contract, ok
+}
+
+// contractKey lower-cases hex addresses; base58 addresses are case-sensitive and kept as they are
+func contractKey(chain, address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		address = strings.ToLower(address)
+	}
+	return strings.ToUpper(chain) + ":" + address
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind before events are dropped for it
+const subscriberBuffer = 1024
+
+// Handler receives the events of a subscription, one at a time and in publish order
+type Handler func(event entities.DomainEvent)
+
+// IEventBus delivers domain events to the subscribers of their type within the process
+type IEventBus interface {
+	Publish(events ...entities.DomainEvent)
+	// Subscribe registers a handler for the given event types, or for every type when none is given.
+	// The returned function cancels the subscription.
+	Subscribe(handler Handler, eventTypes ...string) func()
+}
+
+type subscription struct {
+	types  map[string]bool
+	queue  chan entities.DomainEvent
+	done   chan struct{}
+	closed sync.Once
+}
+
+// Bus is an in-memory IEventBus. Publish never blocks: each subscriber drains its own queue
+// on its own goroutine, so a slow handler only delays itself.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[int]*subscription
+	nextID int
+	logger *logs.Logger
+}
+
+func NewBus(logger *logs.Logger) *Bus {
+	return &Bus{
+		subs:   make(map[int]*subscription),
+		logger: logger,
+	}
+}
+
+func (b *Bus) Publish(events ...entities.DomainEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, event := range events {
+		for _, sub := range b.subs {
+			if len(sub.types) > 0 && !sub.types[event.EventType()] {
+				continue
+			}
+			select {
+			case sub.queue <- event:
+			default:
+				b.logger.Warnf("Event subscriber is %d events behind, dropping %s for %s.%s",
+					subscriberBuffer, event.EventType(), event.Wallet().Blockchain, event.Wallet().Address)
+			}
+		}
+	}
+}
+
+func (b *Bus) Subscribe(handler Handler, eventTypes ...string) func() {
+	sub := &subscription{
+		types: make(map[string]bool, len(eventTypes)),
+		queue: make(chan entities.DomainEvent, subscriberBuffer),
+		done:  make(chan struct{}),
+	}
+	for _, t := range eventTypes {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = sub
+	b.mu.Unlock()
+
+	go b.run(sub, handler)
+
+	return func() {
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+		sub.closed.Do(func() { close(sub.done) })
+	}
+}
+
+func (b *Bus) run(sub *subscription, handler Handler) {
+	for {
+		select {
+		case <-sub.done:
+			return
+		case event := <-sub.queue:
+			b.dispatch(handler, event)
+		}
+	}
+}
+
+// dispatch runs a handler, keeping the subscription alive if it panics
+func (b *Bus) dispatch(handler Handler, event entities.DomainEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Errorf("Event handler panicked on %s: %v", event.EventType(), r)
+		}
+	}()
+	handler(event)
+}
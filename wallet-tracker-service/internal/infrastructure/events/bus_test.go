@@ -0,0 +1,103 @@
+package events
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+func testEvent(eventType string) entities.DomainEvent {
+	return entities.BalancesRefreshed{WalletEvent: entities.WalletEvent{Type: eventType, Blockchain: "ETH", Address: "0xabc"}}
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBusDeliversSubscribedTypes(t *testing.T) {
+	bus := NewBus(logs.NewLogger())
+	got := make(chan string, 10)
+	cancel := bus.Subscribe(func(e entities.DomainEvent) { got <- e.EventType() }, entities.EventTokenAdded, entities.EventValueChanged)
+	var all int32
+	defer bus.Subscribe(func(entities.DomainEvent) { atomic.AddInt32(&all, 1) })()
+
+	bus.Publish(testEvent(entities.EventTokenAdded), testEvent(entities.EventTokenRemoved), testEvent(entities.EventValueChanged))
+	for _, want := range []string{entities.EventTokenAdded, entities.EventValueChanged} {
+		select {
+		case eventType := <-got:
+			if eventType != want {
+				t.Errorf("received %s, want %s", eventType, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not delivered", want)
+		}
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&all) == 3 })
+
+	// Nothing reaches a cancelled subscription; cancelling twice is harmless
+	cancel()
+	cancel()
+	bus.Publish(testEvent(entities.EventTokenAdded))
+	waitFor(t, func() bool { return atomic.LoadInt32(&all) == 4 })
+	select {
+	case eventType := <-got:
+		t.Errorf("cancelled subscription received %s", eventType)
+	default:
+	}
+}
+
+func TestBusDropsForFullSubscriber(t *testing.T) {
+	bus := NewBus(logs.NewLogger())
+	release := make(chan struct{})
+	var delivered int32
+	defer bus.Subscribe(func(entities.DomainEvent) {
+		<-release
+		atomic.AddInt32(&delivered, 1)
+	})()
+
+	// The handler holds at most one event, so everything past the buffer behind it is dropped
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < subscriberBuffer+50; i++ {
+			bus.Publish(testEvent(entities.EventTokenAdded))
+		}
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+
+	close(release)
+	waitFor(t, func() bool { return atomic.LoadInt32(&delivered) >= subscriberBuffer })
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&delivered); n > subscriberBuffer+1 {
+		t.Errorf("%d events delivered, want at most %d", n, subscriberBuffer+1)
+	}
+}
+
+func TestBusSurvivesPanickingHandler(t *testing.T) {
+	bus := NewBus(logs.NewLogger())
+	var handled int32
+	defer bus.Subscribe(func(e entities.DomainEvent) {
+		atomic.AddInt32(&handled, 1)
+		if e.EventType() == entities.EventTokenRemoved {
+			panic("handler bug")
+		}
+	})()
+
+	bus.Publish(testEvent(entities.EventTokenRemoved), testEvent(entities.EventTokenAdded))
+	waitFor(t, func() bool { return atomic.LoadInt32(&handled) == 2 })
+}
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/redis/go-redis/v9"
+)
+
+// fanoutChannel is the Redis pub/sub channel replicas exchange events on
+const fanoutChannel = "wallet-tracker:events"
+
+// Envelope is a domain event in its JSON form, as it travels between replicas
+type Envelope struct {
+	Type       string          `json:"type"`
+	Blockchain string          `json:"blockchain"`
+	Address    string          `json:"address"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func NewEnvelope(event entities.DomainEvent) (Envelope, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return Envelope{}, err
+	}
+	wallet := event.Wallet()
+	return Envelope{
+		Type:       event.EventType(),
+		Blockchain: wallet.Blockchain,
+		Address:    wallet.Address,
+		Data:       data,
+	}, nil
+}
+
+// EnvelopeHandler receives fanned out events; it is called on the fanout's goroutine and must not block
+type EnvelopeHandler func(envelope Envelope)
+
+// Fanout delivers the events published on the bus of any replica to a handler on every replica.
+// Events go through Redis pub/sub, including those of the local replica, so each arrives once;
+// without Redis only local events are delivered.
+type Fanout struct {
+	bus         IEventBus
+	redisClient *redis.Client
+	logger      *logs.Logger
+}
+
+func NewFanout(bus IEventBus, redisClient *redis.Client, logger *logs.Logger) *Fanout {
+	return &Fanout{
+		bus:         bus,
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+// Start forwards local events of the given types and delivers every replica's to handler until
+// the returned function is called
+func (f *Fanout) Start(handler EnvelopeHandler, eventTypes ...string) func() {
+	if f.redisClient == nil {
+		f.logger.Warnf("Redis not connected, streaming only the events of this replica")
+		return f.bus.Subscribe(func(event entities.DomainEvent) {
+			if envelope, err := NewEnvelope(event); err == nil {
+				handler(envelope)
+			}
+		}, eventTypes...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pubsub := f.redisClient.Subscribe(ctx, fanoutChannel)
+	go f.listen(pubsub, handler)
+
+	unsubscribe := f.bus.Subscribe(f.forward, eventTypes...)
+	return func() {
+		unsubscribe()
+		cancel()
+		pubsub.Close()
+	}
+}
+
+// forward publishes a local event to every replica
+func (f *Fanout) forward(event entities.DomainEvent) {
+	envelope, err := NewEnvelope(event)
+	if err != nil {
+		f.logger.Errorf("Error encoding %s event: %v", event.EventType(), err)
+		return
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		f.logger.Errorf("Error encoding %s event: %v", event.EventType(), err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := f.redisClient.Publish(ctx, fanoutChannel, payload).Err(); err != nil {
+		f.logger.Errorf("Error publishing %s event to Redis: %v", event.EventType(), err)
+	}
+}
+
+// listen delivers the events of the channel until the subscription is closed; the Redis client
+// reconnects and resubscribes on its own after connection errors
+func (f *Fanout) listen(pubsub *redis.PubSub, handler EnvelopeHandler) {
+	for msg := range pubsub.Channel() {
+		var envelope Envelope
+		if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+			f.logger.Warnf("Ignoring malformed event on %s: %v", fanoutChannel, err)
+			continue
+		}
+		handler(envelope)
+	}
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type AdminController struct {
+	balanceRefresher services.IBalanceRefresher
+	logger           *logs.Logger
+}
+
+func NewAdminController(br services.IBalanceRefresher, logger *logs.Logger) *AdminController {
+	return &AdminController{
+		balanceRefresher: br,
+		logger:           logger,
+	}
+}
+
+// GetRefreshRuns handles GET /api/admin/refresh/runs?limit=20, the metrics of the latest scheduled
+// balance refreshes of every replica. running tells whether this replica is in a run.
+func (ac *AdminController) GetRefreshRuns(c *fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+
+	runs, err := ac.balanceRefresher.GetRuns(limit)
+	if err != nil {
+		ac.logger.Errorf("Error getting refresh runs: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"running": ac.balanceRefresher.Running(),
+		"runs":    runs,
+	})
+}
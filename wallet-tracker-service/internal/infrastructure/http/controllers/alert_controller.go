@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type AlertController struct {
+	alertService services.IAlertService
+	logger       *logs.Logger
+}
+
+func NewAlertController(as services.IAlertService, logger *logs.Logger) *AlertController {
+	return &AlertController{
+		alertService: as,
+		logger:       logger,
+	}
+}
+
+type createAlertRuleRequest struct {
+	Name            string       `json:"name"`
+	Type            string       `json:"type"`
+	Wallet          string       `json:"wallet"`
+	Token           string       `json:"token"`
+	Threshold       entities.USD `json:"threshold"`
+	Percent         float64      `json:"percent"`
+	CooldownSeconds int64        `json:"cooldownSeconds"`
+	Enabled         *bool        `json:"enabled"`
+}
+
+type updateAlertRuleRequest struct {
+	Name            *string       `json:"name"`
+	Token           *string       `json:"token"`
+	Threshold       *entities.USD `json:"threshold"`
+	Percent         *float64      `json:"percent"`
+	CooldownSeconds *int64        `json:"cooldownSeconds"`
+	Enabled         *bool         `json:"enabled"`
+}
+
+// CreateAlertRule handles POST /api/alerts/rules with
+// {"type": "value_below", "wallet": "ETH.0x123", "threshold": "1000"},
+// {"type": "balance_change", "wallet": "ETH.0x123", "token": "USDC"} or
+// {"type": "price_change", "wallet": "ETH.0x123", "percent": 10, "cooldownSeconds": 3600}
+func (ac *AlertController) CreateAlertRule(c *fiber.Ctx) error {
+	var req createAlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	rule, err := ac.alertService.CreateAlertRule(userAddr, services.AlertRuleInput{
+		Name:            req.Name,
+		Type:            req.Type,
+		Wallet:          req.Wallet,
+		Token:           req.Token,
+		Threshold:       req.Threshold,
+		Percent:         req.Percent,
+		CooldownSeconds: req.CooldownSeconds,
+		Enabled:         req.Enabled,
+	})
+	if err != nil {
+		ac.logger.Errorf("Error creating alert rule: %v", err)
+		return c.Status(alertErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// GetAlertRules handles GET /api/alerts/rules
+func (ac *AlertController) GetAlertRules(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	rules, err := ac.alertService.GetAlertRules(userAddr)
+	if err != nil {
+		ac.logger.Errorf("Error getting alert rules: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rules)
+}
+
+// GetAlertRule handles GET /api/alerts/rules/:id
+func (ac *AlertController) GetAlertRule(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	rule, err := ac.alertService.GetAlertRule(userAddr, c.Params("id"))
+	if err != nil {
+		ac.logger.Errorf("Error getting alert rule: %v", err)
+		return c.Status(alertErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rule)
+}
+
+// UpdateAlertRule handles PATCH /api/alerts/rules/:id
+func (ac *AlertController) UpdateAlertRule(c *fiber.Ctx) error {
+	var req updateAlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	rule, err := ac.alertService.UpdateAlertRule(userAddr, c.Params("id"), services.AlertRuleUpdate{
+		Name:            req.Name,
+		Token:           req.Token,
+		Threshold:       req.Threshold,
+		Percent:         req.Percent,
+		CooldownSeconds: req.CooldownSeconds,
+		Enabled:         req.Enabled,
+	})
+	if err != nil {
+		ac.logger.Errorf("Error updating alert rule: %v", err)
+		return c.Status(alertErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rule)
+}
+
+// DeleteAlertRule handles DELETE /api/alerts/rules/:id
+func (ac *AlertController) DeleteAlertRule(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	if err := ac.alertService.DeleteAlertRule(userAddr, c.Params("id")); err != nil {
+		ac.logger.Errorf("Error deleting alert rule: %v", err)
+		return c.Status(alertErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetAlerts handles GET /api/alerts?limit=50, the user's alert history
+func (ac *AlertController) GetAlerts(c *fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	alerts, err := ac.alertService.GetAlerts(userAddr, limit)
+	if err != nil {
+		ac.logger.Errorf("Error getting alerts: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(alerts)
+}
+
+// alertErrorStatus maps alert service errors to HTTP status codes
+func alertErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidAlertRule):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrAlertRuleNotFound):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type ChainController struct {
+	chainRegistry *chains.Registry
+	logger        *logs.Logger
+}
+
+func NewChainController(chainRegistry *chains.Registry, logger *logs.Logger) *ChainController {
+	return &ChainController{
+		chainRegistry: chainRegistry,
+		logger:        logger,
+	}
+}
+
+// GetChains handles GET /api/chains
+func (cc *ChainController) GetChains(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"chains": cc.chainRegistry.All(),
+	})
+}
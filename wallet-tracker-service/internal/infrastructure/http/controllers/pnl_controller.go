@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type PnLController struct {
+	pnlService services.IPnLService
+	logger     *logs.Logger
+}
+
+func NewPnLController(ps services.IPnLService, logger *logs.Logger) *PnLController {
+	return &PnLController{
+		pnlService: ps,
+		logger:     logger,
+	}
+}
+
+// GetWalletPnL handles GET /api/wallets/pnl?address=ETH.0x123&method=fifo
+// Without method the user's costBasisMethod setting applies.
+func (pc *PnLController) GetWalletPnL(c *fiber.Ctx) error {
+	addressParam := c.Query("address", "")
+	if addressParam == "" {
+		pc.logger.Warnf("Missing query param 'address'")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing query param 'address'",
+		})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	pnl, err := pc.pnlService.GetWalletPnL(userAddr, addressParam, c.Query("method", ""))
+	if err != nil {
+		pc.logger.Errorf("Error computing PnL: %v", err)
+		status := walletErrorStatus(err)
+		if errors.Is(err, services.ErrInvalidSettings) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"caip10": caip10ForParam(addressParam),
+		"pnl":    pnl,
+	})
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type PortfolioController struct {
+	portfolioService services.IPortfolioService
+	logger           *logs.Logger
+}
+
+func NewPortfolioController(ps services.IPortfolioService, logger *logs.Logger) *PortfolioController {
+	return &PortfolioController{
+		portfolioService: ps,
+		logger:           logger,
+	}
+}
+
+type createPortfolioRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Wallets     []string `json:"wallets"`
+}
+
+type updatePortfolioRequest struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Wallets     *[]string `json:"wallets"`
+}
+
+// CreatePortfolio handles POST /api/portfolios with {"name": "...", "wallets": ["ETH.0x123", "eip155:56:0x456"]}
+func (pc *PortfolioController) CreatePortfolio(c *fiber.Ctx) error {
+	var req createPortfolioRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	portfolio, err := pc.portfolioService.CreatePortfolio(userAddr, req.Name, req.Description, req.Wallets)
+	if err != nil {
+		pc.logger.Errorf("Error creating portfolio: %v", err)
+		return c.Status(portfolioErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(portfolio)
+}
+
+// GetPortfolios handles GET /api/portfolios
+func (pc *PortfolioController) GetPortfolios(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	portfolios, err := pc.portfolioService.GetPortfolios(userAddr)
+	if err != nil {
+		pc.logger.Errorf("Error getting portfolios: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(portfolios)
+}
+
+// GetPortfolio handles GET /api/portfolios/:id
+func (pc *PortfolioController) GetPortfolio(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	portfolio, err := pc.portfolioService.GetPortfolio(userAddr, c.Params("id"))
+	if err != nil {
+		pc.logger.Errorf("Error getting portfolio: %v", err)
+		return c.Status(portfolioErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(portfolio)
+}
+
+// UpdatePortfolio handles PATCH /api/portfolios/:id
+func (pc *PortfolioController) UpdatePortfolio(c *fiber.Ctx) error {
+	var req updatePortfolioRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	portfolio, err := pc.portfolioService.UpdatePortfolio(userAddr, c.Params("id"), services.PortfolioUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		Wallets:     req.Wallets,
+	})
+	if err != nil {
+		pc.logger.Errorf("Error updating portfolio: %v", err)
+		return c.Status(portfolioErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(portfolio)
+}
+
+// DeletePortfolio handles DELETE /api/portfolios/:id
+func (pc *PortfolioController) DeletePortfolio(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	if err := pc.portfolioService.DeletePortfolio(userAddr, c.Params("id")); err != nil {
+		pc.logger.Errorf("Error deleting portfolio: %v", err)
+		return c.Status(portfolioErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetPortfolioSummary handles GET /api/portfolios/:id/summary
+func (pc *PortfolioController) GetPortfolioSummary(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	summary, err := pc.portfolioService.GetPortfolioSummary(userAddr, c.Params("id"))
+	if err != nil {
+		pc.logger.Errorf("Error getting portfolio summary: %v", err)
+		return c.Status(portfolioErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(summary)
+}
+
+// portfolioErrorStatus maps portfolio service errors to HTTP status codes
+func portfolioErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidPortfolio):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrPortfolioNotFound):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type SettingsController struct {
+	settingsService services.ISettingsService
+	logger          *logs.Logger
+}
+
+func NewSettingsController(ss services.ISettingsService, logger *logs.Logger) *SettingsController {
+	return &SettingsController{
+		settingsService: ss,
+		logger:          logger,
+	}
+}
+
+type updateSettingsRequest struct {
+	CostBasisMethod *string `json:"costBasisMethod"`
+}
+
+// GetSettings handles GET /api/settings
+func (sc *SettingsController) GetSettings(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	settings, err := sc.settingsService.GetSettings(userAddr)
+	if err != nil {
+		sc.logger.Errorf("Error getting settings: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(settings)
+}
+
+// UpdateSettings handles PATCH /api/settings with {"costBasisMethod": "fifo|lifo|hifo|average"}
+func (sc *SettingsController) UpdateSettings(c *fiber.Ctx) error {
+	var req updateSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	settings, err := sc.settingsService.UpdateSettings(userAddr, services.SettingsUpdate{
+		CostBasisMethod: req.CostBasisMethod,
+	})
+	if err != nil {
+		sc.logger.Errorf("Error updating settings: %v", err)
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidSettings) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(settings)
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/valyala/fasthttp"
+)
+
+// streamHeartbeat keeps idle streams open through proxies and detects closed connections
+const streamHeartbeat = 25 * time.Second
+
+// streamMaxLifetime ends streams opened with a token of unknown expiry, so the client has to
+// authenticate again to keep streaming
+const streamMaxLifetime = time.Hour
+
+type StreamController struct {
+	streamService services.IStreamService
+	logger        *logs.Logger
+}
+
+func NewStreamController(ss services.IStreamService, logger *logs.Logger) *StreamController {
+	return &StreamController{
+		streamService: ss,
+		logger:        logger,
+	}
+}
+
+// CreateTicket handles POST /api/stream/tickets. Browsers' EventSource cannot set headers, so
+// it opens the stream with ?ticket= from this response instead of the Authorization header.
+func (sc *StreamController) CreateTicket(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+	var tokenExpiresAt *time.Time
+	if exp, ok := userData["expiresAt"].(time.Time); ok {
+		tokenExpiresAt = &exp
+	}
+
+	ticket, expiresAt, err := sc.streamService.IssueTicket(userAddr, tokenExpiresAt)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"ticket": ticket, "expiresAt": expiresAt})
+}
+
+// StreamUpdates handles GET /api/stream?wallets=ETH.0x123,BSC.0x456 as Server-Sent Events.
+// Without wallets it streams every wallet of the user. Each stored refresh of a streamed wallet
+// sends a balances.refreshed event, preceded by the change events it found. The stream ends with
+// an expired event when the token it was opened with expires.
+func (sc *StreamController) StreamUpdates(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	closeAt := time.Now().Add(streamMaxLifetime)
+	if exp, ok := userData["expiresAt"].(time.Time); ok && exp.Before(closeAt) {
+		closeAt = exp
+	}
+	if !closeAt.After(time.Now()) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+	}
+
+	var addressParams []string
+	for _, param := range strings.Split(c.Query("wallets", ""), ",") {
+		if param = strings.TrimSpace(param); param != "" {
+			addressParams = append(addressParams, param)
+		}
+	}
+
+	client, err := sc.streamService.Connect(userAddr, addressParams)
+	if err != nil {
+		sc.logger.Errorf("Error opening update stream: %v", err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer sc.streamService.Disconnect(client)
+
+		ready, _ := json.Marshal(fiber.Map{"wallets": client.Wallets})
+		if writeEvent(w, "ready", ready) != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		expiry := time.NewTimer(time.Until(closeAt))
+		defer expiry.Stop()
+		for {
+			select {
+			case <-expiry.C:
+				_ = writeEvent(w, "expired", []byte("{}"))
+				return
+			case envelope, ok := <-client.Updates:
+				if !ok {
+					// Dropped for falling behind; the client reconnects
+					return
+				}
+				if writeEvent(w, envelope.Type, envelope.Data) != nil {
+					return
+				}
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+				if w.Flush() != nil {
+					return
+				}
+			}
+		}
+	}))
+	return nil
+}
+
+// writeEvent writes one SSE event; data is single-line JSON
+func writeEvent(w *bufio.Writer, event string, data []byte) error {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return w.Flush()
+}
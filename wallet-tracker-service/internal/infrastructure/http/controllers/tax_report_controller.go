@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type TaxReportController struct {
+	taxReportService services.ITaxReportService
+	logger           *logs.Logger
+}
+
+func NewTaxReportController(trs services.ITaxReportService, logger *logs.Logger) *TaxReportController {
+	return &TaxReportController{
+		taxReportService: trs,
+		logger:           logger,
+	}
+}
+
+// GetTaxReport handles GET /api/reports/tax?year=2024&jurisdiction=US&method=fifo&format=csv|json
+// The report covers every wallet of the user; without method the user's costBasisMethod setting
+// applies when the jurisdiction allows it.
+func (tc *TaxReportController) GetTaxReport(c *fiber.Ctx) error {
+	year, err := strconv.Atoi(c.Query("year", ""))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid query param 'year'"})
+	}
+	format := strings.ToLower(c.Query("format", "csv"))
+	if format != "csv" && format != "json" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv or json"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	report, err := tc.taxReportService.GetTaxReport(userAddr, year, c.Query("jurisdiction", ""), c.Query("method", ""))
+	if err != nil {
+		tc.logger.Errorf("Error building tax report: %v", err)
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidTaxReport) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if format == "json" {
+		return c.JSON(fiber.Map{"report": report})
+	}
+
+	var buf bytes.Buffer
+	if err := usecases.WriteTaxReportCSV(&buf, report); err != nil {
+		tc.logger.Errorf("Error writing tax report: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="tax-report-%s-%d.csv"`, report.Jurisdiction, report.Year))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
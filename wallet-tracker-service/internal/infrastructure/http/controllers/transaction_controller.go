@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type TransactionController struct {
+	transactionService services.ITransactionService
+	logger             *logs.Logger
+}
+
+func NewTransactionController(ts services.ITransactionService, logger *logs.Logger) *TransactionController {
+	return &TransactionController{
+		transactionService: ts,
+		logger:             logger,
+	}
+}
+
+// GetTransactions handles GET /api/wallets/transactions?address=ETH.0x123&token=USDC&direction=in&from=...&to=...&limit=50&cursor=...
+// Without address the listing covers all of the user's wallets. Pass the returned nextCursor to get the following page.
+func (tc *TransactionController) GetTransactions(c *fiber.Ctx) error {
+	from, err := parseTimeParam(c.Query("from", ""), time.Time{})
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	to, err := parseTimeParam(c.Query("to", ""), time.Time{})
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	addressParam := c.Query("address", "")
+	txs, next, err := tc.transactionService.GetTransactions(userAddr, addressParam, services.TransactionQuery{
+		Token:     c.Query("token", ""),
+		Direction: c.Query("direction", ""),
+		From:      from,
+		To:        to,
+		Cursor:    c.Query("cursor", ""),
+		Limit:     limit,
+	})
+	if err != nil {
+		tc.logger.Errorf("Error getting transactions: %v", err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	response := fiber.Map{
+		"transactions": txs,
+		"nextCursor":   next,
+	}
+	if addressParam != "" {
+		response["caip10"] = caip10ForParam(addressParam)
+	}
+	return c.JSON(response)
+}
+
+type setTransactionLabelRequest struct {
+	Hash  string `json:"hash"`
+	Label string `json:"label"`
+}
+
+// SetTransactionLabel handles PATCH /api/wallets/transactions/label?address=ETH.0x123 with {"hash": "0x...", "label": "swap"}.
+// An empty label removes the override.
+func (tc *TransactionController) SetTransactionLabel(c *fiber.Ctx) error {
+	addressParam := c.Query("address", "")
+	if addressParam == "" {
+		tc.logger.Warnf("Missing query param 'address'")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing query param 'address'",
+		})
+	}
+
+	var req setTransactionLabelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	if err := tc.transactionService.SetTransactionLabel(userAddr, addressParam, req.Hash, req.Label); err != nil {
+		tc.logger.Errorf("Error labeling transaction: %v", err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"hash":  req.Hash,
+		"label": req.Label,
+	})
+}
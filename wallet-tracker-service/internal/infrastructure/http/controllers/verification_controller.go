@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type VerificationController struct {
+	verificationService services.IVerificationService
+	logger              *logs.Logger
+}
+
+func NewVerificationController(vs services.IVerificationService, logger *logs.Logger) *VerificationController {
+	return &VerificationController{
+		verificationService: vs,
+		logger:              logger,
+	}
+}
+
+type challengeRequest struct {
+	Address string `json:"address"`
+}
+
+type verifyRequest struct {
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+}
+
+// CreateChallenge handles POST /api/wallets/verify/challenge with {"address": "ETH.0x123"}
+func (vc *VerificationController) CreateChallenge(c *fiber.Ctx) error {
+	var req challengeRequest
+	if err := c.BodyParser(&req); err != nil || req.Address == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing field 'address'"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	challenge, err := vc.verificationService.CreateChallenge(userAddr, req.Address)
+	if err != nil {
+		vc.logger.Errorf("Error creating challenge: %v", err)
+		return c.Status(verificationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(challenge)
+}
+
+// VerifyWallet handles POST /api/wallets/verify with {"address": "ETH.0x123", "signature": "0x..."}
+func (vc *VerificationController) VerifyWallet(c *fiber.Ctx) error {
+	var req verifyRequest
+	if err := c.BodyParser(&req); err != nil || req.Address == "" || req.Signature == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing fields 'address' and 'signature'"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	wallet, err := vc.verificationService.VerifyWallet(userAddr, req.Address, req.Signature)
+	if err != nil {
+		vc.logger.Errorf("Error verifying wallet: %v", err)
+		return c.Status(verificationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(wallet)
+}
+
+// GetVerification handles GET /api/wallets/verification?address=ETH.0x123
+func (vc *VerificationController) GetVerification(c *fiber.Ctx) error {
+	addressParam := c.Query("address", "")
+	if addressParam == "" {
+		vc.logger.Warnf("Missing query param 'address'")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing query param 'address'",
+		})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	wallet, err := vc.verificationService.GetVerification(userAddr, addressParam)
+	if err != nil {
+		vc.logger.Errorf("Error getting verification: %v", err)
+		return c.Status(verificationErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"caip10":     wallet.CAIP10,
+		"verified":   wallet.Verified,
+		"verifiedAt": wallet.VerifiedAt,
+	})
+}
+
+// verificationErrorStatus maps verification errors to HTTP status codes
+func verificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidSignature):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, services.ErrChallengeNotFound):
+		return fiber.StatusBadRequest
+	default:
+		return walletErrorStatus(err)
+	}
+}
@@ -1,10 +1,17 @@
 package controllers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/usecases"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
 )
 
@@ -20,7 +27,7 @@ func NewWalletController(ws services.IWalletService, logger *logs.Logger) *Walle
 	}
 }
 
-// GetBalanceAndStore handles GET /api/wallets/details?address=BSC.0x123
+// GetBalanceAndStore handles GET /api/wallets/details?address=BSC.0x123 (or address=eip155:56:0x123)
 func (wc *WalletController) GetBalanceAndStore(c *fiber.Ctx) error {
 	addressParam := c.Query("address", "")
 	if addressParam == "" {
@@ -45,7 +52,7 @@ func (wc *WalletController) GetBalanceAndStore(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(wallets)
 }
 
-// GetAllAddresses handles GET /api/wallets/addresses
+// GetAllAddresses handles GET /api/wallets/addresses?format=caip10
 func (wc *WalletController) GetAllAddresses(c *fiber.Ctx) error {
 	userData, ok := c.Locals("user").(map[string]interface{})
 	if !ok {
@@ -53,7 +60,12 @@ func (wc *WalletController) GetAllAddresses(c *fiber.Ctx) error {
 	}
 	userAddr, _ := userData["address"].(string)
 
-	addresses, err := wc.walletService.GetAllAddresses(userAddr)
+	getAddresses := wc.walletService.GetAllAddresses
+	if c.Query("format", "") == "caip10" {
+		getAddresses = wc.walletService.GetAllCAIP10Addresses
+	}
+
+	addresses, err := getAddresses(userAddr)
 	if err != nil {
 		wc.logger.Errorf("Error getting addresses: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
@@ -99,6 +111,7 @@ func (wc *WalletController) GetAllTokensByAddress(c *fiber.Ctx) error {
 
 	// Return response
 	return c.JSON(fiber.Map{
+		"caip10": caip10ForParam(addressParam),
 		"tokens": tokens,
 		"pagination": fiber.Map{
 			"page":  page,
@@ -107,3 +120,360 @@ func (wc *WalletController) GetAllTokensByAddress(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// GetUTXOs handles GET /api/wallets/utxos?address=BTC.xpub...
+func (wc *WalletController) GetUTXOs(c *fiber.Ctx) error {
+	addressParam := c.Query("address", "")
+	if addressParam == "" {
+		wc.logger.Warnf("Missing query param 'address'")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing query param 'address'",
+		})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	utxos, err := wc.walletService.GetWalletUTXOs(userAddr, addressParam)
+	if err != nil {
+		wc.logger.Errorf("Error getting UTXOs: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"caip10": caip10ForParam(addressParam), "utxos": utxos})
+}
+
+type addWalletRequest struct {
+	Address string   `json:"address"`
+	Label   string   `json:"label"`
+	Tags    []string `json:"tags"`
+}
+
+type updateWalletRequest struct {
+	Label *string   `json:"label"`
+	Tags  *[]string `json:"tags"`
+}
+
+// AddWallet handles POST /api/wallets with {"address": "ETH.0x123", "label": "...", "tags": [...]}
+func (wc *WalletController) AddWallet(c *fiber.Ctx) error {
+	var req addWalletRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if req.Address == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing field 'address'"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	wallet, err := wc.walletService.AddWallet(userAddr, req.Address, req.Label, req.Tags)
+	if err != nil {
+		wc.logger.Errorf("Error adding wallet: %v", err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(wallet)
+}
+
+// UpdateWallet handles PATCH /api/wallets?address=ETH.0x123 with {"label": "...", "tags": [...]}
+func (wc *WalletController) UpdateWallet(c *fiber.Ctx) error {
+	addressParam := c.Query("address", "")
+	if addressParam == "" {
+		wc.logger.Warnf("Missing query param 'address'")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing query param 'address'",
+		})
+	}
+
+	var req updateWalletRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if req.Label == nil && req.Tags == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Nothing to update, expect 'label' and/or 'tags'"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	wallet, err := wc.walletService.UpdateWallet(userAddr, addressParam, services.WalletUpdate{
+		Label: req.Label,
+		Tags:  req.Tags,
+	})
+	if err != nil {
+		wc.logger.Errorf("Error updating wallet: %v", err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(wallet)
+}
+
+// DeleteWallet handles DELETE /api/wallets?address=ETH.0x123
+func (wc *WalletController) DeleteWallet(c *fiber.Ctx) error {
+	addressParam := c.Query("address", "")
+	if addressParam == "" {
+		wc.logger.Warnf("Missing query param 'address'")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing query param 'address'",
+		})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	if err := wc.walletService.DeleteWallet(userAddr, addressParam); err != nil {
+		wc.logger.Errorf("Error deleting wallet: %v", err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ImportWallets handles POST /api/wallets/import with a CSV or JSON body, or a multipart "file" field.
+// The format comes from ?format=csv|json, else from the content type or file extension.
+func (wc *WalletController) ImportWallets(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	format := strings.ToLower(c.Query("format", ""))
+	var body io.Reader = bytes.NewReader(c.Body())
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Could not read uploaded file"})
+		}
+		defer f.Close()
+		body = f
+		if format == "" {
+			format = walletFileFormat(file.Header.Get("Content-Type"), file.Filename)
+		}
+	} else if format == "" {
+		format = walletFileFormat(c.Get(fiber.HeaderContentType), "")
+	}
+	if format == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unknown file format, expect CSV or JSON"})
+	}
+
+	records, err := usecases.ParseWalletFile(format, body)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	results, err := wc.walletService.ImportWallets(userAddr, records)
+	if err != nil {
+		wc.logger.Errorf("Error importing wallets: %v", err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"summary": fiber.Map{
+			"total":   len(results),
+			"created": counts[services.ImportStatusCreated],
+			"updated": counts[services.ImportStatusUpdated],
+			"failed":  counts[services.ImportStatusFailed],
+		},
+		"results": results,
+	})
+}
+
+// ExportWallets handles GET /api/wallets/export?format=csv|json
+func (wc *WalletController) ExportWallets(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", usecases.WalletFileCSV))
+	if format != usecases.WalletFileCSV && format != usecases.WalletFileJSON {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv or json"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	records, err := wc.walletService.ExportWallets(userAddr)
+	if err != nil {
+		wc.logger.Errorf("Error exporting wallets: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var buf bytes.Buffer
+	if err := usecases.WriteWalletFile(format, &buf, records); err != nil {
+		wc.logger.Errorf("Error writing wallet export: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == usecases.WalletFileJSON {
+		contentType = fiber.MIMEApplicationJSONCharsetUTF8
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="wallets.%s"`, format))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// walletFileFormat guesses the import format from a content type or file name
+func walletFileFormat(contentType, filename string) string {
+	contentType = strings.ToLower(contentType)
+	filename = strings.ToLower(filename)
+	switch {
+	case strings.Contains(contentType, "csv") || strings.HasSuffix(filename, ".csv"):
+		return usecases.WalletFileCSV
+	case strings.Contains(contentType, "json") || strings.HasSuffix(filename, ".json"):
+		return usecases.WalletFileJSON
+	default:
+		return ""
+	}
+}
+
+// walletErrorStatus maps wallet service errors to HTTP status codes
+func walletErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidWallet):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrWalletNotFound), errors.Is(err, services.ErrSnapshotNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrWalletAlreadyExists):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// GetBalancesAt handles GET /api/wallets/balances?address=ETH.0x123&at=2024-05-01T00:00:00Z
+// "at" may also be a unix timestamp in seconds; it defaults to now.
+func (wc *WalletController) GetBalancesAt(c *fiber.Ctx) error {
+	addressParam := c.Query("address", "")
+	if addressParam == "" {
+		wc.logger.Warnf("Missing query param 'address'")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Missing query param 'address'",
+		})
+	}
+
+	at, err := parseTimeParam(c.Query("at", ""), time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	snapshot, err := wc.walletService.GetBalancesAt(userAddr, addressParam, at)
+	if err != nil {
+		wc.logger.Errorf("Error getting balances at %s: %v", at.Format(time.RFC3339), err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"caip10":    caip10ForParam(addressParam),
+		"at":        at,
+		"timestamp": snapshot.Timestamp,
+		"totalUsd":  snapshot.TotalUSD,
+		"balances":  snapshot.Balances,
+	})
+}
+
+// historyRanges are the preset chart ranges and their default bucket
+var historyRanges = map[string]struct {
+	span   time.Duration
+	bucket string
+}{
+	"24h": {24 * time.Hour, "hour"},
+	"7d":  {7 * 24 * time.Hour, "hour"},
+	"30d": {30 * 24 * time.Hour, "day"},
+	"1y":  {365 * 24 * time.Hour, "week"},
+}
+
+// GetValueHistory handles GET /api/wallets/history?address=ETH.0x123&range=30d&bucket=day
+// Without address the series covers all of the user's wallets. Instead of range, from/to
+// (RFC 3339 or unix seconds) select an explicit window.
+func (wc *WalletController) GetValueHistory(c *fiber.Ctx) error {
+	rangeParam := c.Query("range", "30d")
+	preset, ok := historyRanges[rangeParam]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "range must be one of 24h, 7d, 30d, 1y"})
+	}
+
+	to, err := parseTimeParam(c.Query("to", ""), time.Now().UTC())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	from, err := parseTimeParam(c.Query("from", ""), to.Add(-preset.span))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	bucket := c.Query("bucket", preset.bucket)
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	addressParam := c.Query("address", "")
+	points, err := wc.walletService.GetValueHistory(userAddr, addressParam, from, to, bucket)
+	if err != nil {
+		wc.logger.Errorf("Error getting value history: %v", err)
+		return c.Status(walletErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	response := fiber.Map{
+		"from":   from,
+		"to":     to,
+		"bucket": bucket,
+		"points": points,
+	}
+	if addressParam != "" {
+		response["caip10"] = caip10ForParam(addressParam)
+	}
+	return c.JSON(response)
+}
+
+// parseTimeParam parses an RFC 3339 or unix-seconds query value, returning def when empty
+func parseTimeParam(value string, def time.Time) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s', expect RFC 3339 or unix seconds", value)
+	}
+	return t, nil
+}
+
+// caip10ForParam converts an address query param into its CAIP-10 account ID
+func caip10ForParam(addressParam string) string {
+	bc, addr, err := usecases.ParseBlockchainAndAddress(addressParam)
+	if err != nil {
+		return ""
+	}
+	if normalized, err := services.NormalizeAddress(bc, addr); err == nil {
+		addr = normalized
+	}
+	return usecases.FormatCAIP10(bc, addr)
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type WebhookController struct {
+	webhookService services.IWebhookService
+	logger         *logs.Logger
+}
+
+func NewWebhookController(ws services.IWebhookService, logger *logs.Logger) *WebhookController {
+	return &WebhookController{
+		webhookService: ws,
+		logger:         logger,
+	}
+}
+
+type createWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+type updateWebhookRequest struct {
+	URL        *string   `json:"url"`
+	EventTypes *[]string `json:"eventTypes"`
+	Enabled    *bool     `json:"enabled"`
+}
+
+// CreateWebhook handles POST /api/webhooks with {"url": "https://...", "eventTypes": ["balance.increased"]}.
+// The response holds the signing secret, which later reads omit.
+func (wc *WebhookController) CreateWebhook(c *fiber.Ctx) error {
+	var req createWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	sub, err := wc.webhookService.CreateSubscription(userAddr, req.URL, req.EventTypes, req.Enabled)
+	if err != nil {
+		wc.logger.Errorf("Error creating webhook: %v", err)
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(sub)
+}
+
+// GetWebhooks handles GET /api/webhooks
+func (wc *WebhookController) GetWebhooks(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	subs, err := wc.webhookService.GetSubscriptions(userAddr)
+	if err != nil {
+		wc.logger.Errorf("Error getting webhooks: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(subs)
+}
+
+// GetWebhook handles GET /api/webhooks/:id
+func (wc *WebhookController) GetWebhook(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	sub, err := wc.webhookService.GetSubscription(userAddr, c.Params("id"))
+	if err != nil {
+		wc.logger.Errorf("Error getting webhook: %v", err)
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sub)
+}
+
+// UpdateWebhook handles PATCH /api/webhooks/:id
+func (wc *WebhookController) UpdateWebhook(c *fiber.Ctx) error {
+	var req updateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	sub, err := wc.webhookService.UpdateSubscription(userAddr, c.Params("id"), services.WebhookUpdate{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Enabled:    req.Enabled,
+	})
+	if err != nil {
+		wc.logger.Errorf("Error updating webhook: %v", err)
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sub)
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/:id
+func (wc *WebhookController) DeleteWebhook(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	if err := wc.webhookService.DeleteSubscription(userAddr, c.Params("id")); err != nil {
+		wc.logger.Errorf("Error deleting webhook: %v", err)
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetDeadLetters handles GET /api/webhooks/dead-letters?limit=50
+func (wc *WebhookController) GetDeadLetters(c *fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	deliveries, err := wc.webhookService.GetDeadLetters(userAddr, limit)
+	if err != nil {
+		wc.logger.Errorf("Error getting webhook dead letters: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(deliveries)
+}
+
+// ReplayDeadLetter handles POST /api/webhooks/dead-letters/:id/replay
+func (wc *WebhookController) ReplayDeadLetter(c *fiber.Ctx) error {
+	userData, ok := c.Locals("user").(map[string]interface{})
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+	}
+	userAddr, _ := userData["address"].(string)
+
+	delivery, err := wc.webhookService.ReplayDeadLetter(userAddr, c.Params("id"))
+	if err != nil {
+		wc.logger.Errorf("Error replaying webhook dead letter: %v", err)
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
+
+// webhookErrorStatus maps webhook service errors to HTTP status codes
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidWebhook):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrWebhookNotFound), errors.Is(err, services.ErrDeadLetterNotFound):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
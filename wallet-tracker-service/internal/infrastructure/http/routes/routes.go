@@ -4,11 +4,19 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/panoramablock/wallet-tracker-service/internal/application/services"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/config"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/contracts"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/events"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/http/controllers"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/prices"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/providers"
 	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/repositories"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/security"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/stream"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/webhooks"
 )
 
 func SetupRoutes(
@@ -16,17 +24,52 @@ func SetupRoutes(
 	logger *logs.Logger,
 	mongoClient *dbmongo.MongoClient,
 	redisClient *redis.Client,
+	balanceProvider providers.IBalanceProvider,
+	priceOracle prices.IPriceOracle,
+	transactionProvider providers.ITransactionProvider,
+	chainRegistry *chains.Registry,
+	eventBus events.IEventBus,
+	streamHub *stream.Hub,
+	balanceRefresher services.IBalanceRefresher,
 	conf *config.Config,
 ) {
 	// Repositories
 	walletRepo := repositories.NewWalletRepository(mongoClient, conf.MongoDBName)
 	balanceRepo := repositories.NewBalanceRepository(mongoClient, conf.MongoDBName)
+	portfolioRepo := repositories.NewPortfolioRepository(mongoClient, conf.MongoDBName)
+	challengeRepo := repositories.NewChallengeRepository(mongoClient, conf.MongoDBName)
+	snapshotRepo := repositories.NewSnapshotRepository(mongoClient, conf.MongoDBName)
+	settingsRepo := repositories.NewSettingsRepository(mongoClient, conf.MongoDBName)
+	transactionRepo := repositories.NewTransactionRepository(mongoClient, conf.MongoDBName)
+	alertRepo := repositories.NewAlertRepository(mongoClient, conf.MongoDBName)
+	webhookRepo := repositories.NewWebhookRepository(mongoClient, conf.MongoDBName)
+	leaseRepo := repositories.NewLeaseRepository(mongoClient, conf.MongoDBName)
 
 	// Services
-	walletService := services.NewWalletService(logger, walletRepo, balanceRepo, redisClient)
+	walletService := services.NewWalletService(logger, walletRepo, balanceRepo, snapshotRepo, balanceProvider, priceOracle, redisClient, eventBus)
+	portfolioService := services.NewPortfolioService(logger, portfolioRepo, walletRepo, balanceRepo)
+	verificationService := services.NewVerificationService(logger, walletRepo, challengeRepo, conf.SIWEDomain, conf.SIWEURI)
+	settingsService := services.NewSettingsService(logger, settingsRepo)
+	transactionService := services.NewTransactionService(logger, walletRepo, transactionRepo, transactionProvider, contracts.Get(), leaseRepo)
+	pnlService := services.NewPnLService(logger, walletRepo, balanceRepo, transactionService, priceOracle, settingsService, chainRegistry)
+	taxReportService := services.NewTaxReportService(logger, walletRepo, transactionService, priceOracle, settingsService, chainRegistry)
+	alertService := services.NewAlertService(logger, alertRepo, walletRepo, priceOracle, eventBus, leaseRepo)
+	streamService := services.NewStreamService(logger, walletRepo, repositories.NewStreamTicketRepository(mongoClient, conf.MongoDBName), streamHub)
+	webhookService := services.NewWebhookService(logger, webhookRepo, walletRepo, webhooks.NewSender(), eventBus, conf.WebhookMaxAttempts)
 
 	// Controllers
 	walletController := controllers.NewWalletController(walletService, logger)
+	chainController := controllers.NewChainController(chainRegistry, logger)
+	portfolioController := controllers.NewPortfolioController(portfolioService, logger)
+	verificationController := controllers.NewVerificationController(verificationService, logger)
+	settingsController := controllers.NewSettingsController(settingsService, logger)
+	pnlController := controllers.NewPnLController(pnlService, logger)
+	transactionController := controllers.NewTransactionController(transactionService, logger)
+	taxReportController := controllers.NewTaxReportController(taxReportService, logger)
+	alertController := controllers.NewAlertController(alertService, logger)
+	webhookController := controllers.NewWebhookController(webhookService, logger)
+	streamController := controllers.NewStreamController(streamService, logger)
+	adminController := controllers.NewAdminController(balanceRefresher, logger)
 
 	// API version group
 	api := app.Group("/api")
@@ -39,9 +82,70 @@ func SetupRoutes(
 		})
 	})
 
+	// Chain registry
+	api.Get("/chains", chainController.GetChains)
+
+	// User settings
+	api.Get("/settings", settingsController.GetSettings)
+	api.Patch("/settings", settingsController.UpdateSettings)
+
 	// Wallet Routes
 	walletAPI := api.Group("/wallets")
+	walletAPI.Post("/", walletController.AddWallet)
+	walletAPI.Patch("/", walletController.UpdateWallet)
+	walletAPI.Delete("/", walletController.DeleteWallet)
 	walletAPI.Get("/details", walletController.GetBalanceAndStore)
 	walletAPI.Get("/addresses", walletController.GetAllAddresses)
 	walletAPI.Get("/tokens", walletController.GetAllTokensByAddress)
-} 
\ No newline at end of file
+	walletAPI.Get("/pnl", pnlController.GetWalletPnL)
+	walletAPI.Get("/utxos", walletController.GetUTXOs)
+	walletAPI.Get("/balances", walletController.GetBalancesAt)
+	walletAPI.Get("/history", walletController.GetValueHistory)
+	walletAPI.Get("/transactions", transactionController.GetTransactions)
+	walletAPI.Patch("/transactions/label", transactionController.SetTransactionLabel)
+	walletAPI.Post("/import", walletController.ImportWallets)
+	walletAPI.Get("/export", walletController.ExportWallets)
+	walletAPI.Post("/verify/challenge", verificationController.CreateChallenge)
+	walletAPI.Post("/verify", verificationController.VerifyWallet)
+	walletAPI.Get("/verification", verificationController.GetVerification)
+
+	// Reports
+	reportAPI := api.Group("/reports")
+	reportAPI.Get("/tax", taxReportController.GetTaxReport)
+
+	// Alerts
+	alertAPI := api.Group("/alerts")
+	alertAPI.Get("/", alertController.GetAlerts)
+	alertAPI.Get("/rules", alertController.GetAlertRules)
+	alertAPI.Post("/rules", alertController.CreateAlertRule)
+	alertAPI.Get("/rules/:id", alertController.GetAlertRule)
+	alertAPI.Patch("/rules/:id", alertController.UpdateAlertRule)
+	alertAPI.Delete("/rules/:id", alertController.DeleteAlertRule)
+
+	// Live balance updates (Server-Sent Events)
+	api.Get("/stream", streamController.StreamUpdates)
+	api.Post("/stream/tickets", streamController.CreateTicket)
+
+	// Webhooks
+	webhookAPI := api.Group("/webhooks")
+	webhookAPI.Get("/", webhookController.GetWebhooks)
+	webhookAPI.Post("/", webhookController.CreateWebhook)
+	webhookAPI.Get("/dead-letters", webhookController.GetDeadLetters)
+	webhookAPI.Post("/dead-letters/:id/replay", webhookController.ReplayDeadLetter)
+	webhookAPI.Get("/:id", webhookController.GetWebhook)
+	webhookAPI.Patch("/:id", webhookController.UpdateWebhook)
+	webhookAPI.Delete("/:id", webhookController.DeleteWebhook)
+
+	// Portfolio Routes
+	portfolioAPI := api.Group("/portfolios")
+	portfolioAPI.Get("/", portfolioController.GetPortfolios)
+	portfolioAPI.Post("/", portfolioController.CreatePortfolio)
+	portfolioAPI.Get("/:id", portfolioController.GetPortfolio)
+	portfolioAPI.Patch("/:id", portfolioController.UpdatePortfolio)
+	portfolioAPI.Delete("/:id", portfolioController.DeletePortfolio)
+	portfolioAPI.Get("/:id/summary", portfolioController.GetPortfolioSummary)
+
+	// Admin Routes
+	adminAPI := api.Group("/admin", security.NewAdminMiddleware(conf.AdminAddresses))
+	adminAPI.Get("/refresh/runs", adminController.GetRefreshRuns)
+}
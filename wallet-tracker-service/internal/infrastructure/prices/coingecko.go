@@ -0,0 +1,153 @@
+package prices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCoingeckoBaseURL = "https://api.coingecko.com/api/v3"
+	coingeckoProBaseURL     = "https://pro-api.coingecko.com/api/v3"
+)
+
+// coingeckoPlatforms maps chain keys to CoinGecko asset platform IDs
+var coingeckoPlatforms = map[string]string{
+	"ETH":         "ethereum",
+	"BSC":         "binance-smart-chain",
+	"POLYGON":     "polygon-pos",
+	"OPTIMISM":    "optimistic-ethereum",
+	"ARBITRUM":    "arbitrum-one",
+	"BASE":        "base",
+	"AVAX_CCHAIN": "avalanche",
+	"CELO":        "celo",
+	"FANTOM":      "fantom",
+	"SOLANA":      "solana",
+	"TRON":        "tron",
+}
+
+// CoingeckoSource fetches prices from the CoinGecko simple price endpoints
+type CoingeckoSource struct {
+	apiKey     string
+	keyHeader  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewCoingeckoSource creates a source for a pro or a demo API key. Pro keys are only accepted
+// by the pro host and demo keys only by the public one, so the default base URL follows the key.
+func NewCoingeckoSource(proAPIKey, demoAPIKey, baseURL string) *CoingeckoSource {
+	source := &CoingeckoSource{
+		baseURL:    defaultCoingeckoBaseURL,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+	switch {
+	case proAPIKey != "":
+		source.apiKey = proAPIKey
+		source.keyHeader = "x-cg-pro-api-key"
+		source.baseURL = coingeckoProBaseURL
+	case demoAPIKey != "":
+		source.apiKey = demoAPIKey
+		source.keyHeader = "x-cg-demo-api-key"
+	}
+	if baseURL != "" {
+		source.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	return source
+}
+
+func (s *CoingeckoSource) Name() string {
+	return "coingecko"
+}
+
+// GetPrices queries /simple/price for IDs and /simple/token_price per platform for contracts
+func (s *CoingeckoSource) GetPrices(ctx context.Context, keys []PriceKey) (map[PriceKey]float64, error) {
+	result := make(map[PriceKey]float64, len(keys))
+
+	var ids []string
+	byPlatform := make(map[string][]PriceKey)
+	for _, k := range keys {
+		if k.CoingeckoID != "" {
+			ids = append(ids, k.CoingeckoID)
+			continue
+		}
+		if platform, ok := coingeckoPlatforms[k.Blockchain]; ok {
+			byPlatform[platform] = append(byPlatform[platform], k)
+		}
+	}
+
+	if len(ids) > 0 {
+		query := url.Values{}
+		query.Set("ids", strings.Join(ids, ","))
+		query.Set("vs_currencies", "usd")
+
+		var res map[string]map[string]float64
+		if err := s.get(ctx, "/simple/price?"+query.Encode(), &res); err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			if price, ok := res[k.CoingeckoID]["usd"]; ok && k.CoingeckoID != "" {
+				result[k] = price
+			}
+		}
+	}
+
+	for platform, platformKeys := range byPlatform {
+		contracts := make([]string, 0, len(platformKeys))
+		for _, k := range platformKeys {
+			contracts = append(contracts, k.Contract)
+		}
+
+		query := url.Values{}
+		query.Set("contract_addresses", strings.Join(contracts, ","))
+		query.Set("vs_currencies", "usd")
+
+		var res map[string]map[string]float64
+		if err := s.get(ctx, fmt.Sprintf("/simple/token_price/%s?%s", platform, query.Encode()), &res); err != nil {
+			return nil, err
+		}
+		// CoinGecko returns EVM contracts lower-cased
+		for _, k := range platformKeys {
+			if price, ok := res[normalizeContract(k.Blockchain, k.Contract)]["usd"]; ok {
+				result[k] = price
+			} else if price, ok := res[strings.ToLower(k.Contract)]["usd"]; ok {
+				result[k] = price
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *CoingeckoSource) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create coingecko request: %w", err)
+	}
+	if s.apiKey != "" {
+		req.Header.Set(s.keyHeader, s.apiKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed calling coingecko API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("coingecko API error: %s", string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal coingecko response: %w", err)
+	}
+	return nil
+}
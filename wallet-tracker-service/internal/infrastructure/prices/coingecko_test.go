@@ -0,0 +1,56 @@
+package prices
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCoingeckoSourceAPIKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		proKey    string
+		demoKey   string
+		wantURL   string
+		wantKey   string
+		wantValue string
+	}{
+		{"no key", "", "", defaultCoingeckoBaseURL, "", ""},
+		{"pro key", "pro", "", coingeckoProBaseURL, "x-cg-pro-api-key", "pro"},
+		{"demo key", "", "demo", defaultCoingeckoBaseURL, "x-cg-demo-api-key", "demo"},
+		{"both keys", "pro", "demo", coingeckoProBaseURL, "x-cg-pro-api-key", "pro"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewCoingeckoSource(tt.proKey, tt.demoKey, "").baseURL; got != tt.wantURL {
+				t.Errorf("base URL = %s, want %s", got, tt.wantURL)
+			}
+
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				_, _ = w.Write([]byte(`{"ethereum":{"usd":2000}}`))
+			}))
+			defer server.Close()
+
+			source := NewCoingeckoSource(tt.proKey, tt.demoKey, server.URL)
+			prices, err := source.GetPrices(context.Background(), []PriceKey{{CoingeckoID: "ethereum"}})
+			if err != nil {
+				t.Fatalf("GetPrices: %v", err)
+			}
+			if prices[PriceKey{CoingeckoID: "ethereum"}] != 2000 {
+				t.Errorf("prices = %v", prices)
+			}
+			for _, name := range []string{"x-cg-pro-api-key", "x-cg-demo-api-key"} {
+				want := ""
+				if name == tt.wantKey {
+					want = tt.wantValue
+				}
+				if got := header.Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
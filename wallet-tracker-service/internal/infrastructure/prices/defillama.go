@@ -0,0 +1,203 @@
+package prices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDefiLlamaBaseURL = "https://coins.llama.fi"
+
+	// defiLlamaSearchWidth is how far, in seconds, a historical price may be from the time asked for
+	defiLlamaSearchWidth = 6 * 3600
+)
+
+// defiLlamaChains maps chain keys to DefiLlama chain prefixes
+var defiLlamaChains = map[string]string{
+	"ETH":         "ethereum",
+	"BSC":         "bsc",
+	"POLYGON":     "polygon",
+	"OPTIMISM":    "optimism",
+	"ARBITRUM":    "arbitrum",
+	"BASE":        "base",
+	"AVAX_CCHAIN": "avax",
+	"CELO":        "celo",
+	"FANTOM":      "fantom",
+	"SOLANA":      "solana",
+	"TRON":        "tron",
+}
+
+// DefiLlamaSource fetches prices from the DefiLlama coins API
+type DefiLlamaSource struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewDefiLlamaSource(baseURL string) *DefiLlamaSource {
+	if baseURL == "" {
+		baseURL = defaultDefiLlamaBaseURL
+	}
+	return &DefiLlamaSource{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (s *DefiLlamaSource) Name() string {
+	return "defillama"
+}
+
+// GetPrices queries /prices/current with coingecko:<id> and <chain>:<contract> coin IDs
+func (s *DefiLlamaSource) GetPrices(ctx context.Context, keys []PriceKey) (map[PriceKey]float64, error) {
+	coins := make(map[string]PriceKey, len(keys))
+	for _, k := range keys {
+		if id, ok := defiLlamaCoinID(k); ok {
+			coins[id] = k
+		}
+	}
+	if len(coins) == 0 {
+		return map[PriceKey]float64{}, nil
+	}
+
+	ids := make([]string, 0, len(coins))
+	for id := range coins {
+		ids = append(ids, id)
+	}
+
+	var res struct {
+		Coins map[string]struct {
+			Price float64 `json:"price"`
+		} `json:"coins"`
+	}
+	if err := s.get(ctx, "/prices/current/"+strings.Join(ids, ","), &res); err != nil {
+		return nil, err
+	}
+
+	result := make(map[PriceKey]float64, len(res.Coins))
+	for id, coin := range res.Coins {
+		if k, ok := matchCoin(coins, id); ok {
+			result[k] = coin.Price
+		}
+	}
+	return result, nil
+}
+
+// GetHistoricalPrices queries /batchHistorical, taking for each key the price nearest its hour
+func (s *DefiLlamaSource) GetHistoricalPrices(ctx context.Context, keys []HistoricalKey) (map[HistoricalKey]float64, error) {
+	coins := make(map[string]PriceKey)
+	hours := make(map[string][]int64)
+	for _, k := range keys {
+		id, ok := defiLlamaCoinID(k.PriceKey)
+		if !ok {
+			continue
+		}
+		coins[id] = k.PriceKey
+		hours[id] = append(hours[id], k.Hour)
+	}
+	if len(coins) == 0 {
+		return map[HistoricalKey]float64{}, nil
+	}
+
+	query, err := json.Marshal(hours)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("coins", string(query))
+	params.Set("searchWidth", strconv.Itoa(defiLlamaSearchWidth))
+
+	var res struct {
+		Coins map[string]struct {
+			Prices []struct {
+				Timestamp int64   `json:"timestamp"`
+				Price     float64 `json:"price"`
+			} `json:"prices"`
+		} `json:"coins"`
+	}
+	if err := s.get(ctx, "/batchHistorical?"+params.Encode(), &res); err != nil {
+		return nil, err
+	}
+
+	result := make(map[HistoricalKey]float64)
+	for id, coin := range res.Coins {
+		k, ok := matchCoin(coins, id)
+		if !ok || len(coin.Prices) == 0 {
+			continue
+		}
+		for _, hour := range hours[id] {
+			nearest := coin.Prices[0]
+			for _, p := range coin.Prices[1:] {
+				if abs64(p.Timestamp-hour) < abs64(nearest.Timestamp-hour) {
+					nearest = p
+				}
+			}
+			if abs64(nearest.Timestamp-hour) <= defiLlamaSearchWidth {
+				result[HistoricalKey{PriceKey: k, Hour: hour}] = nearest.Price
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *DefiLlamaSource) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create defillama request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed calling defillama API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("defillama API error: %s", string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal defillama response: %w", err)
+	}
+	return nil
+}
+
+// defiLlamaCoinID returns the coingecko:<id> or <chain>:<contract> coin ID of a key
+func defiLlamaCoinID(k PriceKey) (string, bool) {
+	if k.CoingeckoID != "" {
+		return "coingecko:" + k.CoingeckoID, true
+	}
+	if chain, ok := defiLlamaChains[k.Blockchain]; ok {
+		return fmt.Sprintf("%s:%s", chain, k.Contract), true
+	}
+	return "", false
+}
+
+// matchCoin finds the requested key of a coin ID; DefiLlama may echo EVM contracts in a different case
+func matchCoin(coins map[string]PriceKey, id string) (PriceKey, bool) {
+	if k, ok := coins[id]; ok {
+		return k, true
+	}
+	for requested, k := range coins {
+		if strings.EqualFold(requested, id) {
+			return k, true
+		}
+	}
+	return PriceKey{}, false
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
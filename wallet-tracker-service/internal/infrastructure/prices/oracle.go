@@ -0,0 +1,390 @@
+package prices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/config"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultPriceCacheTTL = 5 * time.Minute
+	priceBatchSize       = 100
+
+	// Past prices do not change, so they are kept much longer than current ones
+	historicalPriceCacheTTL = 30 * 24 * time.Hour
+
+	// lastKnownPriceTTL is how long a price stays usable while every source is failing
+	lastKnownPriceTTL = 7 * 24 * time.Hour
+)
+
+// ErrPricesUnavailable is returned when every price source failed
+var ErrPricesUnavailable = errors.New("every price source failed")
+
+// IPriceOracle values wallet balances in USD, now or in the past
+type IPriceOracle interface {
+	PriceWallets(ctx context.Context, wallets []entities.Wallet) error
+	GetHistoricalPrices(ctx context.Context, keys []HistoricalKey) (map[HistoricalKey]float64, error)
+}
+
+// Quote is a USD price with the time it was observed
+type Quote struct {
+	Price float64   `json:"price"`
+	At    time.Time `json:"at"`
+}
+
+// Oracle aggregates the configured price sources, caching results in Redis
+type Oracle struct {
+	sources     []IPriceSource
+	historical  []IHistoricalPriceSource
+	redisClient *redis.Client
+	ttl         time.Duration
+	logger      *logs.Logger
+}
+
+// NewOracle uses the sources that support history for past prices, or DefiLlama when none does
+func NewOracle(sources []IPriceSource, redisClient *redis.Client, ttl time.Duration, logger *logs.Logger) *Oracle {
+	if ttl <= 0 {
+		ttl = defaultPriceCacheTTL
+	}
+	var historical []IHistoricalPriceSource
+	for _, src := range sources {
+		if h, ok := src.(IHistoricalPriceSource); ok {
+			historical = append(historical, h)
+		}
+	}
+	if len(historical) == 0 {
+		historical = append(historical, NewDefiLlamaSource(""))
+	}
+	return &Oracle{
+		sources:     sources,
+		historical:  historical,
+		redisClient: redisClient,
+		ttl:         ttl,
+		logger:      logger,
+	}
+}
+
+// NewOracleFromConfig builds an oracle with the sources listed in config
+func NewOracleFromConfig(conf *config.Config, redisClient *redis.Client, logger *logs.Logger) (*Oracle, error) {
+	var sources []IPriceSource
+	for _, name := range conf.PriceSources {
+		switch strings.ToLower(name) {
+		case "coingecko":
+			sources = append(sources, NewCoingeckoSource(conf.CoingeckoAPIKey, conf.CoingeckoDemoAPIKey, conf.CoingeckoBaseURL))
+		case "defillama":
+			sources = append(sources, NewDefiLlamaSource(""))
+		default:
+			return nil, fmt.Errorf("unknown price source '%s'", name)
+		}
+	}
+	return NewOracle(sources, redisClient, conf.PriceCacheTTL, logger), nil
+}
+
+// PriceWallets sets Asset.USDPrice and Balance.USDValue for every priced balance, and the wallet
+// total to the sum of the values kept. When every source fails, balances keep their last known
+// price; those without one are marked Unpriced and an error wrapping ErrPricesUnavailable is returned.
+func (o *Oracle) PriceWallets(ctx context.Context, wallets []entities.Wallet) error {
+	var keys []PriceKey
+	seen := make(map[PriceKey]bool)
+	for _, w := range wallets {
+		for _, b := range w.Balances {
+			k, ok := keyFor(w.Blockchain, b.Asset)
+			if ok && !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	// When every source fails, quotes still holds cached and last known prices
+	quotes, quoteErr := o.GetQuotes(ctx, keys)
+
+	unpriced := 0
+	for i := range wallets {
+		total := entities.USD{}
+		for j := range wallets[i].Balances {
+			b := &wallets[i].Balances[j]
+			if k, ok := keyFor(wallets[i].Blockchain, b.Asset); ok {
+				if q, ok := quotes[k]; ok {
+					price := entities.NewUSDFromFloat(q.Price)
+					b.Asset.USDPrice = price
+					b.USDValue = price.MulRat(b.Amount.Rat(b.Asset.Decimals))
+					pricedAt := q.At
+					b.PricedAt = &pricedAt
+					b.Unpriced = false
+				} else if quoteErr != nil && b.PricedAt == nil {
+					// No source could be asked and there is no earlier price to keep
+					b.Unpriced = true
+					unpriced++
+				}
+			}
+			total = total.Add(b.USDValue)
+		}
+		wallets[i].Balance = total
+	}
+
+	if unpriced > 0 {
+		return fmt.Errorf("%d balances left unpriced: %w", unpriced, quoteErr)
+	}
+	if quoteErr != nil {
+		o.logger.Warnf("Priced wallets at last known prices: %v", quoteErr)
+	}
+	return nil
+}
+
+// GetQuotes returns prices for the keys, reading through the Redis cache. When every source fails
+// it returns the cached and last known prices it has, with an error wrapping ErrPricesUnavailable.
+func (o *Oracle) GetQuotes(ctx context.Context, keys []PriceKey) (map[PriceKey]Quote, error) {
+	quotes := o.readCache(ctx, keys, cacheKey)
+
+	var missing []PriceKey
+	for _, k := range keys {
+		if _, ok := quotes[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) == 0 || len(o.sources) == 0 {
+		return quotes, nil
+	}
+
+	// Every source is asked concurrently; the median of their answers is used
+	observed := make(map[PriceKey][]float64)
+	var failures []string
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, src := range o.sources {
+		wg.Add(1)
+		go func(src IPriceSource) {
+			defer wg.Done()
+			for start := 0; start < len(missing); start += priceBatchSize {
+				end := start + priceBatchSize
+				if end > len(missing) {
+					end = len(missing)
+				}
+				res, err := src.GetPrices(ctx, missing[start:end])
+				if err != nil {
+					o.logger.Warnf("Price source %s failed: %v", src.Name(), err)
+					mu.Lock()
+					failures = append(failures, fmt.Sprintf("%s: %v", src.Name(), err))
+					mu.Unlock()
+					return
+				}
+				mu.Lock()
+				for k, price := range res {
+					if price > 0 {
+						observed[k] = append(observed[k], price)
+					}
+				}
+				mu.Unlock()
+			}
+		}(src)
+	}
+	wg.Wait()
+
+	now := time.Now().UTC()
+	fresh := make(map[PriceKey]Quote, len(observed))
+	for k, values := range observed {
+		fresh[k] = Quote{Price: median(values), At: now}
+		quotes[k] = fresh[k]
+	}
+	o.writeCache(ctx, fresh)
+
+	if len(failures) < len(o.sources) {
+		return quotes, nil
+	}
+
+	var unanswered []PriceKey
+	for _, k := range missing {
+		if _, ok := quotes[k]; !ok {
+			unanswered = append(unanswered, k)
+		}
+	}
+	for k, q := range o.readCache(ctx, unanswered, lastKnownCacheKey) {
+		quotes[k] = q
+	}
+	return quotes, fmt.Errorf("%w: %s", ErrPricesUnavailable, strings.Join(failures, "; "))
+}
+
+// GetHistoricalPrices returns USD prices at past hours, reading through the Redis cache.
+// Sources are asked in order for the keys the previous ones did not know; unknown keys are omitted.
+func (o *Oracle) GetHistoricalPrices(ctx context.Context, keys []HistoricalKey) (map[HistoricalKey]float64, error) {
+	prices := o.readHistoricalCache(ctx, keys)
+
+	var missing []HistoricalKey
+	seen := make(map[HistoricalKey]bool)
+	for _, k := range keys {
+		if _, ok := prices[k]; !ok && !seen[k] {
+			seen[k] = true
+			missing = append(missing, k)
+		}
+	}
+
+	fresh := make(map[HistoricalKey]float64)
+	for _, src := range o.historical {
+		if len(missing) == 0 {
+			break
+		}
+		var unknown []HistoricalKey
+		for start := 0; start < len(missing); start += priceBatchSize {
+			end := start + priceBatchSize
+			if end > len(missing) {
+				end = len(missing)
+			}
+			res, err := src.GetHistoricalPrices(ctx, missing[start:end])
+			if err != nil {
+				o.logger.Warnf("Historical price source %s failed: %v", src.Name(), err)
+				res = nil
+			}
+			for _, k := range missing[start:end] {
+				if price, ok := res[k]; ok && price > 0 {
+					fresh[k] = price
+					prices[k] = price
+				} else {
+					unknown = append(unknown, k)
+				}
+			}
+		}
+		missing = unknown
+	}
+	o.writeHistoricalCache(ctx, fresh)
+
+	return prices, nil
+}
+
+func (o *Oracle) readHistoricalCache(ctx context.Context, keys []HistoricalKey) map[HistoricalKey]float64 {
+	prices := make(map[HistoricalKey]float64, len(keys))
+	if o.redisClient == nil || len(keys) == 0 {
+		return prices
+	}
+
+	redisKeys := make([]string, len(keys))
+	for i, k := range keys {
+		redisKeys[i] = historicalCacheKey(k)
+	}
+	values, err := o.redisClient.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		o.logger.Warnf("Price cache read failed: %v", err)
+		return prices
+	}
+
+	for i, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if price, err := strconv.ParseFloat(raw, 64); err == nil {
+			prices[keys[i]] = price
+		}
+	}
+	return prices
+}
+
+func (o *Oracle) writeHistoricalCache(ctx context.Context, prices map[HistoricalKey]float64) {
+	if o.redisClient == nil || len(prices) == 0 {
+		return
+	}
+	pipe := o.redisClient.Pipeline()
+	for k, price := range prices {
+		pipe.Set(ctx, historicalCacheKey(k), strconv.FormatFloat(price, 'g', -1, 64), historicalPriceCacheTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		o.logger.Warnf("Price cache write failed: %v", err)
+	}
+}
+
+func (o *Oracle) readCache(ctx context.Context, keys []PriceKey, keyFn func(PriceKey) string) map[PriceKey]Quote {
+	quotes := make(map[PriceKey]Quote, len(keys))
+	if o.redisClient == nil || len(keys) == 0 {
+		return quotes
+	}
+
+	redisKeys := make([]string, len(keys))
+	for i, k := range keys {
+		redisKeys[i] = keyFn(k)
+	}
+	values, err := o.redisClient.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		o.logger.Warnf("Price cache read failed: %v", err)
+		return quotes
+	}
+
+	for i, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var q Quote
+		if err := json.Unmarshal([]byte(raw), &q); err == nil {
+			quotes[keys[i]] = q
+		}
+	}
+	return quotes
+}
+
+func (o *Oracle) writeCache(ctx context.Context, quotes map[PriceKey]Quote) {
+	if o.redisClient == nil || len(quotes) == 0 {
+		return
+	}
+	pipe := o.redisClient.Pipeline()
+	for k, q := range quotes {
+		data, err := json.Marshal(q)
+		if err != nil {
+			continue
+		}
+		pipe.Set(ctx, cacheKey(k), data, o.ttl)
+		pipe.Set(ctx, lastKnownCacheKey(k), data, lastKnownPriceTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		o.logger.Warnf("Price cache write failed: %v", err)
+	}
+}
+
+func cacheKey(k PriceKey) string {
+	return "price:" + k.String()
+}
+
+// lastKnownCacheKey outlives cacheKey so balances keep a price through a source outage
+func lastKnownCacheKey(k PriceKey) string {
+	return "price:last:" + k.String()
+}
+
+func historicalCacheKey(k HistoricalKey) string {
+	return fmt.Sprintf("price:%d:%s", k.Hour, k.PriceKey.String())
+}
+
+// keyFor picks the price key of an asset, preferring its CoinGecko ID
+func keyFor(blockchain string, asset entities.Asset) (PriceKey, bool) {
+	if asset.CoingeckoID != "" {
+		return PriceKey{CoingeckoID: asset.CoingeckoID}, true
+	}
+	if asset.Address != "" {
+		return PriceKey{Blockchain: blockchain, Contract: normalizeContract(blockchain, asset.Address)}, true
+	}
+	// Native assets from providers that do not tag them are priced through the chain registry
+	if chain, ok := chains.Get().Chain(blockchain); ok && chain.NativeAsset.CoingeckoID != "" {
+		return PriceKey{CoingeckoID: chain.NativeAsset.CoingeckoID}, true
+	}
+	return PriceKey{}, false
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
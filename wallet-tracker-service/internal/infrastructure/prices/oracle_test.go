@@ -0,0 +1,108 @@
+package prices
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+type fakeSource struct {
+	name   string
+	prices map[PriceKey]float64
+	err    error
+}
+
+func (s fakeSource) Name() string { return s.name }
+
+func (s fakeSource) GetPrices(ctx context.Context, keys []PriceKey) (map[PriceKey]float64, error) {
+	return s.prices, s.err
+}
+
+func testWallet() entities.Wallet {
+	pricedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	held := entities.NewBalance(entities.Asset{Symbol: "USDC", CoingeckoID: "usd-coin", Decimals: 6}, entities.NewRawAmount(big.NewInt(5000000)))
+	held.Asset.USDPrice = entities.NewUSDFromFloat(1)
+	held.USDValue = entities.NewUSDFromFloat(5)
+	held.PricedAt = &pricedAt
+	return entities.Wallet{
+		Blockchain: "ETH",
+		Balances: []entities.Balance{
+			entities.NewBalance(entities.Asset{Symbol: "ETH", CoingeckoID: "ethereum", Decimals: 18}, entities.NewRawAmount(big.NewInt(2e18))),
+			held,
+		},
+	}
+}
+
+func TestOraclePriceWallets(t *testing.T) {
+	eth, usdc := PriceKey{CoingeckoID: "ethereum"}, PriceKey{CoingeckoID: "usd-coin"}
+	down := errors.New("HTTP 429")
+
+	tests := []struct {
+		name         string
+		sources      []IPriceSource
+		wantErr      bool
+		wantBalance  string
+		wantUnpriced bool
+	}{
+		{
+			name:        "every source answers",
+			sources:     []IPriceSource{fakeSource{name: "a", prices: map[PriceKey]float64{eth: 2000, usdc: 1}}},
+			wantBalance: "4005",
+		},
+		{
+			name: "one source fails",
+			sources: []IPriceSource{
+				fakeSource{name: "a", err: down},
+				fakeSource{name: "b", prices: map[PriceKey]float64{eth: 2000, usdc: 1}},
+			},
+			wantBalance: "4005",
+		},
+		{
+			// USDC keeps its earlier price and stays in the total; ETH has none to keep
+			name:         "every source fails",
+			sources:      []IPriceSource{fakeSource{name: "a", err: down}, fakeSource{name: "b", err: down}},
+			wantErr:      true,
+			wantBalance:  "5",
+			wantUnpriced: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOracle(tt.sources, nil, 0, logs.NewLogger())
+			wallets := []entities.Wallet{testWallet()}
+
+			err := o.PriceWallets(context.Background(), wallets)
+			if tt.wantErr != (err != nil) || (err != nil && !errors.Is(err, ErrPricesUnavailable)) {
+				t.Fatalf("PriceWallets error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := wallets[0].Balance.String(); got != tt.wantBalance {
+				t.Errorf("wallet balance = %s, want %s", got, tt.wantBalance)
+			}
+			if got := wallets[0].Balances[0].Unpriced; got != tt.wantUnpriced {
+				t.Errorf("ETH unpriced = %v, want %v", got, tt.wantUnpriced)
+			}
+			if wallets[0].Balances[1].Unpriced {
+				t.Error("USDC with an earlier price was marked unpriced")
+			}
+		})
+	}
+}
+
+func TestOracleGetQuotesErrorsOnlyWhenEverySourceFails(t *testing.T) {
+	eth := PriceKey{CoingeckoID: "ethereum"}
+	o := NewOracle([]IPriceSource{
+		fakeSource{name: "a", err: errors.New("timeout")},
+		fakeSource{name: "b", prices: map[PriceKey]float64{}},
+	}, nil, 0, logs.NewLogger())
+
+	// A source that answers without the key is not a failure
+	quotes, err := o.GetQuotes(context.Background(), []PriceKey{eth})
+	if err != nil || len(quotes) != 0 {
+		t.Errorf("GetQuotes = %v, %v; want no quotes and no error", quotes, err)
+	}
+}
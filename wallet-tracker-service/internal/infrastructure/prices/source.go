@@ -0,0 +1,60 @@
+package prices
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+// PriceKey identifies an asset either by CoinGecko ID or by chain and contract address
+type PriceKey struct {
+	CoingeckoID string
+	Blockchain  string
+	Contract    string
+}
+
+// String returns the canonical form used for cache keys
+func (k PriceKey) String() string {
+	if k.CoingeckoID != "" {
+		return "cg:" + k.CoingeckoID
+	}
+	return fmt.Sprintf("%s:%s", k.Blockchain, normalizeContract(k.Blockchain, k.Contract))
+}
+
+// IPriceSource returns USD prices for a batch of assets; unknown assets are omitted from the result
+type IPriceSource interface {
+	Name() string
+	GetPrices(ctx context.Context, keys []PriceKey) (map[PriceKey]float64, error)
+}
+
+// EVM contracts are case-insensitive, base58 ones (Solana, Tron) are not
+func normalizeContract(blockchain, contract string) string {
+	if strings.HasPrefix(contract, "0x") || strings.HasPrefix(contract, "0X") {
+		return strings.ToLower(contract)
+	}
+	return contract
+}
+
+// HistoricalKey is an asset at a past hour, the resolution historical prices are kept at
+type HistoricalKey struct {
+	PriceKey
+	Hour int64 // unix seconds, truncated to the hour
+}
+
+// NewHistoricalKey returns the key pricing an asset at the given time
+func NewHistoricalKey(blockchain string, asset entities.Asset, at time.Time) (HistoricalKey, bool) {
+	k, ok := keyFor(blockchain, asset)
+	if !ok {
+		return HistoricalKey{}, false
+	}
+	return HistoricalKey{PriceKey: k, Hour: at.Truncate(time.Hour).Unix()}, true
+}
+
+// IHistoricalPriceSource returns USD prices at past times; unknown keys are omitted from the result
+type IHistoricalPriceSource interface {
+	Name() string
+	GetHistoricalPrices(ctx context.Context, keys []HistoricalKey) (map[HistoricalKey]float64, error)
+}
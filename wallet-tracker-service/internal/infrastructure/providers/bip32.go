@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/secp256k1"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// btcScriptType is the address type implied by an extended key prefix
+type btcScriptType int
+
+const (
+	scriptP2PKH      btcScriptType = iota // xpub: legacy 1... addresses
+	scriptP2SHP2WPKH                      // ypub: nested segwit 3... addresses
+	scriptP2WPKH                          // zpub: native segwit bc1q... addresses
+)
+
+// extendedKeyVersions maps the BIP-32/49/84 mainnet public key versions to their script type
+var extendedKeyVersions = map[string]btcScriptType{
+	"0488b21e": scriptP2PKH,
+	"049d7cb2": scriptP2SHP2WPKH,
+	"04b24746": scriptP2WPKH,
+}
+
+// extendedPublicKey is a parsed xpub/ypub/zpub
+type extendedPublicKey struct {
+	scriptType btcScriptType
+	chainCode  []byte
+	pubKey     []byte // 33-byte compressed point
+}
+
+// isExtendedPublicKey reports whether s looks like an xpub, ypub or zpub
+func isExtendedPublicKey(s string) bool {
+	if len(s) < 4 {
+		return false
+	}
+	switch s[:4] {
+	case "xpub", "ypub", "zpub":
+		return true
+	}
+	return false
+}
+
+func parseExtendedPublicKey(s string) (*extendedPublicKey, error) {
+	payload, err := addresses.Base58CheckDecode(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended public key: %w", err)
+	}
+	if len(payload) != 78 {
+		return nil, fmt.Errorf("invalid extended public key length")
+	}
+
+	scriptType, ok := extendedKeyVersions[hex.EncodeToString(payload[:4])]
+	if !ok {
+		return nil, fmt.Errorf("unsupported extended public key version")
+	}
+	pubKey := payload[45:78]
+	if pubKey[0] != 0x02 && pubKey[0] != 0x03 {
+		return nil, fmt.Errorf("extended key does not hold a public key")
+	}
+	if _, _, err := secp256k1.DecompressPoint(pubKey); err != nil {
+		return nil, err
+	}
+
+	return &extendedPublicKey{
+		scriptType: scriptType,
+		chainCode:  payload[13:45],
+		pubKey:     pubKey,
+	}, nil
+}
+
+// child derives the non-hardened child at index (BIP-32 CKDpub)
+func (k *extendedPublicKey) child(index uint32) (*extendedPublicKey, error) {
+	if index >= 0x80000000 {
+		return nil, fmt.Errorf("cannot derive hardened child from a public key")
+	}
+
+	data := make([]byte, 37)
+	copy(data, k.pubKey)
+	binary.BigEndian.PutUint32(data[33:], index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(secp256k1.N) >= 0 {
+		return nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+
+	px, py, err := secp256k1.DecompressPoint(k.pubKey)
+	if err != nil {
+		return nil, err
+	}
+	tx, ty := secp256k1.ScalarBaseMult(il)
+	cx, cy := secp256k1.Add(tx, ty, px, py)
+	if cx == nil {
+		return nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+
+	return &extendedPublicKey{
+		scriptType: k.scriptType,
+		chainCode:  sum[32:],
+		pubKey:     secp256k1.CompressPoint(cx, cy),
+	}, nil
+}
+
+// address encodes the key as a mainnet address of its script type
+func (k *extendedPublicKey) address() (string, error) {
+	keyHash := hash160(k.pubKey)
+	switch k.scriptType {
+	case scriptP2PKH:
+		return addresses.Base58CheckEncode(append([]byte{0x00}, keyHash...)), nil
+	case scriptP2SHP2WPKH:
+		redeemScript := append([]byte{0x00, 0x14}, keyHash...)
+		return addresses.Base58CheckEncode(append([]byte{0x05}, hash160(redeemScript)...)), nil
+	case scriptP2WPKH:
+		return addresses.EncodeSegwitAddress("bc", 0, keyHash)
+	default:
+		return "", fmt.Errorf("unsupported script type")
+	}
+}
+
+func hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"bytes"
+	"testing"
+)
+
+// BIP-32 test vector 1, public derivation from the hardened nodes
+func TestExtendedPublicKeyChild(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent string
+		index  uint32
+		child  string
+	}{
+		{
+			name:   "m/0H/1",
+			parent: "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+			index:  1,
+			child:  "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+		},
+		{
+			name:   "m/0H/1/2H/2",
+			parent: "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+			index:  2,
+			child:  "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+		},
+		{
+			name:   "m/0H/1/2H/2/1000000000",
+			parent: "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+			index:  1000000000,
+			child:  "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, err := parseExtendedPublicKey(tt.parent)
+			if err != nil {
+				t.Fatalf("parse parent: %v", err)
+			}
+			want, err := parseExtendedPublicKey(tt.child)
+			if err != nil {
+				t.Fatalf("parse child: %v", err)
+			}
+			got, err := parent.child(tt.index)
+			if err != nil {
+				t.Fatalf("child(%d): %v", tt.index, err)
+			}
+			if !bytes.Equal(got.pubKey, want.pubKey) {
+				t.Errorf("public key = %x, want %x", got.pubKey, want.pubKey)
+			}
+			if !bytes.Equal(got.chainCode, want.chainCode) {
+				t.Errorf("chain code = %x, want %x", got.chainCode, want.chainCode)
+			}
+		})
+	}
+}
+
+func TestExtendedPublicKeyHardenedChild(t *testing.T) {
+	key, err := parseExtendedPublicKey("xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := key.child(0x80000000); err == nil {
+		t.Error("deriving a hardened child from a public key should fail")
+	}
+}
+
+// Account keys of the "abandon ... about" mnemonic from BIP-44, BIP-49 and BIP-84
+func TestExtendedPublicKeyAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		account string
+		chain   uint32
+		index   uint32
+		address string
+	}{
+		{
+			name:    "BIP-44 m/44H/0H/0H/0/0",
+			account: "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj",
+			address: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA",
+		},
+		{
+			name:    "BIP-49 m/49H/0H/0H/0/0",
+			account: "ypub6Ww3ibxVfGzLrAH1PNcjyAWenMTbbAosGNB6VvmSEgytSER9azLDWCxoJwW7Ke7icmizBMXrzBx9979FfaHxHcrArf3zbeJJJUZPf663zsP",
+			address: "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf",
+		},
+		{
+			name:    "BIP-84 m/84H/0H/0H/0/0",
+			account: "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs",
+			address: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		},
+		{
+			name:    "BIP-84 m/84H/0H/0H/0/1",
+			account: "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs",
+			index:   1,
+			address: "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g",
+		},
+		{
+			name:    "BIP-84 m/84H/0H/0H/1/0",
+			account: "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs",
+			chain:   1,
+			address: "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !isExtendedPublicKey(tt.account) {
+				t.Fatalf("%s not recognized as an extended public key", tt.account[:4])
+			}
+			account, err := parseExtendedPublicKey(tt.account)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			chainKey, err := account.child(tt.chain)
+			if err != nil {
+				t.Fatalf("child(%d): %v", tt.chain, err)
+			}
+			key, err := chainKey.child(tt.index)
+			if err != nil {
+				t.Fatalf("child(%d): %v", tt.index, err)
+			}
+			got, err := key.address()
+			if err != nil {
+				t.Fatalf("address: %v", err)
+			}
+			if got != tt.address {
+				t.Errorf("address = %s, want %s", got, tt.address)
+			}
+		})
+	}
+}
+
+func TestParseExtendedPublicKeyInvalid(t *testing.T) {
+	tests := map[string]string{
+		"bad checksum":     "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet9",
+		"private key":      "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+		"not base58":       "xpub0000",
+		"too short":        "xpub661MyMwAqRbc",
+		"not a key at all": "",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseExtendedPublicKey(input); err == nil {
+				t.Errorf("parseExtendedPublicKey(%q) should fail", input)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+const (
+	defaultEsploraURL  = "https://blockstream.info/api"
+	defaultBTCGapLimit = 20
+
+	// maxDerivedAddresses bounds the scan of a single derivation chain
+	maxDerivedAddresses = 10000
+
+	// esploraConcurrency bounds the Esplora requests one lookup has in flight
+	esploraConcurrency = 8
+)
+
+// IUTXOProvider is implemented by providers of UTXO-based chains
+type IUTXOProvider interface {
+	GetUTXOs(ctx context.Context, blockchain, address string) (map[string][]entities.UTXO, error)
+}
+
+// BitcoinProvider reads BTC balances from an Esplora-compatible REST API.
+// The address may be a single address or an xpub/ypub/zpub, in which case
+// receive and change addresses are derived until gapLimit unused ones in a row.
+type BitcoinProvider struct {
+	chain      entities.Chain
+	baseURL    string
+	gapLimit   int
+	httpClient *http.Client
+	logger     *logs.Logger
+}
+
+type esploraStats struct {
+	TxCount int `json:"tx_count"`
+}
+
+type esploraAddress struct {
+	ChainStats   esploraStats `json:"chain_stats"`
+	MempoolStats esploraStats `json:"mempool_stats"`
+}
+
+type esploraUTXO struct {
+	TxID   string `json:"txid"`
+	Vout   uint32 `json:"vout"`
+	Value  uint64 `json:"value"`
+	Status struct {
+		Confirmed   bool  `json:"confirmed"`
+		BlockHeight int64 `json:"block_height"`
+	} `json:"status"`
+}
+
+func NewBitcoinProvider(chain entities.Chain, baseURL string, gapLimit int, logger *logs.Logger) *BitcoinProvider {
+	if baseURL == "" {
+		baseURL = defaultEsploraURL
+	}
+	if gapLimit <= 0 {
+		gapLimit = defaultBTCGapLimit
+	}
+	return &BitcoinProvider{
+		chain:      chain,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		gapLimit:   gapLimit,
+		httpClient: &http.Client{Timeout: 20 * time.Second},
+		logger:     logger,
+	}
+}
+
+func (p *BitcoinProvider) Name() string {
+	return "btc"
+}
+
+// GetWalletBalance sums the UTXOs of the address, or of every used address derived from an extended key
+func (p *BitcoinProvider) GetWalletBalance(ctx context.Context, blockchain, address string) ([]entities.Wallet, error) {
+	utxoSet, err := p.GetUTXOs(ctx, blockchain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	var total uint64
+	for _, utxos := range utxoSet {
+		for _, u := range utxos {
+			total += u.Value
+		}
+	}
+	raw := entities.NewRawAmount(new(big.Int).SetUint64(total))
+
+	return []entities.Wallet{{
+		Blockchain: blockchain,
+		Address:    address,
+		Balances:   []entities.Balance{entities.NewBalance(p.chain.NativeAsset.Asset(), raw)},
+	}}, nil
+}
+
+// GetUTXOs returns the unspent outputs keyed by address
+func (p *BitcoinProvider) GetUTXOs(ctx context.Context, blockchain, address string) (map[string][]entities.UTXO, error) {
+	if blockchain != p.chain.Key {
+		return nil, fmt.Errorf("blockchain '%s' is not supported by the btc provider", blockchain)
+	}
+
+	addrs, err := p.resolveAddresses(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	utxoSets := make([][]entities.UTXO, len(addrs))
+	err = forEachConcurrently(ctx, len(addrs), func(ctx context.Context, i int) error {
+		var raw []esploraUTXO
+		if err := p.get(ctx, fmt.Sprintf("/address/%s/utxo", url.PathEscape(addrs[i])), &raw); err != nil {
+			return err
+		}
+		utxos := make([]entities.UTXO, 0, len(raw))
+		for _, u := range raw {
+			utxos = append(utxos, entities.UTXO{
+				TxID:        u.TxID,
+				Vout:        u.Vout,
+				Value:       u.Value,
+				Confirmed:   u.Status.Confirmed,
+				BlockHeight: u.Status.BlockHeight,
+			})
+		}
+		utxoSets[i] = utxos
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utxoSet := make(map[string][]entities.UTXO, len(addrs))
+	for i, addr := range addrs {
+		utxoSet[addr] = utxoSets[i]
+	}
+
+	return utxoSet, nil
+}
+
+// resolveAddresses returns the address itself, or the used addresses derived from an extended key
+func (p *BitcoinProvider) resolveAddresses(ctx context.Context, input string) ([]string, error) {
+	if !isExtendedPublicKey(input) {
+		return []string{input}, nil
+	}
+
+	account, err := parseExtendedPublicKey(input)
+	if err != nil {
+		return nil, err
+	}
+	p.logger.Infof("Deriving BTC addresses with gap limit %d", p.gapLimit)
+
+	var used []string
+	// Chain 0 holds receive addresses, chain 1 change addresses
+	for _, chainIndex := range []uint32{0, 1} {
+		chainKey, err := account.child(chainIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		// Addresses are checked a gap window at a time, concurrently; the scan stops at the
+		// first gapLimit unused addresses in a row
+		gap := 0
+		for start := uint32(0); gap < p.gapLimit && start < maxDerivedAddresses; start += uint32(p.gapLimit) {
+			window := make([]string, 0, p.gapLimit)
+			for i := start; i < start+uint32(p.gapLimit) && i < maxDerivedAddresses; i++ {
+				childKey, err := chainKey.child(i)
+				if err != nil {
+					return nil, err
+				}
+				addr, err := childKey.address()
+				if err != nil {
+					return nil, err
+				}
+				window = append(window, addr)
+			}
+
+			txCounts := make([]int, len(window))
+			err := forEachConcurrently(ctx, len(window), func(ctx context.Context, i int) error {
+				var info esploraAddress
+				if err := p.get(ctx, fmt.Sprintf("/address/%s", url.PathEscape(window[i])), &info); err != nil {
+					return err
+				}
+				txCounts[i] = info.ChainStats.TxCount + info.MempoolStats.TxCount
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for i, addr := range window {
+				if gap >= p.gapLimit {
+					break
+				}
+				if txCounts[i] == 0 {
+					gap++
+					continue
+				}
+				gap = 0
+				used = append(used, addr)
+			}
+		}
+	}
+
+	return used, nil
+}
+
+// forEachConcurrently calls fn for 0 to n-1 with at most esploraConcurrency calls in flight.
+// It returns the first error, after which the remaining calls see a cancelled context.
+func forEachConcurrently(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, esploraConcurrency)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func (p *BitcoinProvider) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create esplora request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed calling esplora API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("esplora API error: %s", string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal esplora response: %w", err)
+	}
+	return nil
+}
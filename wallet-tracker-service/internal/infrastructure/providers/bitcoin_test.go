@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+// The BIP-84 account key of the "abandon ... about" mnemonic
+const testZpub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+
+func deriveTestAddress(t *testing.T, chain, index uint32) string {
+	t.Helper()
+	account, err := parseExtendedPublicKey(testZpub)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	chainKey, err := account.child(chain)
+	if err != nil {
+		t.Fatalf("child(%d): %v", chain, err)
+	}
+	key, err := chainKey.child(index)
+	if err != nil {
+		t.Fatalf("child(%d): %v", index, err)
+	}
+	addr, err := key.address()
+	if err != nil {
+		t.Fatalf("address: %v", err)
+	}
+	return addr
+}
+
+func TestBitcoinGetWalletBalanceScansGapWindows(t *testing.T) {
+	const gapLimit = 5
+	// Receive address 8 sits 4 unused addresses after 3 and is found; 14 sits 5 after 8 and is not
+	used := map[string]bool{
+		deriveTestAddress(t, 0, 0):  true,
+		deriveTestAddress(t, 0, 3):  true,
+		deriveTestAddress(t, 0, 8):  true,
+		deriveTestAddress(t, 0, 14): true,
+		deriveTestAddress(t, 1, 1):  true,
+	}
+
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	utxoQueries := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		path := strings.TrimPrefix(r.URL.Path, "/address/")
+		if addr := strings.TrimSuffix(path, "/utxo"); addr != path {
+			mu.Lock()
+			utxoQueries[addr] = true
+			mu.Unlock()
+			utxo := esploraUTXO{TxID: "ab", Value: 1000}
+			utxo.Status.Confirmed = true
+			_ = json.NewEncoder(w).Encode([]esploraUTXO{utxo})
+			return
+		}
+		info := esploraAddress{}
+		if used[path] {
+			info.ChainStats.TxCount = 1
+		}
+		_ = json.NewEncoder(w).Encode(info)
+	}))
+	defer server.Close()
+
+	chain := entities.Chain{Key: "BTC", NativeAsset: entities.ChainAsset{Symbol: "BTC", Name: "Bitcoin", Decimals: 8}}
+	p := NewBitcoinProvider(chain, server.URL, gapLimit, logs.NewLogger())
+
+	wallets, err := p.GetWalletBalance(context.Background(), "BTC", testZpub)
+	if err != nil {
+		t.Fatalf("GetWalletBalance: %v", err)
+	}
+	if got := wallets[0].Balances[0].Amount.String(); got != "4000" {
+		t.Errorf("balance = %s, want 4000 from four used addresses", got)
+	}
+	for _, addr := range []string{deriveTestAddress(t, 0, 0), deriveTestAddress(t, 0, 3), deriveTestAddress(t, 0, 8), deriveTestAddress(t, 1, 1)} {
+		if !utxoQueries[addr] {
+			t.Errorf("UTXOs of used address %s were not read", addr)
+		}
+	}
+	if utxoQueries[deriveTestAddress(t, 0, 14)] {
+		t.Error("an address past the gap limit was counted")
+	}
+	if maxInFlight < 2 || maxInFlight > esploraConcurrency {
+		t.Errorf("%d requests in flight at most, want 2 to %d", maxInFlight, esploraConcurrency)
+	}
+}
+
+func TestForEachConcurrentlyStopsAtFirstError(t *testing.T) {
+	var started int32
+	err := forEachConcurrently(context.Background(), 100, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&started, 1)
+		if i == 0 {
+			return context.DeadlineExceeded
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want the first call's error", err)
+	}
+	if started > esploraConcurrency {
+		t.Errorf("%d calls started after the first error, want at most %d", started, esploraConcurrency)
+	}
+}
@@ -0,0 +1,257 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+// esploraMaxTxPages bounds how far back one sync walks per address (25 transactions per page)
+const esploraMaxTxPages = 40
+
+type esploraTx struct {
+	TxID string `json:"txid"`
+	Vin  []struct {
+		Prevout *struct {
+			Address string `json:"scriptpubkey_address"`
+			Value   uint64 `json:"value"`
+		} `json:"prevout"`
+	} `json:"vin"`
+	Vout []struct {
+		Address string `json:"scriptpubkey_address"`
+		Value   uint64 `json:"value"`
+	} `json:"vout"`
+	Fee    uint64 `json:"fee"`
+	Status struct {
+		Confirmed   bool  `json:"confirmed"`
+		BlockHeight int64 `json:"block_height"`
+		BlockTime   int64 `json:"block_time"`
+	} `json:"status"`
+}
+
+// esploraCursor is how far the sync of a BTC wallet has got: every transaction at or below Height
+// is ingested. When a sync stops at the page limit, Resume holds the last transaction read of each
+// address that was cut short, where the next sync carries on, and Top the highest block the walk
+// started from. Height only moves up to Top once every address has been read down to Height.
+//
+// It is stored as "<height>" or "<height>/<top>/<address>:<txid>/...".
+type esploraCursor struct {
+	Height int64
+	Top    int64
+	Resume map[string]string
+}
+
+func parseEsploraCursor(cursor string) (esploraCursor, error) {
+	c := esploraCursor{Height: -1, Resume: make(map[string]string)}
+	if cursor == "" {
+		return c, nil
+	}
+	invalid := fmt.Errorf("invalid BTC transaction cursor '%s'", cursor)
+
+	parts := strings.Split(cursor, "/")
+	var err error
+	if c.Height, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return c, invalid
+	}
+	if len(parts) == 1 {
+		return c, nil
+	}
+	if len(parts) < 3 {
+		return c, invalid
+	}
+	if c.Top, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return c, invalid
+	}
+	for _, part := range parts[2:] {
+		addr, txid, ok := strings.Cut(part, ":")
+		if !ok {
+			return c, invalid
+		}
+		c.Resume[addr] = txid
+	}
+	return c, nil
+}
+
+func (c esploraCursor) String() string {
+	if len(c.Resume) == 0 {
+		if c.Height < 0 {
+			return ""
+		}
+		return strconv.FormatInt(c.Height, 10)
+	}
+	addrs := make([]string, 0, len(c.Resume))
+	for addr := range c.Resume {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	parts := []string{strconv.FormatInt(c.Height, 10), strconv.FormatInt(c.Top, 10)}
+	for _, addr := range addrs {
+		parts = append(parts, addr+":"+c.Resume[addr])
+	}
+	return strings.Join(parts, "/")
+}
+
+// GetTransactions ingests the confirmed transactions above the cursor block height.
+// For an extended key every used derived address is scanned, and transfers between
+// them net out, so a transaction is stored once with the wallet's net change.
+func (p *BitcoinProvider) GetTransactions(ctx context.Context, blockchain, address, cursor string) ([]entities.Transaction, string, error) {
+	if blockchain != p.chain.Key {
+		return nil, "", fmt.Errorf("blockchain '%s' is not supported by the btc provider", blockchain)
+	}
+
+	current, err := parseEsploraCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	fromHeight := current.Height + 1
+
+	addrs, err := p.resolveAddresses(ctx, address)
+	if err != nil {
+		return nil, "", err
+	}
+	own := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		own[addr] = true
+	}
+
+	// Confirmed history comes newest first, 25 per page; walk back to the cursor height,
+	// or carry on from where the previous sync stopped
+	found := make(map[string]esploraTx)
+	stopped := make(map[string]string)
+	for _, addr := range addrs {
+		base := fmt.Sprintf("/address/%s/txs/chain", url.PathEscape(addr))
+		path := base
+		if txid, ok := current.Resume[addr]; ok {
+			path = base + "/" + txid
+		}
+		reachedCursor := false
+		for page := 0; page < esploraMaxTxPages && !reachedCursor; page++ {
+			var batch []esploraTx
+			if err := p.get(ctx, path, &batch); err != nil {
+				return nil, "", err
+			}
+			for _, tx := range batch {
+				if tx.Status.BlockHeight < fromHeight {
+					reachedCursor = true
+					break
+				}
+				found[tx.TxID] = tx
+			}
+			if len(batch) < 25 {
+				reachedCursor = true
+			} else {
+				path = base + "/" + batch[len(batch)-1].TxID
+			}
+		}
+		if !reachedCursor {
+			stopped[addr] = strings.TrimPrefix(path, base+"/")
+		}
+	}
+
+	var maxHeight int64 = -1
+	txs := make([]entities.Transaction, 0, len(found))
+	for _, raw := range found {
+		if raw.Status.BlockHeight > maxHeight {
+			maxHeight = raw.Status.BlockHeight
+		}
+		txs = append(txs, p.toTransaction(address, own, raw))
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].BlockNumber < txs[j].BlockNumber })
+
+	next := esploraCursor{Height: current.Height, Top: current.Top, Resume: stopped}
+	switch {
+	case len(current.Resume) == 0 && len(stopped) == 0:
+		// Every address was read from its newest transaction down to the cursor
+		if maxHeight > next.Height {
+			next.Height = maxHeight
+		}
+	case len(current.Resume) == 0:
+		// A walk cut short covers every block up to the highest one it read
+		next.Top = maxHeight
+	case len(stopped) == 0:
+		// The resumed addresses have not been read above the top of the walk
+		next.Height = current.Top
+	}
+	if len(stopped) > 0 {
+		p.logger.Infof("BTC sync for %s stopped at the page limit on %d addresses, the next sync continues below them", address, len(stopped))
+	}
+
+	return txs, next.String(), nil
+}
+
+// toTransaction nets the transaction's inputs and outputs against the wallet's addresses
+func (p *BitcoinProvider) toTransaction(address string, own map[string]bool, raw esploraTx) entities.Transaction {
+	var spent, received uint64
+	var counterpartyIn, counterpartyOut, ownIn, ownOut string
+	for _, in := range raw.Vin {
+		if in.Prevout == nil {
+			continue
+		}
+		if own[in.Prevout.Address] {
+			spent += in.Prevout.Value
+			if ownIn == "" {
+				ownIn = in.Prevout.Address
+			}
+		} else if counterpartyIn == "" {
+			counterpartyIn = in.Prevout.Address
+		}
+	}
+	for _, out := range raw.Vout {
+		if own[out.Address] {
+			received += out.Value
+			if ownOut == "" {
+				ownOut = out.Address
+			}
+		} else if counterpartyOut == "" && out.Address != "" {
+			counterpartyOut = out.Address
+		}
+	}
+
+	tx := entities.Transaction{
+		Blockchain:  p.chain.Key,
+		Address:     address,
+		Hash:        raw.TxID,
+		LegID:       "tx",
+		BlockNumber: raw.Status.BlockHeight,
+		Timestamp:   time.Unix(raw.Status.BlockTime, 0).UTC(),
+		Asset:       p.chain.NativeAsset.Asset(),
+	}
+
+	net := new(big.Int).Sub(new(big.Int).SetUint64(received), new(big.Int).SetUint64(spent))
+	if spent == 0 {
+		tx.Direction = entities.DirectionIn
+		tx.From = counterpartyIn
+		tx.To = ownOut
+		tx.Amount = entities.NewRawAmount(net)
+		return tx
+	}
+
+	// The wallet funded the transaction, so it paid the fee; what left beyond it was sent away
+	fee := new(big.Int).SetUint64(raw.Fee)
+	tx.Fee = entities.NewRawAmount(fee)
+	tx.From = ownIn
+	sent := new(big.Int).Sub(new(big.Int).Neg(net), fee)
+	switch sent.Sign() {
+	case 1:
+		tx.Direction = entities.DirectionOut
+		tx.To = counterpartyOut
+		tx.Amount = entities.NewRawAmount(sent)
+	case 0:
+		tx.Direction = entities.DirectionSelf
+		tx.To = ownOut
+	default:
+		// More came back than the wallet put in, e.g. a coinjoin
+		tx.Direction = entities.DirectionIn
+		tx.To = ownOut
+		tx.Amount = entities.NewRawAmount(new(big.Int).Neg(sent))
+	}
+	return tx
+}
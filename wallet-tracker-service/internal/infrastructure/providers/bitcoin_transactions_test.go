@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+const testBTCAddress = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+
+// newEsploraStub serves the confirmed history of testBTCAddress, given oldest first, 25 per page
+func newEsploraStub(t *testing.T, history *[]esploraTx) *httptest.Server {
+	t.Helper()
+	base := "/address/" + testBTCAddress + "/txs/chain"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, base) {
+			http.NotFound(w, r)
+			return
+		}
+		txs := *history
+		start := len(txs)
+		if lastSeen := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, base), "/"); lastSeen != "" {
+			for i, tx := range txs {
+				if tx.TxID == lastSeen {
+					start = i
+				}
+			}
+		}
+		page := []esploraTx{}
+		for i := start - 1; i >= 0 && len(page) < 25; i-- {
+			page = append(page, txs[i])
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testEsploraTx(height int64) esploraTx {
+	tx := esploraTx{TxID: fmt.Sprintf("%064d", height), Fee: 100}
+	tx.Status.Confirmed = true
+	tx.Status.BlockHeight = height
+	tx.Status.BlockTime = 1700000000 + height
+	return tx
+}
+
+func TestBitcoinGetTransactionsResumesPastPageLimit(t *testing.T) {
+	// One transaction per block from height 100; the cursor has ingested block 100
+	history := make([]esploraTx, esploraMaxTxPages*25+30)
+	for i := range history {
+		history[i] = testEsploraTx(int64(100 + i))
+	}
+	server := newEsploraStub(t, &history)
+	chain := entities.Chain{Key: "BTC", NativeAsset: entities.ChainAsset{Symbol: "BTC", Name: "Bitcoin", Decimals: 8}}
+	p := NewBitcoinProvider(chain, server.URL, 20, logs.NewLogger())
+
+	seen := make(map[string]bool)
+	sync := func(cursor string) string {
+		t.Helper()
+		txs, next, err := p.GetTransactions(context.Background(), "BTC", testBTCAddress, cursor)
+		if err != nil {
+			t.Fatalf("GetTransactions(%s): %v", cursor, err)
+		}
+		for _, tx := range txs {
+			seen[tx.Hash] = true
+		}
+		return next
+	}
+
+	cursor := sync("100")
+	if !strings.HasPrefix(cursor, "100/") {
+		t.Fatalf("cursor after a capped sync = %s, want it to stay at height 100", cursor)
+	}
+
+	// A block mined while the walk is cut short must not be skipped either
+	history = append(history, testEsploraTx(int64(100+len(history))))
+
+	for syncs := 0; ; syncs++ {
+		if syncs > 10 {
+			t.Fatalf("cursor %s still moving after %d syncs", cursor, syncs)
+		}
+		next := sync(cursor)
+		if next == cursor {
+			break
+		}
+		cursor = next
+	}
+
+	top := history[len(history)-1].Status.BlockHeight
+	if cursor != strconv.FormatInt(top, 10) {
+		t.Errorf("cursor = %s, want %d", cursor, top)
+	}
+	for _, tx := range history[1:] {
+		if !seen[tx.TxID] {
+			t.Errorf("transaction at height %d was never ingested", tx.Status.BlockHeight)
+		}
+	}
+	if seen[history[0].TxID] {
+		t.Error("transaction at the cursor height was ingested again")
+	}
+}
+
+func TestEsploraCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		cursor  string
+		wantErr bool
+	}{
+		{"", false},
+		{"812345", false},
+		{"812345/812400/bc1qa:ab12/bc1qb:cd34", false},
+		{"-1/812400/bc1qa:ab12", false},
+		{"abc", true},
+		{"812345/812400", true},
+		{"812345/812400/bc1qa", true},
+	}
+	for _, tt := range tests {
+		c, err := parseEsploraCursor(tt.cursor)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseEsploraCursor(%q) should fail", tt.cursor)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseEsploraCursor(%q): %v", tt.cursor, err)
+			continue
+		}
+		if got := c.String(); got != tt.cursor {
+			t.Errorf("round trip of %q gave %q", tt.cursor, got)
+		}
+	}
+}
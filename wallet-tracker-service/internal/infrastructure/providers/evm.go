@@ -0,0 +1,278 @@
+package providers
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+const (
+	// Multicall3 is deployed at the same address on every supported EVM chain
+	multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+	aggregate3Selector = "82ad56cb" // aggregate3((address,bool,bytes)[])
+	balanceOfSelector  = "70a08231" // balanceOf(address)
+
+	multicallBatchSize = 200
+)
+
+// EVMProvider reads native and ERC-20 balances directly from JSON-RPC endpoints
+type EVMProvider struct {
+	rpcClients   map[string]*jsonRPCClient
+	nativeAssets map[string]entities.Asset
+	tokens       map[string][]TokenInfo
+	logger       *logs.Logger
+}
+
+// NewEVMProvider serves every registry chain with an EVM chain ID; chains without an RPC URL are rejected at lookup
+func NewEVMProvider(registry *chains.Registry, tokens map[string][]TokenInfo, logger *logs.Logger) *EVMProvider {
+	httpClient := &http.Client{Timeout: 20 * time.Second}
+	clients := make(map[string]*jsonRPCClient)
+	nativeAssets := make(map[string]entities.Asset)
+	for _, chain := range registry.All() {
+		if chain.EVMChainID == 0 {
+			continue
+		}
+		nativeAssets[chain.Key] = chain.NativeAsset.Asset()
+		if url := registry.RPCURL(chain.Key); url != "" {
+			clients[chain.Key] = newJSONRPCClient(url, httpClient)
+		}
+	}
+	if tokens == nil {
+		tokens = map[string][]TokenInfo{}
+	}
+	return &EVMProvider{
+		rpcClients:   clients,
+		nativeAssets: nativeAssets,
+		tokens:       tokens,
+		logger:       logger,
+	}
+}
+
+func (p *EVMProvider) Name() string {
+	return "evm"
+}
+
+// GetWalletBalance reads the native balance with eth_getBalance and token balances through Multicall3
+func (p *EVMProvider) GetWalletBalance(ctx context.Context, blockchain, address string) ([]entities.Wallet, error) {
+	native, ok := p.nativeAssets[blockchain]
+	if !ok {
+		return nil, fmt.Errorf("blockchain '%s' is not an EVM chain", blockchain)
+	}
+	client, ok := p.rpcClients[blockchain]
+	if !ok {
+		return nil, fmt.Errorf("no RPC URL configured for %s", blockchain)
+	}
+	p.logger.Infof("EVM balance lookup for %s.%s", blockchain, address)
+
+	var nativeHex string
+	if err := client.Call(ctx, "eth_getBalance", []interface{}{address, "latest"}, &nativeHex); err != nil {
+		return nil, err
+	}
+	nativeAmount, err := parseHexBig(nativeHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid eth_getBalance result: %w", err)
+	}
+
+	wallet := entities.Wallet{
+		Blockchain: blockchain,
+		Address:    address,
+		Balances:   []entities.Balance{entities.NewBalance(native, entities.NewRawAmount(nativeAmount))},
+	}
+
+	tokens := p.tokens[blockchain]
+	for start := 0; start < len(tokens); start += multicallBatchSize {
+		end := start + multicallBatchSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		batch := tokens[start:end]
+
+		amounts, err := p.tokenBalances(ctx, client, address, batch)
+		if err != nil {
+			return nil, err
+		}
+		for i, amount := range amounts {
+			if amount == nil || amount.Sign() == 0 {
+				continue
+			}
+			token := batch[i]
+			asset := entities.Asset{
+				Symbol:      token.Symbol,
+				Name:        token.Name,
+				Address:     token.Address,
+				Decimals:    token.Decimals,
+				LogoURI:     token.LogoURI,
+				CoingeckoID: token.CoingeckoID,
+			}
+			wallet.Balances = append(wallet.Balances, entities.NewBalance(asset, entities.NewRawAmount(amount)))
+		}
+	}
+
+	return []entities.Wallet{wallet}, nil
+}
+
+// tokenBalances batches balanceOf calls for the given tokens into a single Multicall3 aggregate3 call.
+// Entries whose call failed are returned as nil.
+func (p *EVMProvider) tokenBalances(ctx context.Context, client *jsonRPCClient, owner string, tokens []TokenInfo) ([]*big.Int, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	calldata, err := encodeBalanceOfAggregate3(owner, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	call := map[string]string{
+		"to":   multicall3Address,
+		"data": "0x" + hex.EncodeToString(calldata),
+	}
+	var resultHex string
+	if err := client.Call(ctx, "eth_call", []interface{}{call, "latest"}, &resultHex); err != nil {
+		return nil, err
+	}
+
+	result, err := hex.DecodeString(strings.TrimPrefix(resultHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid aggregate3 result: %w", err)
+	}
+	return decodeAggregate3Balances(result, len(tokens))
+}
+
+// encodeBalanceOfAggregate3 ABI-encodes aggregate3 with one balanceOf(owner) call per token
+func encodeBalanceOfAggregate3(owner string, tokens []TokenInfo) ([]byte, error) {
+	ownerWord, err := addressWord(owner)
+	if err != nil {
+		return nil, err
+	}
+	innerCall := append(mustDecodeHex(balanceOfSelector), ownerWord...)
+
+	// Each (address,bool,bytes) tuple: target, allowFailure, bytes offset, bytes length, padded calldata
+	tupleSize := 4*32 + padded32(len(innerCall))
+
+	out := mustDecodeHex(aggregate3Selector)
+	out = append(out, uintWord(32)...)
+	out = append(out, uintWord(uint64(len(tokens)))...)
+	for i := range tokens {
+		out = append(out, uintWord(uint64(len(tokens)*32+i*tupleSize))...)
+	}
+	for _, token := range tokens {
+		target, err := addressWord(token.Address)
+		if err != nil {
+			return nil, fmt.Errorf("token %s: %w", token.Symbol, err)
+		}
+		out = append(out, target...)
+		out = append(out, uintWord(1)...)
+		out = append(out, uintWord(96)...)
+		out = append(out, uintWord(uint64(len(innerCall)))...)
+		out = append(out, innerCall...)
+		out = append(out, make([]byte, padded32(len(innerCall))-len(innerCall))...)
+	}
+	return out, nil
+}
+
+// decodeAggregate3Balances decodes the (bool success, bytes returnData)[] result of aggregate3
+func decodeAggregate3Balances(data []byte, expected int) ([]*big.Int, error) {
+	arrayOffset, err := readWord(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	length, err := readWord(data, arrayOffset)
+	if err != nil {
+		return nil, err
+	}
+	if length != expected {
+		return nil, fmt.Errorf("aggregate3 returned %d results, expected %d", length, expected)
+	}
+
+	base := arrayOffset + 32
+	amounts := make([]*big.Int, length)
+	for i := 0; i < length; i++ {
+		tupleOffset, err := readWord(data, base+i*32)
+		if err != nil {
+			return nil, err
+		}
+		tuple := base + tupleOffset
+
+		success, err := readWord(data, tuple)
+		if err != nil {
+			return nil, err
+		}
+		bytesOffset, err := readWord(data, tuple+32)
+		if err != nil {
+			return nil, err
+		}
+		bytesLen, err := readWord(data, tuple+bytesOffset)
+		if err != nil {
+			return nil, err
+		}
+		if success == 0 || bytesLen < 32 {
+			continue
+		}
+		start := tuple + bytesOffset + 32
+		if start+32 > len(data) {
+			return nil, fmt.Errorf("aggregate3 result truncated")
+		}
+		amounts[i] = new(big.Int).SetBytes(data[start : start+32])
+	}
+	return amounts, nil
+}
+
+// readWord reads a 32-byte big-endian word at offset as an int
+func readWord(data []byte, offset int) (int, error) {
+	if offset < 0 || offset+32 > len(data) {
+		return 0, fmt.Errorf("aggregate3 result truncated")
+	}
+	word := new(big.Int).SetBytes(data[offset : offset+32])
+	if !word.IsInt64() || word.Int64() > int64(len(data)) {
+		return 0, fmt.Errorf("aggregate3 result has invalid offset")
+	}
+	return int(word.Int64()), nil
+}
+
+func addressWord(address string) ([]byte, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(address), "0x"))
+	if err != nil || len(raw) != 20 {
+		return nil, fmt.Errorf("invalid EVM address: %s", address)
+	}
+	return append(make([]byte, 12), raw...), nil
+}
+
+func uintWord(v uint64) []byte {
+	word := make([]byte, 32)
+	new(big.Int).SetUint64(v).FillBytes(word)
+	return word
+}
+
+func padded32(n int) int {
+	return (n + 31) / 32 * 32
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func parseHexBig(s string) (*big.Int, error) {
+	trimmed := strings.TrimPrefix(s, "0x")
+	if trimmed == "" {
+		return big.NewInt(0), nil
+	}
+	v, ok := new(big.Int).SetString(trimmed, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid hex quantity '%s'", s)
+	}
+	return v, nil
+}
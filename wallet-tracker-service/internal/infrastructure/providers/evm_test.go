@@ -0,0 +1,220 @@
+package providers
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+const (
+	testEVMOwner = "0x1111111111111111111111111111111111111111"
+	testUSDC     = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	testDAI      = "0x6B175474E89094C44Da98b954EedeAC495271d0F"
+	testBroken   = "0x2222222222222222222222222222222222222222"
+)
+
+// multicallStub answers aggregate3 calls from per-token balances; tokens missing from balances fail their sub-call
+func multicallStub(t *testing.T, native string, balances map[string]*big.Int) rpcHandler {
+	return func(method string, params []json.RawMessage) (interface{}, *jsonRPCError) {
+		switch method {
+		case "eth_getBalance":
+			var owner string
+			_ = json.Unmarshal(params[0], &owner)
+			if owner != testEVMOwner {
+				t.Errorf("eth_getBalance for %s, want %s", owner, testEVMOwner)
+			}
+			return native, nil
+		case "eth_call":
+			var call map[string]string
+			_ = json.Unmarshal(params[0], &call)
+			if !strings.EqualFold(call["to"], multicall3Address) {
+				t.Errorf("eth_call to %s, want Multicall3", call["to"])
+			}
+			targets, owners := decodeAggregate3Call(t, call["data"])
+			results := make([]*big.Int, len(targets))
+			for i, target := range targets {
+				if owners[i] != strings.ToLower(testEVMOwner) {
+					t.Errorf("balanceOf(%s), want %s", owners[i], testEVMOwner)
+				}
+				results[i] = balances[target]
+			}
+			return "0x" + hex.EncodeToString(encodeAggregate3Result(results)), nil
+		default:
+			return nil, &jsonRPCError{Code: -32601, Message: "method not found"}
+		}
+	}
+}
+
+// decodeAggregate3Call returns the lowercase targets and balanceOf owners of an aggregate3 calldata
+func decodeAggregate3Call(t *testing.T, data string) ([]string, []string) {
+	t.Helper()
+	raw, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil {
+		t.Fatalf("calldata is not hex: %v", err)
+	}
+	if hex.EncodeToString(raw[:4]) != aggregate3Selector {
+		t.Fatalf("selector %x, want %s", raw[:4], aggregate3Selector)
+	}
+	args := raw[4:]
+	word := func(offset int) int {
+		return int(new(big.Int).SetBytes(args[offset : offset+32]).Int64())
+	}
+
+	base := word(0) + 32
+	n := word(base - 32)
+	var targets, owners []string
+	for i := 0; i < n; i++ {
+		tuple := base + word(base+i*32)
+		targets = append(targets, "0x"+hex.EncodeToString(args[tuple+12:tuple+32]))
+		if word(tuple+32) != 1 {
+			t.Errorf("call %d does not allow failure", i)
+		}
+		callData := tuple + word(tuple+64)
+		inner := args[callData+32 : callData+32+word(callData)]
+		if hex.EncodeToString(inner[:4]) != balanceOfSelector {
+			t.Errorf("call %d selector %x, want balanceOf", i, inner[:4])
+		}
+		owners = append(owners, "0x"+hex.EncodeToString(inner[4+12:4+32]))
+	}
+	return targets, owners
+}
+
+// encodeAggregate3Result ABI-encodes (bool,bytes)[]; a nil amount is encoded as a failed call
+func encodeAggregate3Result(amounts []*big.Int) []byte {
+	out := uintWord(32)
+	out = append(out, uintWord(uint64(len(amounts)))...)
+
+	var tuples [][]byte
+	for _, amount := range amounts {
+		var tuple []byte
+		if amount == nil {
+			tuple = append(uintWord(0), uintWord(64)...)
+			tuple = append(tuple, uintWord(0)...)
+		} else {
+			tuple = append(uintWord(1), uintWord(64)...)
+			tuple = append(tuple, uintWord(32)...)
+			word := make([]byte, 32)
+			amount.FillBytes(word)
+			tuple = append(tuple, word...)
+		}
+		tuples = append(tuples, tuple)
+	}
+
+	offset := len(amounts) * 32
+	for _, tuple := range tuples {
+		out = append(out, uintWord(uint64(offset))...)
+		offset += len(tuple)
+	}
+	for _, tuple := range tuples {
+		out = append(out, tuple...)
+	}
+	return out
+}
+
+func newTestEVMProvider(url string, tokens []TokenInfo) *EVMProvider {
+	return &EVMProvider{
+		rpcClients:   map[string]*jsonRPCClient{"ETH": newJSONRPCClient(url, nil)},
+		nativeAssets: map[string]entities.Asset{"ETH": {Symbol: "ETH", Name: "Ether", Decimals: 18}},
+		tokens:       map[string][]TokenInfo{"ETH": tokens},
+		logger:       logs.NewLogger(),
+	}
+}
+
+func TestEVMProviderGetWalletBalance(t *testing.T) {
+	tokens := []TokenInfo{
+		{Address: testUSDC, Symbol: "USDC", Decimals: 6},
+		{Address: testDAI, Symbol: "DAI", Decimals: 18},
+		{Address: testBroken, Symbol: "BROKEN", Decimals: 18},
+	}
+	usdc := big.NewInt(2500000)
+	balances := map[string]*big.Int{
+		strings.ToLower(testUSDC): usdc,
+		strings.ToLower(testDAI):  big.NewInt(0),
+	}
+	server := newRPCStub(t, multicallStub(t, "0xde0b6b3a7640000", balances))
+	provider := newTestEVMProvider(server.URL, tokens)
+
+	wallets, err := provider.GetWalletBalance(context.Background(), "ETH", testEVMOwner)
+	if err != nil {
+		t.Fatalf("GetWalletBalance: %v", err)
+	}
+	if len(wallets) != 1 {
+		t.Fatalf("got %d wallets, want 1", len(wallets))
+	}
+
+	got := make(map[string]string)
+	for _, b := range wallets[0].Balances {
+		got[b.Asset.Symbol] = b.FormattedAmount()
+	}
+	want := map[string]string{"ETH": "1", "USDC": "2.5"}
+	if len(got) != len(want) {
+		t.Errorf("balances %v, want %v", got, want)
+	}
+	for symbol, amount := range want {
+		if got[symbol] != amount {
+			t.Errorf("%s balance = %q, want %q", symbol, got[symbol], amount)
+		}
+	}
+}
+
+func TestEVMProviderBatchesTokens(t *testing.T) {
+	var tokens []TokenInfo
+	balances := make(map[string]*big.Int)
+	for i := 0; i < multicallBatchSize+5; i++ {
+		addr := "0x" + strings.Repeat("0", 36) + hex.EncodeToString([]byte{byte(i >> 8), byte(i)})
+		tokens = append(tokens, TokenInfo{Address: addr, Symbol: "T", Decimals: 0})
+		balances[addr] = big.NewInt(int64(i + 1))
+	}
+
+	calls := 0
+	stub := multicallStub(t, "0x0", balances)
+	server := newRPCStub(t, func(method string, params []json.RawMessage) (interface{}, *jsonRPCError) {
+		if method == "eth_call" {
+			calls++
+		}
+		return stub(method, params)
+	})
+	provider := newTestEVMProvider(server.URL, tokens)
+
+	wallets, err := provider.GetWalletBalance(context.Background(), "ETH", testEVMOwner)
+	if err != nil {
+		t.Fatalf("GetWalletBalance: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("made %d aggregate3 calls, want 2", calls)
+	}
+	// The zero native balance is kept, every token has a balance
+	if n := len(wallets[0].Balances); n != len(tokens)+1 {
+		t.Errorf("got %d balances, want %d", n, len(tokens)+1)
+	}
+}
+
+func TestEVMProviderRPCError(t *testing.T) {
+	server := newRPCStub(t, func(string, []json.RawMessage) (interface{}, *jsonRPCError) {
+		return nil, &jsonRPCError{Code: -32000, Message: "header not found"}
+	})
+	provider := newTestEVMProvider(server.URL, nil)
+
+	if _, err := provider.GetWalletBalance(context.Background(), "ETH", testEVMOwner); err == nil {
+		t.Error("GetWalletBalance should fail on an RPC error")
+	}
+	if _, err := provider.GetWalletBalance(context.Background(), "SOLANA", testEVMOwner); err == nil {
+		t.Error("GetWalletBalance should reject a non-EVM chain")
+	}
+}
+
+func TestDecodeAggregate3BalancesTruncated(t *testing.T) {
+	result := encodeAggregate3Result([]*big.Int{big.NewInt(1), big.NewInt(2)})
+	if _, err := decodeAggregate3Balances(result[:len(result)-32], 2); err == nil {
+		t.Error("decoding a truncated result should fail")
+	}
+	if _, err := decodeAggregate3Balances(result, 3); err == nil {
+		t.Error("decoding a result with the wrong length should fail")
+	}
+}
@@ -0,0 +1,317 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+const (
+	defaultEtherscanAPIURL = "https://api.etherscan.io/v2/api"
+
+	etherscanPageSize = 1000
+	// etherscanMaxPages bounds one sync; the cursor stops where it got to and the next sync resumes
+	etherscanMaxPages = 10
+)
+
+// EVMTransactionProvider reads native, internal and ERC-20 transfers from an Etherscan v2
+// compatible API, which serves every EVM chain through a chainid parameter.
+// The cursor is the last block number fully ingested.
+type EVMTransactionProvider struct {
+	apiURL       string
+	apiKey       string
+	chainIDs     map[string]int64
+	nativeAssets map[string]entities.Asset
+	tokens       map[string]map[string]TokenInfo
+	httpClient   *http.Client
+	logger       *logs.Logger
+}
+
+type etherscanResponse struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// etherscanTransfer covers the fields of txlist, txlistinternal and tokentx entries
+type etherscanTransfer struct {
+	BlockNumber     string `json:"blockNumber"`
+	TimeStamp       string `json:"timeStamp"`
+	Hash            string `json:"hash"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	ContractAddress string `json:"contractAddress"`
+	Input           string `json:"input"`
+	GasUsed         string `json:"gasUsed"`
+	GasPrice        string `json:"gasPrice"`
+	IsError         string `json:"isError"`
+	LogIndex        string `json:"logIndex"`
+	TraceID         string `json:"traceId"`
+	TokenName       string `json:"tokenName"`
+	TokenSymbol     string `json:"tokenSymbol"`
+	TokenDecimal    string `json:"tokenDecimal"`
+}
+
+func NewEVMTransactionProvider(apiURL, apiKey string, registry *chains.Registry, tokens map[string][]TokenInfo, logger *logs.Logger) *EVMTransactionProvider {
+	if apiURL == "" {
+		apiURL = defaultEtherscanAPIURL
+	}
+	chainIDs := make(map[string]int64)
+	nativeAssets := make(map[string]entities.Asset)
+	for _, chain := range registry.All() {
+		if chain.EVMChainID == 0 {
+			continue
+		}
+		chainIDs[chain.Key] = chain.EVMChainID
+		nativeAssets[chain.Key] = chain.NativeAsset.Asset()
+	}
+	known := make(map[string]map[string]TokenInfo, len(tokens))
+	for chain, list := range tokens {
+		byAddress := make(map[string]TokenInfo, len(list))
+		for _, t := range list {
+			byAddress[strings.ToLower(t.Address)] = t
+		}
+		known[chain] = byAddress
+	}
+	return &EVMTransactionProvider{
+		apiURL:       apiURL,
+		apiKey:       apiKey,
+		chainIDs:     chainIDs,
+		nativeAssets: nativeAssets,
+		tokens:       known,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		logger:       logger,
+	}
+}
+
+func (p *EVMTransactionProvider) Name() string {
+	return "etherscan"
+}
+
+// GetTransactions fetches the transfers in blocks after the cursor, up to the current head
+func (p *EVMTransactionProvider) GetTransactions(ctx context.Context, blockchain, address, cursor string) ([]entities.Transaction, string, error) {
+	chainID, ok := p.chainIDs[blockchain]
+	if !ok {
+		return nil, "", fmt.Errorf("blockchain '%s' is not an EVM chain", blockchain)
+	}
+
+	var from int64
+	if cursor != "" {
+		last, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid EVM transaction cursor '%s'", cursor)
+		}
+		from = last + 1
+	}
+
+	head, err := p.blockNumber(ctx, chainID)
+	if err != nil {
+		return nil, "", err
+	}
+	if head < from {
+		return nil, cursor, nil
+	}
+	p.logger.Infof("EVM transaction sync for %s.%s, blocks %d-%d", blockchain, address, from, head)
+
+	var txs []entities.Transaction
+	covered := head
+	for _, action := range []string{"txlist", "txlistinternal", "tokentx"} {
+		transfers, actionCovered, err := p.fetchAction(ctx, chainID, action, address, from, head)
+		if err != nil {
+			return nil, "", err
+		}
+		if actionCovered < covered {
+			covered = actionCovered
+		}
+		for _, t := range transfers {
+			txs = append(txs, p.toTransaction(blockchain, address, action, t))
+		}
+	}
+
+	if covered < from {
+		// Nothing was fully covered; keep the old cursor so the next sync retries the same range
+		return txs, cursor, nil
+	}
+	return txs, strconv.FormatInt(covered, 10), nil
+}
+
+// fetchAction pages through one account action in ascending block order.
+// It returns the transfers and the last block it is sure to have fully read.
+func (p *EVMTransactionProvider) fetchAction(ctx context.Context, chainID int64, action, address string, from, head int64) ([]etherscanTransfer, int64, error) {
+	var all []etherscanTransfer
+	start := from
+	for page := 0; page < etherscanMaxPages; page++ {
+		params := url.Values{
+			"module":     {"account"},
+			"action":     {action},
+			"address":    {address},
+			"startblock": {strconv.FormatInt(start, 10)},
+			"endblock":   {strconv.FormatInt(head, 10)},
+			"page":       {"1"},
+			"offset":     {strconv.Itoa(etherscanPageSize)},
+			"sort":       {"asc"},
+		}
+		var batch []etherscanTransfer
+		if err := p.get(ctx, chainID, params, &batch); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", action, err)
+		}
+		all = append(all, batch...)
+		if len(batch) < etherscanPageSize {
+			return all, head, nil
+		}
+
+		// A full page may end part way through its last block, so the next page starts at that block again
+		last, _ := strconv.ParseInt(batch[len(batch)-1].BlockNumber, 10, 64)
+		if last <= start {
+			p.logger.Warnf("%s: block %d has more than %d transfers for %s, skipping the rest", action, last, etherscanPageSize, address)
+			return all, last, nil
+		}
+		start = last
+	}
+	return all, start - 1, nil
+}
+
+func (p *EVMTransactionProvider) toTransaction(blockchain, address, action string, t etherscanTransfer) entities.Transaction {
+	block, _ := strconv.ParseInt(t.BlockNumber, 10, 64)
+	unix, _ := strconv.ParseInt(t.TimeStamp, 10, 64)
+	amount, _ := entities.ParseRawAmount(t.Value)
+
+	tx := entities.Transaction{
+		Blockchain:  blockchain,
+		Address:     address,
+		Hash:        t.Hash,
+		BlockNumber: block,
+		Timestamp:   time.Unix(unix, 0).UTC(),
+		Direction:   transferDirection(address, t.From, t.To),
+		From:        t.From,
+		To:          t.To,
+		Asset:       p.nativeAssets[blockchain],
+		Amount:      amount,
+		Failed:      t.IsError == "1",
+	}
+
+	switch action {
+	case "txlist":
+		tx.LegID = "tx"
+		if t.Input != "" && t.Input != "0x" {
+			tx.Contract = t.To
+			if len(t.Input) >= 10 {
+				tx.Method = strings.ToLower(t.Input[:10])
+			}
+		}
+		if t.To == "" && t.ContractAddress != "" {
+			tx.Contract = t.ContractAddress
+		}
+		if strings.EqualFold(t.From, address) {
+			gasUsed, _ := new(big.Int).SetString(t.GasUsed, 10)
+			gasPrice, _ := new(big.Int).SetString(t.GasPrice, 10)
+			if gasUsed != nil && gasPrice != nil {
+				tx.Fee = entities.NewRawAmount(new(big.Int).Mul(gasUsed, gasPrice))
+			}
+		}
+	case "txlistinternal":
+		traceID := t.TraceID
+		if traceID == "" {
+			traceID = fmt.Sprintf("%s-%s-%s", strings.ToLower(t.From), strings.ToLower(t.To), t.Value)
+		}
+		tx.LegID = "internal:" + traceID
+	case "tokentx":
+		tx.LegID = "log:" + t.LogIndex
+		tx.Asset = p.tokenAsset(blockchain, t)
+	}
+
+	// A failed transaction still pays gas but moves nothing
+	if tx.Failed {
+		tx.Amount = entities.RawAmount{}
+	}
+	return tx
+}
+
+// tokenAsset describes a token from the transfer, preferring the token list's metadata
+func (p *EVMTransactionProvider) tokenAsset(blockchain string, t etherscanTransfer) entities.Asset {
+	decimals, _ := strconv.Atoi(t.TokenDecimal)
+	asset := entities.Asset{
+		Symbol:   t.TokenSymbol,
+		Name:     t.TokenName,
+		Address:  t.ContractAddress,
+		Decimals: decimals,
+	}
+	if known, ok := p.tokens[blockchain][strings.ToLower(t.ContractAddress)]; ok {
+		asset.Symbol = known.Symbol
+		asset.Name = known.Name
+		asset.Address = known.Address
+		asset.LogoURI = known.LogoURI
+		asset.CoingeckoID = known.CoingeckoID
+	}
+	return asset
+}
+
+// blockNumber returns the chain head through the API's eth_blockNumber proxy
+func (p *EVMTransactionProvider) blockNumber(ctx context.Context, chainID int64) (int64, error) {
+	var head string
+	params := url.Values{"module": {"proxy"}, "action": {"eth_blockNumber"}}
+	if err := p.get(ctx, chainID, params, &head); err != nil {
+		return 0, fmt.Errorf("eth_blockNumber: %w", err)
+	}
+	n, err := parseHexBig(head)
+	if err != nil {
+		return 0, err
+	}
+	return n.Int64(), nil
+}
+
+func (p *EVMTransactionProvider) get(ctx context.Context, chainID int64, params url.Values, out interface{}) error {
+	params.Set("chainid", strconv.FormatInt(chainID, 10))
+	params.Set("apikey", p.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create explorer request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed calling explorer API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("explorer API error: %s", string(body))
+	}
+
+	var res etherscanResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("failed to unmarshal explorer response: %w", err)
+	}
+	// Account actions report an empty history as status 0; proxy actions have no status at all
+	if res.Status == "0" {
+		if strings.HasPrefix(res.Message, "No transactions found") || strings.HasPrefix(res.Message, "No records found") {
+			return nil
+		}
+		var reason string
+		if json.Unmarshal(res.Result, &reason) != nil {
+			reason = string(res.Result)
+		}
+		return fmt.Errorf("explorer API error: %s: %s", res.Message, reason)
+	}
+	if err := json.Unmarshal(res.Result, out); err != nil {
+		return fmt.Errorf("failed to decode explorer result: %w", err)
+	}
+	return nil
+}
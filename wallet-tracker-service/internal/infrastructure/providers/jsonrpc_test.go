@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// rpcHandler answers one JSON-RPC method call of a stub node
+type rpcHandler func(method string, params []json.RawMessage) (interface{}, *jsonRPCError)
+
+// newRPCStub starts a JSON-RPC server answering every request with handler
+func newRPCStub(t *testing.T, handler rpcHandler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, rpcErr := handler(req.Method, req.Params)
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if rpcErr != nil {
+			res["error"] = rpcErr
+		} else {
+			res["result"] = result
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJSONRPCClientCall(t *testing.T) {
+	server := newRPCStub(t, func(method string, params []json.RawMessage) (interface{}, *jsonRPCError) {
+		switch method {
+		case "echo":
+			var s string
+			_ = json.Unmarshal(params[0], &s)
+			return s, nil
+		default:
+			return nil, &jsonRPCError{Code: -32601, Message: "method not found"}
+		}
+	})
+	client := newJSONRPCClient(server.URL, nil)
+
+	var out string
+	if err := client.Call(context.Background(), "echo", []interface{}{"hello"}, &out); err != nil {
+		t.Fatalf("echo: %v", err)
+	}
+	if out != "hello" {
+		t.Errorf("echo = %q, want hello", out)
+	}
+
+	err := client.Call(context.Background(), "missing", nil, &out)
+	var rpcErr *jsonRPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Errorf("missing method error = %v, want rpc error -32601", err)
+	}
+}
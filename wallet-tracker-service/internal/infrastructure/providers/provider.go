@@ -0,0 +1,152 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/config"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+// IBalanceProvider fetches the current balances of a wallet from a data source
+type IBalanceProvider interface {
+	Name() string
+	GetWalletBalance(ctx context.Context, blockchain, address string) ([]entities.Wallet, error)
+}
+
+// Registry routes balance lookups to the provider configured for each chain
+type Registry struct {
+	defaultProvider IBalanceProvider
+	chainProviders  map[string]IBalanceProvider
+}
+
+func NewRegistry(defaultProvider IBalanceProvider) *Registry {
+	return &Registry{
+		defaultProvider: defaultProvider,
+		chainProviders:  make(map[string]IBalanceProvider),
+	}
+}
+
+// NewRegistryFromConfig builds a registry with the default provider and per-chain providers.
+// A chain uses the provider named in the chain registry unless CHAIN_PROVIDERS overrides it.
+func NewRegistryFromConfig(conf *config.Config, chainRegistry *chains.Registry, logger *logs.Logger) (*Registry, error) {
+	tokens, err := LoadTokenList(conf.TokenListPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Providers are shared across the chains that use them
+	created := make(map[string]IBalanceProvider)
+	get := func(name string) (IBalanceProvider, error) {
+		name = strings.ToLower(name)
+		if p, ok := created[name]; ok {
+			return p, nil
+		}
+		p, err := newProvider(name, conf, chainRegistry, tokens, logger)
+		if err != nil {
+			return nil, err
+		}
+		created[name] = p
+		return p, nil
+	}
+
+	defaultProvider, err := get(conf.BalanceProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	providerNames := make(map[string]string)
+	for _, chain := range chainRegistry.All() {
+		if chain.Provider.Name != "" {
+			providerNames[chain.Key] = chain.Provider.Name
+		}
+	}
+	for blockchain, name := range conf.ChainProviders {
+		providerNames[blockchain] = name
+	}
+
+	registry := NewRegistry(defaultProvider)
+	for blockchain, name := range providerNames {
+		p, err := get(name)
+		if err != nil {
+			return nil, fmt.Errorf("provider for %s: %w", blockchain, err)
+		}
+		registry.Register(blockchain, p)
+	}
+
+	return registry, nil
+}
+
+// newProvider creates a provider by its configured name
+func newProvider(name string, conf *config.Config, chainRegistry *chains.Registry, tokens map[string][]TokenInfo, logger *logs.Logger) (IBalanceProvider, error) {
+	switch name {
+	case "", "rango":
+		return NewRangoProvider(conf.RangoAPIKey, conf.RangoBaseURL, logger), nil
+	case "evm":
+		return NewEVMProvider(chainRegistry, tokens, logger), nil
+	case "solana":
+		chain, ok := chainRegistry.Chain("SOLANA")
+		if !ok {
+			return nil, fmt.Errorf("chain registry has no SOLANA entry")
+		}
+		return NewSolanaProvider(chain, chainRegistry.RPCURL(chain.Key), tokens[chain.Key], logger), nil
+	case "btc":
+		chain, ok := chainRegistry.Chain("BTC")
+		if !ok {
+			return nil, fmt.Errorf("chain registry has no BTC entry")
+		}
+		// BTC_ESPLORA_URL takes precedence over the registry's API URL
+		baseURL := conf.BTCEsploraURL
+		if baseURL == "" {
+			baseURL = chain.Provider.APIURL
+		}
+		return NewBitcoinProvider(chain, baseURL, conf.BTCGapLimit, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown balance provider '%s'", name)
+	}
+}
+
+// Register sets the provider used for a blockchain
+func (r *Registry) Register(blockchain string, p IBalanceProvider) {
+	r.chainProviders[strings.ToUpper(blockchain)] = p
+}
+
+// ProviderFor returns the provider configured for a blockchain, falling back to the default
+func (r *Registry) ProviderFor(blockchain string) (IBalanceProvider, error) {
+	if p, ok := r.chainProviders[strings.ToUpper(blockchain)]; ok {
+		return p, nil
+	}
+	if r.defaultProvider == nil {
+		return nil, fmt.Errorf("no balance provider configured for %s", blockchain)
+	}
+	return r.defaultProvider, nil
+}
+
+func (r *Registry) Name() string {
+	return "registry"
+}
+
+// GetWalletBalance dispatches to the provider configured for the blockchain
+func (r *Registry) GetWalletBalance(ctx context.Context, blockchain, address string) ([]entities.Wallet, error) {
+	p, err := r.ProviderFor(blockchain)
+	if err != nil {
+		return nil, err
+	}
+	return p.GetWalletBalance(ctx, blockchain, address)
+}
+
+// GetUTXOs dispatches to the chain provider when it exposes a UTXO set
+func (r *Registry) GetUTXOs(ctx context.Context, blockchain, address string) (map[string][]entities.UTXO, error) {
+	p, err := r.ProviderFor(blockchain)
+	if err != nil {
+		return nil, err
+	}
+	utxoProvider, ok := p.(IUTXOProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider '%s' does not expose UTXOs for %s", p.Name(), blockchain)
+	}
+	return utxoProvider.GetUTXOs(ctx, blockchain, address)
+}
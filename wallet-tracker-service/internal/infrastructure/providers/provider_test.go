@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+type fakeProvider struct {
+	name  string
+	calls []string
+}
+
+func (f *fakeProvider) Name() string {
+	return f.name
+}
+
+func (f *fakeProvider) GetWalletBalance(_ context.Context, blockchain, address string) ([]entities.Wallet, error) {
+	f.calls = append(f.calls, blockchain+"."+address)
+	return []entities.Wallet{{Blockchain: blockchain, Address: address, Label: f.name}}, nil
+}
+
+func TestRegistryRouting(t *testing.T) {
+	fallback := &fakeProvider{name: "fallback"}
+	evm := &fakeProvider{name: "evm"}
+	solana := &fakeProvider{name: "solana"}
+
+	registry := NewRegistry(fallback)
+	registry.Register("ETH", evm)
+	registry.Register("polygon", evm)
+	registry.Register("SOLANA", solana)
+
+	tests := []struct {
+		blockchain string
+		want       string
+	}{
+		{"ETH", "evm"},
+		{"eth", "evm"},
+		{"POLYGON", "evm"},
+		{"SOLANA", "solana"},
+		{"BTC", "fallback"},
+		{"UNKNOWN", "fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.blockchain, func(t *testing.T) {
+			p, err := registry.ProviderFor(tt.blockchain)
+			if err != nil {
+				t.Fatalf("ProviderFor(%s): %v", tt.blockchain, err)
+			}
+			if p.Name() != tt.want {
+				t.Errorf("ProviderFor(%s) = %s, want %s", tt.blockchain, p.Name(), tt.want)
+			}
+
+			balances, err := registry.GetWalletBalance(context.Background(), tt.blockchain, "addr")
+			if err != nil {
+				t.Fatalf("GetWalletBalance(%s): %v", tt.blockchain, err)
+			}
+			if len(balances) != 1 || balances[0].Label != tt.want {
+				t.Errorf("GetWalletBalance(%s) served by %v, want %s", tt.blockchain, balances, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryWithoutDefault(t *testing.T) {
+	evm := &fakeProvider{name: "evm"}
+	registry := NewRegistry(nil)
+	registry.Register("ETH", evm)
+
+	if _, err := registry.ProviderFor("ETH"); err != nil {
+		t.Errorf("ProviderFor(ETH): %v", err)
+	}
+	if _, err := registry.ProviderFor("BTC"); err == nil {
+		t.Error("ProviderFor(BTC) without a default provider should fail")
+	}
+	if _, err := registry.GetWalletBalance(context.Background(), "BTC", "addr"); err == nil {
+		t.Error("GetWalletBalance(BTC) without a default provider should fail")
+	}
+	if len(evm.calls) != 0 {
+		t.Errorf("evm provider called for %v", evm.calls)
+	}
+}
+
+func TestRegistryGetUTXOsUnsupported(t *testing.T) {
+	registry := NewRegistry(&fakeProvider{name: "fallback"})
+	if _, err := registry.GetUTXOs(context.Background(), "BTC", "addr"); err == nil {
+		t.Error("GetUTXOs should fail when the provider exposes no UTXO set")
+	}
+}
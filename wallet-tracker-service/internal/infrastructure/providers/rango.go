@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+const defaultRangoBaseURL = "https://api.rango.exchange"
+
+// RangoProvider fetches balances from the Rango wallet details API
+type RangoProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	logger     *logs.Logger
+}
+
+type rangoWalletDetailsResponse struct {
+	Wallets []rangoWallet `json:"wallets"`
+}
+
+type rangoWallet struct {
+	Failed     bool           `json:"failed"`
+	BlockChain string         `json:"blockChain"`
+	Address    string         `json:"address"`
+	Balances   []rangoBalance `json:"balances"`
+}
+
+type rangoBalance struct {
+	Asset struct {
+		Blockchain string  `json:"blockchain"`
+		Symbol     string  `json:"symbol"`
+		Address    *string `json:"address"`
+	} `json:"asset"`
+	Amount struct {
+		Amount   string `json:"amount"`
+		Decimals int    `json:"decimals"`
+	} `json:"amount"`
+}
+
+func NewRangoProvider(apiKey, baseURL string, logger *logs.Logger) *RangoProvider {
+	if baseURL == "" {
+		baseURL = defaultRangoBaseURL
+	}
+	return &RangoProvider{
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 20 * time.Second},
+		logger:     logger,
+	}
+}
+
+func (p *RangoProvider) Name() string {
+	return "rango"
+}
+
+// GetWalletBalance calls GET /wallets/details for a BLOCKCHAIN.ADDRESS pair
+func (p *RangoProvider) GetWalletBalance(ctx context.Context, blockchain, address string) ([]entities.Wallet, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("rango API key not configured")
+	}
+
+	query := url.Values{}
+	query.Set("address", fmt.Sprintf("%s.%s", blockchain, address))
+	query.Set("apiKey", p.apiKey)
+	apiURL := fmt.Sprintf("%s/wallets/details?%s", p.baseURL, query.Encode())
+	p.logger.Infof("GET: %s/wallets/details?address=%s.%s", p.baseURL, blockchain, address)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rango request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed calling rango API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rango API error: %s", string(bodyBytes))
+	}
+
+	var apiRes rangoWalletDetailsResponse
+	if err := json.Unmarshal(bodyBytes, &apiRes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rango response: %w", err)
+	}
+
+	wallets := make([]entities.Wallet, 0, len(apiRes.Wallets))
+	for _, rw := range apiRes.Wallets {
+		if rw.Failed {
+			return nil, fmt.Errorf("rango failed to fetch %s.%s", rw.BlockChain, rw.Address)
+		}
+		wallet, err := rw.toEntity()
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	return wallets, nil
+}
+
+func (rw rangoWallet) toEntity() (entities.Wallet, error) {
+	wallet := entities.Wallet{
+		Blockchain: rw.BlockChain,
+		Address:    rw.Address,
+		Balances:   make([]entities.Balance, 0, len(rw.Balances)),
+	}
+
+	for _, rb := range rw.Balances {
+		contract := ""
+		if rb.Asset.Address != nil {
+			contract = *rb.Asset.Address
+		}
+		amount, err := entities.ParseRawAmount(rb.Amount.Amount)
+		if err != nil {
+			return entities.Wallet{}, fmt.Errorf("rango balance for %s: %w", rb.Asset.Symbol, err)
+		}
+		asset := entities.Asset{
+			Symbol:   rb.Asset.Symbol,
+			Name:     rb.Asset.Symbol,
+			Address:  contract,
+			Decimals: rb.Amount.Decimals,
+		}
+		wallet.Balances = append(wallet.Balances, entities.NewBalance(asset, amount))
+	}
+
+	return wallet, nil
+}
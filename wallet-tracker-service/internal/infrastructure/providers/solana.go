@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+const (
+	splTokenProgramID     = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	splToken2022ProgramID = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+)
+
+// SolanaProvider reads SOL and SPL token balances from a Solana JSON-RPC endpoint
+type SolanaProvider struct {
+	chain     entities.Chain
+	rpcClient *jsonRPCClient
+	mints     map[string]TokenInfo
+	logger    *logs.Logger
+}
+
+type solanaBalanceResult struct {
+	Value uint64 `json:"value"`
+}
+
+type solanaTokenAccountsResult struct {
+	Value []struct {
+		Pubkey  string `json:"pubkey"`
+		Account struct {
+			Data struct {
+				Parsed struct {
+					Info struct {
+						Mint        string `json:"mint"`
+						TokenAmount struct {
+							Amount   string `json:"amount"`
+							Decimals int    `json:"decimals"`
+						} `json:"tokenAmount"`
+					} `json:"info"`
+				} `json:"parsed"`
+			} `json:"data"`
+		} `json:"account"`
+	} `json:"value"`
+}
+
+// NewSolanaProvider creates a provider for the chain at rpcURL; known mints are used to name tokens
+func NewSolanaProvider(chain entities.Chain, rpcURL string, knownMints []TokenInfo, logger *logs.Logger) *SolanaProvider {
+	mints := make(map[string]TokenInfo, len(knownMints))
+	for _, t := range knownMints {
+		mints[t.Address] = t
+	}
+	return &SolanaProvider{
+		chain:     chain,
+		rpcClient: newJSONRPCClient(rpcURL, &http.Client{Timeout: 20 * time.Second}),
+		mints:     mints,
+		logger:    logger,
+	}
+}
+
+func (p *SolanaProvider) Name() string {
+	return "solana"
+}
+
+// GetWalletBalance reads lamports with getBalance and SPL balances for both token programs
+func (p *SolanaProvider) GetWalletBalance(ctx context.Context, blockchain, address string) ([]entities.Wallet, error) {
+	if blockchain != p.chain.Key {
+		return nil, fmt.Errorf("blockchain '%s' is not supported by the solana provider", blockchain)
+	}
+	if p.rpcClient.url == "" {
+		return nil, fmt.Errorf("no RPC URL configured for %s", blockchain)
+	}
+	p.logger.Infof("Solana balance lookup for %s", address)
+
+	var lamports solanaBalanceResult
+	if err := p.rpcClient.Call(ctx, "getBalance", []interface{}{address}, &lamports); err != nil {
+		return nil, err
+	}
+	rawLamports := entities.NewRawAmount(new(big.Int).SetUint64(lamports.Value))
+
+	wallet := entities.Wallet{
+		Blockchain: blockchain,
+		Address:    address,
+		Balances:   []entities.Balance{entities.NewBalance(p.chain.NativeAsset.Asset(), rawLamports)},
+	}
+
+	// A wallet may hold several accounts for the same mint, so amounts are summed per mint
+	totals := make(map[string]*big.Int)
+	decimals := make(map[string]int)
+	for _, programID := range []string{splTokenProgramID, splToken2022ProgramID} {
+		var accounts solanaTokenAccountsResult
+		params := []interface{}{
+			address,
+			map[string]string{"programId": programID},
+			map[string]string{"encoding": "jsonParsed"},
+		}
+		if err := p.rpcClient.Call(ctx, "getTokenAccountsByOwner", params, &accounts); err != nil {
+			return nil, err
+		}
+
+		for _, acc := range accounts.Value {
+			info := acc.Account.Data.Parsed.Info
+			amount, ok := new(big.Int).SetString(info.TokenAmount.Amount, 10)
+			if !ok || amount.Sign() == 0 {
+				continue
+			}
+			if totals[info.Mint] == nil {
+				totals[info.Mint] = new(big.Int)
+			}
+			totals[info.Mint].Add(totals[info.Mint], amount)
+			decimals[info.Mint] = info.TokenAmount.Decimals
+		}
+	}
+
+	mints := make([]string, 0, len(totals))
+	for mint := range totals {
+		mints = append(mints, mint)
+	}
+	sort.Strings(mints)
+
+	for _, mint := range mints {
+		wallet.Balances = append(wallet.Balances,
+			entities.NewBalance(p.mintAsset(mint, decimals[mint]), entities.NewRawAmount(totals[mint])))
+	}
+
+	return []entities.Wallet{wallet}, nil
+}
+
+// mintAsset maps a mint account to an asset, using the on-chain decimals
+func (p *SolanaProvider) mintAsset(mint string, decimals int) entities.Asset {
+	asset := entities.Asset{
+		Symbol:   mint,
+		Name:     mint,
+		Address:  mint,
+		Decimals: decimals,
+	}
+	if known, ok := p.mints[mint]; ok {
+		asset.Symbol = known.Symbol
+		asset.Name = known.Name
+		asset.LogoURI = known.LogoURI
+		asset.CoingeckoID = known.CoingeckoID
+	}
+	return asset
+}
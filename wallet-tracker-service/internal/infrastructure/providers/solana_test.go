@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+const (
+	testSolanaOwner = "9WzDXwBbmkg8ZTbNMqUxvQRAyrZzDsGYdLVL9zYtAWWM"
+	testUSDCMint    = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	testPYUSDMint   = "2b1kV6DkPAnxd5ixfnxCpjxmKwqjjaYmCZfHsFu24GXo"
+	testUnknownMint = "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"
+)
+
+func tokenAccount(mint, amount string, decimals int) map[string]interface{} {
+	return map[string]interface{}{
+		"pubkey": mint + "-account",
+		"account": map[string]interface{}{
+			"data": map[string]interface{}{
+				"parsed": map[string]interface{}{
+					"info": map[string]interface{}{
+						"mint": mint,
+						"tokenAmount": map[string]interface{}{
+							"amount":   amount,
+							"decimals": decimals,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestSolanaProvider(url string) *SolanaProvider {
+	chain := entities.Chain{
+		Key:         "SOLANA",
+		NativeAsset: entities.ChainAsset{Symbol: "SOL", Name: "Solana", Decimals: 9},
+	}
+	known := []TokenInfo{{Address: testUSDCMint, Symbol: "USDC", Name: "USD Coin", Decimals: 6}}
+	return NewSolanaProvider(chain, url, known, logs.NewLogger())
+}
+
+func TestSolanaProviderGetWalletBalance(t *testing.T) {
+	programs := make(map[string]int)
+	server := newRPCStub(t, func(method string, params []json.RawMessage) (interface{}, *jsonRPCError) {
+		var owner string
+		_ = json.Unmarshal(params[0], &owner)
+		if owner != testSolanaOwner {
+			t.Errorf("%s for %s, want %s", method, owner, testSolanaOwner)
+		}
+
+		switch method {
+		case "getBalance":
+			return map[string]interface{}{"context": map[string]int{"slot": 1}, "value": 1500000000}, nil
+		case "getTokenAccountsByOwner":
+			var filter, config map[string]string
+			_ = json.Unmarshal(params[1], &filter)
+			_ = json.Unmarshal(params[2], &config)
+			if config["encoding"] != "jsonParsed" {
+				t.Errorf("encoding %q, want jsonParsed", config["encoding"])
+			}
+			programs[filter["programId"]]++
+
+			var accounts []interface{}
+			switch filter["programId"] {
+			case splTokenProgramID:
+				// Two accounts of the same mint are summed, empty accounts are dropped
+				accounts = []interface{}{
+					tokenAccount(testUSDCMint, "1000000", 6),
+					tokenAccount(testUSDCMint, "250000", 6),
+					tokenAccount(testUnknownMint, "0", 6),
+				}
+			case splToken2022ProgramID:
+				accounts = []interface{}{tokenAccount(testPYUSDMint, "4200000", 6)}
+			}
+			return map[string]interface{}{"context": map[string]int{"slot": 1}, "value": accounts}, nil
+		default:
+			return nil, &jsonRPCError{Code: -32601, Message: "method not found"}
+		}
+	})
+	provider := newTestSolanaProvider(server.URL)
+
+	wallets, err := provider.GetWalletBalance(context.Background(), "SOLANA", testSolanaOwner)
+	if err != nil {
+		t.Fatalf("GetWalletBalance: %v", err)
+	}
+	if programs[splTokenProgramID] != 1 || programs[splToken2022ProgramID] != 1 {
+		t.Errorf("token programs queried %v, want both once", programs)
+	}
+
+	balances := wallets[0].Balances
+	want := []struct {
+		symbol string
+		amount string
+	}{
+		{"SOL", "1.5"},
+		{testPYUSDMint, "4.2"}, // unknown mints are named by their address
+		{"USDC", "1.25"},
+	}
+	if len(balances) != len(want) {
+		t.Fatalf("got %d balances, want %d", len(balances), len(want))
+	}
+	for i, w := range want {
+		if balances[i].Asset.Symbol != w.symbol || balances[i].FormattedAmount() != w.amount {
+			t.Errorf("balance %d = %s %s, want %s %s",
+				i, balances[i].FormattedAmount(), balances[i].Asset.Symbol, w.amount, w.symbol)
+		}
+	}
+	if balances[2].Asset.Address != testUSDCMint || balances[2].Asset.Decimals != 6 {
+		t.Errorf("USDC asset = %+v", balances[2].Asset)
+	}
+}
+
+func TestSolanaProviderErrors(t *testing.T) {
+	server := newRPCStub(t, func(method string, _ []json.RawMessage) (interface{}, *jsonRPCError) {
+		if method == "getBalance" {
+			return map[string]interface{}{"value": 0}, nil
+		}
+		return nil, &jsonRPCError{Code: -32602, Message: "Invalid param"}
+	})
+	provider := newTestSolanaProvider(server.URL)
+
+	if _, err := provider.GetWalletBalance(context.Background(), "SOLANA", testSolanaOwner); err == nil {
+		t.Error("GetWalletBalance should fail when token accounts cannot be read")
+	}
+	if _, err := provider.GetWalletBalance(context.Background(), "ETH", testSolanaOwner); err == nil {
+		t.Error("GetWalletBalance should reject another chain")
+	}
+	if _, err := newTestSolanaProvider("").GetWalletBalance(context.Background(), "SOLANA", testSolanaOwner); err == nil {
+		t.Error("GetWalletBalance should fail without an RPC URL")
+	}
+}
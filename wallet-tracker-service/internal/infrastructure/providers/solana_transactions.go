@@ -0,0 +1,256 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+const (
+	solanaSignaturePageSize = 1000
+	// solanaMaxSignaturePages bounds how far back one sync walks towards the cursor
+	solanaMaxSignaturePages = 10
+	// solanaMaxTransactionsPerSync bounds the getTransaction calls of one sync; the oldest go first
+	solanaMaxTransactionsPerSync = 200
+
+	systemProgramID        = "11111111111111111111111111111111"
+	computeBudgetProgramID = "ComputeBudget111111111111111111111111111111"
+)
+
+type solanaSignatureInfo struct {
+	Signature string `json:"signature"`
+}
+
+type solanaTokenBalance struct {
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UITokenAmount struct {
+		Amount   string `json:"amount"`
+		Decimals int    `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+type solanaTransactionResult struct {
+	Slot      int64  `json:"slot"`
+	BlockTime *int64 `json:"blockTime"`
+	Meta      *struct {
+		Err               interface{}          `json:"err"`
+		Fee               uint64               `json:"fee"`
+		PreBalances       []uint64             `json:"preBalances"`
+		PostBalances      []uint64             `json:"postBalances"`
+		PreTokenBalances  []solanaTokenBalance `json:"preTokenBalances"`
+		PostTokenBalances []solanaTokenBalance `json:"postTokenBalances"`
+	} `json:"meta"`
+	Transaction struct {
+		Message struct {
+			AccountKeys []struct {
+				Pubkey string `json:"pubkey"`
+			} `json:"accountKeys"`
+			Instructions []struct {
+				ProgramID string `json:"programId"`
+			} `json:"instructions"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+// GetTransactions ingests the wallet's transactions newer than the cursor signature, oldest first.
+// Without a cursor, history starts at the most recent page of signatures.
+//
+// A sync that reaches the page limit before the cursor ingests nothing and appends the last
+// signature of each page it read to the cursor, as "<until>/<before>/<before>...", lowest first.
+// Later syncs read below those points one at a time, so no signature is skipped and the cursor
+// never moves past a range that was not read.
+func (p *SolanaProvider) GetTransactions(ctx context.Context, blockchain, address, cursor string) ([]entities.Transaction, string, error) {
+	if blockchain != p.chain.Key {
+		return nil, "", fmt.Errorf("blockchain '%s' is not supported by the solana provider", blockchain)
+	}
+	if p.rpcClient.url == "" {
+		return nil, "", fmt.Errorf("no RPC URL configured for %s", blockchain)
+	}
+
+	parts := strings.Split(cursor, "/")
+	until, resume := parts[0], parts[1:]
+
+	// Signatures come newest first; walk back until the cursor
+	var signatures []solanaSignatureInfo
+	var pageEnds []string
+	before := ""
+	if len(resume) > 0 {
+		before = resume[0]
+	}
+	reachedCursor := false
+	for page := 0; page < solanaMaxSignaturePages; page++ {
+		opts := map[string]interface{}{"limit": solanaSignaturePageSize}
+		if until != "" {
+			opts["until"] = until
+		}
+		if before != "" {
+			opts["before"] = before
+		}
+		var batch []solanaSignatureInfo
+		if err := p.rpcClient.Call(ctx, "getSignaturesForAddress", []interface{}{address, opts}, &batch); err != nil {
+			return nil, "", err
+		}
+		signatures = append(signatures, batch...)
+		if len(batch) < solanaSignaturePageSize || until == "" {
+			reachedCursor = true
+			break
+		}
+		before = batch[len(batch)-1].Signature
+		pageEnds = append(pageEnds, before)
+	}
+	if !reachedCursor {
+		next := make([]string, 0, len(pageEnds)+len(resume))
+		for i := len(pageEnds) - 1; i >= 0; i-- {
+			next = append(next, pageEnds[i])
+		}
+		p.logger.Infof("Solana sync for %s read %d signatures without reaching the cursor, the next syncs continue below them", address, len(signatures))
+		return nil, solanaCursor(until, append(next, resume...)), nil
+	}
+
+	// Process oldest first so the cursor only moves past what was ingested
+	for i, j := 0, len(signatures)-1; i < j; i, j = i+1, j-1 {
+		signatures[i], signatures[j] = signatures[j], signatures[i]
+	}
+	if len(signatures) > solanaMaxTransactionsPerSync {
+		signatures = signatures[:solanaMaxTransactionsPerSync]
+	} else if len(resume) > 0 {
+		// Everything below this resume point is ingested
+		resume = resume[1:]
+	}
+	if len(signatures) == 0 {
+		return nil, solanaCursor(until, resume), nil
+	}
+	p.logger.Infof("Solana transaction sync for %s, %d signatures", address, len(signatures))
+
+	var txs []entities.Transaction
+	for _, sig := range signatures {
+		var result *solanaTransactionResult
+		params := []interface{}{
+			sig.Signature,
+			map[string]interface{}{"encoding": "jsonParsed", "maxSupportedTransactionVersion": 0},
+		}
+		if err := p.rpcClient.Call(ctx, "getTransaction", params, &result); err != nil {
+			return nil, "", err
+		}
+		if result == nil || result.Meta == nil {
+			continue
+		}
+		txs = append(txs, p.transfers(address, sig.Signature, result)...)
+	}
+
+	return txs, solanaCursor(signatures[len(signatures)-1].Signature, resume), nil
+}
+
+// solanaCursor joins the newest ingested signature with the points left to resume below
+func solanaCursor(until string, resume []string) string {
+	return strings.Join(append([]string{until}, resume...), "/")
+}
+
+// transfers derives the wallet's legs from the balance changes recorded in the transaction meta.
+// The fee is reported separately from the native leg of the fee payer.
+func (p *SolanaProvider) transfers(address, signature string, result *solanaTransactionResult) []entities.Transaction {
+	meta := result.Meta
+	base := entities.Transaction{
+		Blockchain:  p.chain.Key,
+		Address:     address,
+		Hash:        signature,
+		BlockNumber: result.Slot,
+		Failed:      meta.Err != nil,
+	}
+	if result.BlockTime != nil {
+		base.Timestamp = time.Unix(*result.BlockTime, 0).UTC()
+	}
+	for _, ix := range result.Transaction.Message.Instructions {
+		if ix.ProgramID != computeBudgetProgramID && ix.ProgramID != systemProgramID {
+			base.Contract = ix.ProgramID
+			break
+		}
+	}
+
+	var legs []entities.Transaction
+	for i, key := range result.Transaction.Message.AccountKeys {
+		if key.Pubkey != address || i >= len(meta.PreBalances) || i >= len(meta.PostBalances) {
+			continue
+		}
+		// The fee payer is always the first account
+		feePayer := i == 0
+		delta := new(big.Int).Sub(new(big.Int).SetUint64(meta.PostBalances[i]), new(big.Int).SetUint64(meta.PreBalances[i]))
+		if feePayer {
+			delta.Add(delta, new(big.Int).SetUint64(meta.Fee))
+		}
+		if delta.Sign() == 0 && !feePayer {
+			break
+		}
+
+		leg := base
+		leg.LegID = "native"
+		leg.Asset = p.chain.NativeAsset.Asset()
+		leg.Direction = signedDirection(delta)
+		leg.Amount = entities.NewRawAmount(new(big.Int).Abs(delta))
+		if feePayer {
+			leg.Fee = entities.NewRawAmount(new(big.Int).SetUint64(meta.Fee))
+			if delta.Sign() == 0 {
+				leg.Direction = entities.DirectionOut
+			}
+		}
+		legs = append(legs, leg)
+		break
+	}
+
+	// Token balances are per token account; sum the changes of the accounts the wallet owns
+	deltas := make(map[string]*big.Int)
+	decimals := make(map[string]int)
+	add := func(balances []solanaTokenBalance, sign int64) {
+		for _, b := range balances {
+			if b.Owner != address {
+				continue
+			}
+			amount, ok := new(big.Int).SetString(b.UITokenAmount.Amount, 10)
+			if !ok {
+				continue
+			}
+			if deltas[b.Mint] == nil {
+				deltas[b.Mint] = new(big.Int)
+			}
+			deltas[b.Mint].Add(deltas[b.Mint], amount.Mul(amount, big.NewInt(sign)))
+			decimals[b.Mint] = b.UITokenAmount.Decimals
+		}
+	}
+	add(meta.PreTokenBalances, -1)
+	add(meta.PostTokenBalances, 1)
+
+	mints := make([]string, 0, len(deltas))
+	for mint, delta := range deltas {
+		if delta.Sign() != 0 {
+			mints = append(mints, mint)
+		}
+	}
+	sort.Strings(mints)
+	for _, mint := range mints {
+		delta := deltas[mint]
+		leg := base
+		leg.LegID = "mint:" + mint
+		leg.Asset = p.mintAsset(mint, decimals[mint])
+		leg.Direction = signedDirection(delta)
+		leg.Amount = entities.NewRawAmount(new(big.Int).Abs(delta))
+		legs = append(legs, leg)
+	}
+	return legs
+}
+
+func signedDirection(delta *big.Int) string {
+	switch delta.Sign() {
+	case 1:
+		return entities.DirectionIn
+	case -1:
+		return entities.DirectionOut
+	default:
+		return entities.DirectionSelf
+	}
+}
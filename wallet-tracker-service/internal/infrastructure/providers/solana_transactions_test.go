@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// signatureHistory is a stub of getSignaturesForAddress over signatures ordered oldest first
+type signatureHistory []string
+
+func (h signatureHistory) page(params []json.RawMessage) []solanaSignatureInfo {
+	var opts struct {
+		Limit  int    `json:"limit"`
+		Until  string `json:"until"`
+		Before string `json:"before"`
+	}
+	_ = json.Unmarshal(params[1], &opts)
+
+	start, end := len(h), -1
+	for i, sig := range h {
+		if sig == opts.Before {
+			start = i
+		}
+		if sig == opts.Until {
+			end = i
+		}
+	}
+	var batch []solanaSignatureInfo
+	for i := start - 1; i > end && len(batch) < opts.Limit; i-- {
+		batch = append(batch, solanaSignatureInfo{Signature: h[i]})
+	}
+	return batch
+}
+
+func TestSolanaGetTransactionsResumesPastPageLimit(t *testing.T) {
+	// More signatures above the cursor than one sync may page through
+	history := make(signatureHistory, solanaMaxSignaturePages*solanaSignaturePageSize+501)
+	for i := range history {
+		history[i] = fmt.Sprintf("sig%05d", i)
+	}
+
+	var fetched []string
+	server := newRPCStub(t, func(method string, params []json.RawMessage) (interface{}, *jsonRPCError) {
+		switch method {
+		case "getSignaturesForAddress":
+			return history.page(params), nil
+		case "getTransaction":
+			var sig string
+			_ = json.Unmarshal(params[0], &sig)
+			fetched = append(fetched, sig)
+			return map[string]interface{}{
+				"slot": 1,
+				"meta": map[string]interface{}{"fee": 0, "preBalances": []int{}, "postBalances": []int{}},
+			}, nil
+		default:
+			return nil, &jsonRPCError{Code: -32601, Message: "method not found"}
+		}
+	})
+	p := newTestSolanaProvider(server.URL)
+
+	cursor := history[0]
+	_, cursor, err := p.GetTransactions(context.Background(), "SOLANA", testSolanaOwner, cursor)
+	if err != nil {
+		t.Fatalf("GetTransactions: %v", err)
+	}
+	if len(fetched) != 0 || !strings.HasPrefix(cursor, history[0]+"/") {
+		t.Fatalf("capped sync fetched %d transactions and moved the cursor to %s", len(fetched), cursor)
+	}
+
+	// Sync until caught up
+	for syncs := 0; ; syncs++ {
+		if syncs > 100 {
+			t.Fatalf("cursor %s still moving after %d syncs", cursor, syncs)
+		}
+		_, next, err := p.GetTransactions(context.Background(), "SOLANA", testSolanaOwner, cursor)
+		if err != nil {
+			t.Fatalf("GetTransactions: %v", err)
+		}
+		if next == cursor {
+			break
+		}
+		cursor = next
+	}
+
+	if cursor != history[len(history)-1] {
+		t.Errorf("cursor = %s, want the newest signature %s", cursor, history[len(history)-1])
+	}
+	if len(fetched) != len(history)-1 {
+		t.Fatalf("fetched %d transactions, want %d", len(fetched), len(history)-1)
+	}
+	for i, sig := range fetched {
+		if sig != history[i+1] {
+			t.Fatalf("transaction %d is %s, want %s in oldest first order", i, sig, history[i+1])
+		}
+	}
+}
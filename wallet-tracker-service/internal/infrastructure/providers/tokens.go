@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TokenInfo describes a token the providers should query on a chain
+type TokenInfo struct {
+	Address     string `json:"address"`
+	Symbol      string `json:"symbol"`
+	Name        string `json:"name"`
+	Decimals    int    `json:"decimals"`
+	LogoURI     string `json:"logoURI,omitempty"`
+	CoingeckoID string `json:"coingeckoId,omitempty"`
+}
+
+// LoadTokenList reads a JSON file mapping chain keys to their token lists, e.g. {"ETH": [{...}]}
+func LoadTokenList(path string) (map[string][]TokenInfo, error) {
+	if path == "" {
+		return map[string][]TokenInfo{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token list: %w", err)
+	}
+
+	var raw map[string][]TokenInfo
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse token list: %w", err)
+	}
+
+	tokens := make(map[string][]TokenInfo, len(raw))
+	for chain, list := range raw {
+		tokens[strings.ToUpper(chain)] = list
+	}
+	return tokens, nil
+}
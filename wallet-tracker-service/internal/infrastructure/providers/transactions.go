@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/chains"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/config"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+// ErrTransactionsUnsupported is returned for chains without a transaction provider
+var ErrTransactionsUnsupported = errors.New("transaction history is not available for this chain")
+
+// ITransactionProvider fetches the transfers of a wallet incrementally.
+// GetTransactions returns the transfers after cursor (all known history when cursor is empty)
+// and the cursor to resume from next time. Transfers may be returned again; callers upsert them.
+type ITransactionProvider interface {
+	Name() string
+	GetTransactions(ctx context.Context, blockchain, address, cursor string) ([]entities.Transaction, string, error)
+}
+
+// TransactionRegistry routes transaction lookups to the provider of each chain
+type TransactionRegistry struct {
+	chainProviders map[string]ITransactionProvider
+}
+
+func NewTransactionRegistry() *TransactionRegistry {
+	return &TransactionRegistry{chainProviders: make(map[string]ITransactionProvider)}
+}
+
+// NewTransactionRegistryFromConfig wires EVM chains to the explorer API when an API key is set,
+// Solana chains with an RPC URL to the Solana provider and BTC to Esplora
+func NewTransactionRegistryFromConfig(conf *config.Config, chainRegistry *chains.Registry, logger *logs.Logger) (*TransactionRegistry, error) {
+	tokens, err := LoadTokenList(conf.TokenListPath)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := NewTransactionRegistry()
+	var evm *EVMTransactionProvider
+	for _, chain := range chainRegistry.All() {
+		switch {
+		case chain.EVMChainID != 0:
+			if conf.EtherscanAPIKey == "" {
+				continue
+			}
+			if evm == nil {
+				evm = NewEVMTransactionProvider(conf.EtherscanAPIURL, conf.EtherscanAPIKey, chainRegistry, tokens, logger)
+			}
+			registry.Register(chain.Key, evm)
+		case chain.AddressFormat == "solana":
+			if url := chainRegistry.RPCURL(chain.Key); url != "" {
+				registry.Register(chain.Key, NewSolanaProvider(chain, url, tokens[chain.Key], logger))
+			}
+		case chain.AddressFormat == "btc":
+			baseURL := conf.BTCEsploraURL
+			if baseURL == "" {
+				baseURL = chain.Provider.APIURL
+			}
+			registry.Register(chain.Key, NewBitcoinProvider(chain, baseURL, conf.BTCGapLimit, logger))
+		}
+	}
+	return registry, nil
+}
+
+// Register sets the transaction provider used for a blockchain
+func (r *TransactionRegistry) Register(blockchain string, p ITransactionProvider) {
+	r.chainProviders[strings.ToUpper(blockchain)] = p
+}
+
+// ProviderFor returns the transaction provider of a blockchain
+func (r *TransactionRegistry) ProviderFor(blockchain string) (ITransactionProvider, error) {
+	if p, ok := r.chainProviders[strings.ToUpper(blockchain)]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTransactionsUnsupported, blockchain)
+}
+
+func (r *TransactionRegistry) Name() string {
+	return "registry"
+}
+
+// GetTransactions dispatches to the provider configured for the blockchain
+func (r *TransactionRegistry) GetTransactions(ctx context.Context, blockchain, address, cursor string) ([]entities.Transaction, string, error) {
+	p, err := r.ProviderFor(blockchain)
+	if err != nil {
+		return nil, "", err
+	}
+	return p.GetTransactions(ctx, blockchain, address, cursor)
+}
+
+// transferDirection compares addresses case-insensitively, as explorers lower-case EVM addresses
+func transferDirection(wallet, from, to string) string {
+	fromWallet := strings.EqualFold(from, wallet)
+	toWallet := strings.EqualFold(to, wallet)
+	switch {
+	case fromWallet && toWallet:
+		return entities.DirectionSelf
+	case fromWallet:
+		return entities.DirectionOut
+	default:
+		return entities.DirectionIn
+	}
+}
@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IAlertRepository interface {
+	SaveRule(rule *entities.AlertRule) error
+	GetRule(userID string, id primitive.ObjectID) (*entities.AlertRule, error)
+	GetRulesByUser(userID string) ([]entities.AlertRule, error)
+	CountRulesByUser(userID string) (int64, error)
+	DeleteRule(userID string, id primitive.ObjectID) (bool, error)
+	GetEnabledRulesByWallet(blockchain, address string, types ...string) ([]entities.AlertRule, error)
+	GetEnabledRules(ruleType string) ([]entities.AlertRule, error)
+	SetRuleState(id primitive.ObjectID, active bool, triggeredAt *time.Time) error
+	SaveAlert(alert *entities.Alert) (bool, error)
+	GetAlerts(userID string, limit int64) ([]entities.Alert, error)
+}
+
+// AlertRepository stores the users' alert rules and the history of the alerts they raised
+type AlertRepository struct {
+	mongoClient     *dbmongo.MongoClient
+	dbName          string
+	collection      string
+	alertCollection string
+}
+
+func NewAlertRepository(mongoClient *dbmongo.MongoClient, dbName string) *AlertRepository {
+	return &AlertRepository{
+		mongoClient:     mongoClient,
+		dbName:          dbName,
+		collection:      "alert_rules",
+		alertCollection: "alerts",
+	}
+}
+
+// EnsureIndexes creates the wallet index the evaluator reads rules by, the history listing index
+// and the unique de-duplication index of alerts
+func (r *AlertRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "blockchain", Value: 1}, {Key: "address", Value: 1}, {Key: "enabled", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "createdAt", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	alerts := r.mongoClient.Client.Database(r.dbName).Collection(r.alertCollection)
+	_, err = alerts.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "dedupKey", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "triggeredAt", Value: -1}}},
+	})
+	return err
+}
+
+// SaveRule inserts a new rule or replaces an existing one of the same user
+func (r *AlertRepository) SaveRule(rule *entities.AlertRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rule.UpdatedAt = time.Now()
+	if rule.ID.IsZero() {
+		rule.ID = primitive.NewObjectID()
+	}
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"_id":     rule.ID,
+		"user_id": rule.UserID,
+	}
+
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := collection.ReplaceOne(ctx, filter, rule, opts)
+	return err
+}
+
+func (r *AlertRepository) GetRule(userID string, id primitive.ObjectID) (*entities.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	var rule entities.AlertRule
+	err := collection.FindOne(ctx, bson.M{"_id": id, "user_id": userID}).Decode(&rule)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *AlertRepository) GetRulesByUser(userID string) ([]entities.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules := []entities.AlertRule{}
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *AlertRepository) CountRulesByUser(userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+	return collection.CountDocuments(ctx, bson.M{"user_id": userID})
+}
+
+// DeleteRule removes a rule and reports whether it existed. Its alert history is kept.
+func (r *AlertRepository) DeleteRule(userID string, id primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// GetEnabledRulesByWallet returns the enabled rules of every user on a wallet, restricted to the
+// given types when any are given
+func (r *AlertRepository) GetEnabledRulesByWallet(blockchain, address string, types ...string) ([]entities.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"blockchain": blockchain,
+		"address":    address,
+		"enabled":    true,
+	}
+	if len(types) > 0 {
+		filter["type"] = bson.M{"$in": types}
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []entities.AlertRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetEnabledRules returns the enabled rules of one type on every wallet
+func (r *AlertRepository) GetEnabledRules(ruleType string) ([]entities.AlertRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	cursor, err := collection.Find(ctx, bson.M{"type": ruleType, "enabled": true})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []entities.AlertRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// SetRuleState records the evaluation state of a rule, and when it last raised an alert if triggeredAt is set
+func (r *AlertRepository) SetRuleState(id primitive.ObjectID, active bool, triggeredAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	set := bson.M{"active": active}
+	if triggeredAt != nil {
+		set["lastTriggeredAt"] = *triggeredAt
+	}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	return err
+}
+
+// SaveAlert records an alert and reports whether it is new; an alert with the same dedup key is left alone
+func (r *AlertRepository) SaveAlert(alert *entities.Alert) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if alert.ID.IsZero() {
+		alert.ID = primitive.NewObjectID()
+	}
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.alertCollection)
+
+	_, err := collection.InsertOne(ctx, alert)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetAlerts returns the user's most recent alerts, newest first
+func (r *AlertRepository) GetAlerts(userID string, limit int64) ([]entities.Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.alertCollection)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "triggeredAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	alerts := []entities.Alert{}
+	if err = cursor.All(ctx, &alerts); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
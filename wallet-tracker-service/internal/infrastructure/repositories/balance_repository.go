@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
@@ -14,6 +15,7 @@ import (
 type IBalanceRepository interface {
 	SaveBalances(balances *entities.WalletBalances) error
 	GetBalancesByWallet(blockchain, address string) (*entities.WalletBalances, error)
+	AggregateBalances(wallets []entities.WalletRef) (*entities.PortfolioSummary, error)
 }
 
 type BalanceRepository struct {
@@ -70,4 +72,121 @@ func (r *BalanceRepository) GetBalancesByWallet(blockchain, address string) (*en
 	}
 	
 	return &balances, nil
-} 
\ No newline at end of file
+}
+
+type tokenGroup struct {
+	ID struct {
+		Blockchain string `bson:"blockchain"`
+	} `bson:"_id"`
+	Asset    entities.Asset       `bson:"asset"`
+	Amounts  []entities.RawAmount `bson:"amounts"`
+	USDValue entities.USD         `bson:"usdValue"`
+	Wallets  int                  `bson:"wallets"`
+}
+
+type balanceTotals struct {
+	USDValue  entities.USD `bson:"usdValue"`
+	UpdatedAt time.Time    `bson:"updatedAt"`
+}
+
+type balanceFacets struct {
+	Tokens []tokenGroup          `bson:"tokens"`
+	Chains []entities.ChainTotal `bson:"chains"`
+	Totals []balanceTotals       `bson:"totals"`
+}
+
+// AggregateBalances sums the stored balances of the given wallets in a single aggregation:
+// the total, a per-chain breakdown and tokens merged per chain and contract.
+// Raw amounts are strings in Mongo, so they are collected here and added up with big integers.
+func (r *BalanceRepository) AggregateBalances(wallets []entities.WalletRef) (*entities.PortfolioSummary, error) {
+	summary := &entities.PortfolioSummary{
+		Chains: []entities.ChainTotal{},
+		Tokens: []entities.PortfolioToken{},
+	}
+	if len(wallets) == 0 {
+		return summary, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	match := make(bson.A, 0, len(wallets))
+	for _, w := range wallets {
+		match = append(match, bson.M{"blockchain": w.Blockchain, "address": w.Address})
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"$or": match}}},
+		bson.D{{Key: "$unwind", Value: "$balances"}},
+		bson.D{{Key: "$facet", Value: bson.M{
+			"tokens": bson.A{
+				bson.M{"$group": bson.M{
+					"_id": bson.M{
+						"blockchain": "$blockchain",
+						"contract":   "$balances.asset.address",
+						"symbol":     "$balances.asset.symbol",
+					},
+					"asset":    bson.M{"$first": "$balances.asset"},
+					"amounts":  bson.M{"$push": "$balances.amount"},
+					"usdValue": bson.M{"$sum": "$balances.usdValue"},
+					"wallets":  bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"usdValue": -1}},
+			},
+			"chains": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":      "$blockchain",
+					"usdValue": bson.M{"$sum": "$balances.usdValue"},
+					"wallets":  bson.M{"$addToSet": "$address"},
+				}},
+				bson.M{"$project": bson.M{"usdValue": 1, "wallets": bson.M{"$size": "$wallets"}}},
+				bson.M{"$sort": bson.M{"usdValue": -1}},
+			},
+			"totals": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":       nil,
+					"usdValue":  bson.M{"$sum": "$balances.usdValue"},
+					"updatedAt": bson.M{"$max": "$updatedAt"},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []balanceFacets
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+	if len(results) == 0 {
+		return summary, nil
+	}
+	facets := results[0]
+
+	if len(facets.Totals) > 0 {
+		summary.TotalUSD = facets.Totals[0].USDValue
+		summary.UpdatedAt = facets.Totals[0].UpdatedAt
+	}
+	summary.Chains = append(summary.Chains, facets.Chains...)
+	for _, group := range facets.Tokens {
+		var amount entities.RawAmount
+		for _, a := range group.Amounts {
+			amount = amount.Add(a)
+		}
+		balance := entities.NewBalance(group.Asset, amount)
+		balance.USDValue = group.USDValue
+		summary.Tokens = append(summary.Tokens, entities.PortfolioToken{
+			Blockchain: group.ID.Blockchain,
+			Balance:    balance,
+			Wallets:    group.Wallets,
+		})
+	}
+
+	return summary, nil
+}
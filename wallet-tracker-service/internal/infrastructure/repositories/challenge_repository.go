@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IChallengeRepository interface {
+	SaveChallenge(challenge *entities.WalletChallenge) error
+	ConsumeChallenge(userID, blockchain, address string, now time.Time) (*entities.WalletChallenge, error)
+}
+
+type ChallengeRepository struct {
+	mongoClient *dbmongo.MongoClient
+	dbName      string
+	collection  string
+}
+
+func NewChallengeRepository(mongoClient *dbmongo.MongoClient, dbName string) *ChallengeRepository {
+	return &ChallengeRepository{
+		mongoClient: mongoClient,
+		dbName:      dbName,
+		collection:  "wallet_challenges",
+	}
+}
+
+// SaveChallenge stores the challenge, replacing any earlier one for the same wallet
+func (r *ChallengeRepository) SaveChallenge(challenge *entities.WalletChallenge) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"user_id":    challenge.UserID,
+		"blockchain": challenge.Blockchain,
+		"address":    challenge.Address,
+	}
+
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := collection.ReplaceOne(ctx, filter, challenge, opts)
+	return err
+}
+
+// ConsumeChallenge removes and returns the wallet's unexpired challenge, or nil when there is none.
+// Removal and lookup are one operation, so concurrent calls cannot both get the challenge.
+func (r *ChallengeRepository) ConsumeChallenge(userID, blockchain, address string, now time.Time) (*entities.WalletChallenge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"user_id":    userID,
+		"blockchain": blockchain,
+		"address":    address,
+		"expiresAt":  bson.M{"$gt": now},
+	}
+
+	var challenge entities.WalletChallenge
+	err := collection.FindOneAndDelete(ctx, filter).Decode(&challenge)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &challenge, nil
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MigrateEVMAddresses rewrites wallets stored before EVM addresses were EIP-55 checksummed
+func (r *WalletRepository) MigrateEVMAddresses(blockchains []string) (int64, error) {
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+	return checksumEVMAddresses(collection, blockchains, []string{"user_id", "blockchain"})
+}
+
+// MigrateEVMAddresses rewrites balance records stored before EVM addresses were EIP-55 checksummed
+func (r *BalanceRepository) MigrateEVMAddresses(blockchains []string) (int64, error) {
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+	return checksumEVMAddresses(collection, blockchains, []string{"blockchain"})
+}
+
+// checksumEVMAddresses sets the EIP-55 form on every document of the EVM chains whose address is
+// stored in another case, and returns how many were rewritten. Documents are unique by keyFields
+// plus the address; a refresh since the upgrade may already have written the checksummed twin of
+// a legacy document, in which case the twin is dropped so the legacy document keeps its creation
+// date and user fields. The next refresh brings its balances up to date.
+func checksumEVMAddresses(collection *mongo.Collection, blockchains []string, keyFields []string) (int64, error) {
+	if len(blockchains) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	filter := bson.M{
+		"blockchain": bson.M{"$in": blockchains},
+		"address":    bson.M{"$regex": "^0[xX][0-9a-fA-F]{40}$"},
+	}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list EVM addresses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var migrated int64
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return migrated, err
+		}
+		address, _ := doc["address"].(string)
+		checksummed := addresses.ToChecksumAddress(address)
+		if address == checksummed {
+			continue
+		}
+
+		twin := bson.M{"_id": bson.M{"$ne": doc["_id"]}, "address": checksummed}
+		for _, field := range keyFields {
+			twin[field] = doc[field]
+		}
+		if _, err := collection.DeleteMany(ctx, twin); err != nil {
+			return migrated, fmt.Errorf("failed to drop checksummed duplicate of %s: %w", address, err)
+		}
+
+		update := bson.M{"$set": bson.M{"address": checksummed}}
+		if _, err := collection.UpdateByID(ctx, doc["_id"], update); err != nil {
+			return migrated, fmt.Errorf("failed to checksum address %s: %w", address, err)
+		}
+		migrated++
+	}
+	return migrated, cursor.Err()
+}
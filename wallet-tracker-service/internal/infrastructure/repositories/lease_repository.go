@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ILeaseRepository interface {
+	AcquireLease(name, holder string, ttl time.Duration) (*entities.Lease, bool, error)
+	ReleaseLease(name, holder string) error
+}
+
+type LeaseRepository struct {
+	mongoClient *dbmongo.MongoClient
+	dbName      string
+	collection  string
+}
+
+func NewLeaseRepository(mongoClient *dbmongo.MongoClient, dbName string) *LeaseRepository {
+	return &LeaseRepository{
+		mongoClient: mongoClient,
+		dbName:      dbName,
+		collection:  "leases",
+	}
+}
+
+// AcquireLease takes the named lease for ttl when it is free, expired or already held by holder.
+// It returns the lease as it now stands and whether holder got it.
+func (r *LeaseRepository) AcquireLease(name, holder string, ttl time.Duration) (*entities.Lease, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	now := time.Now()
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"expiresAt": bson.M{"$lte": now}},
+			bson.M{"holder": holder},
+		},
+	}
+	update := bson.M{"$set": bson.M{"holder": holder, "acquiredAt": now, "expiresAt": now.Add(ttl)}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var lease entities.Lease
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&lease)
+	if err == nil {
+		return &lease, true, nil
+	}
+	// A live lease of another holder makes the upsert collide with its _id
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false, err
+	}
+
+	err = collection.FindOne(ctx, bson.M{"_id": name}).Decode(&lease)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Released in between; the next run will take it
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &lease, false, nil
+}
+
+// ReleaseLease frees the named lease if holder still holds it
+func (r *LeaseRepository) ReleaseLease(name, holder string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": name, "holder": holder})
+	return err
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IPortfolioRepository interface {
+	SavePortfolio(portfolio *entities.Portfolio) error
+	GetPortfolio(userID string, id primitive.ObjectID) (*entities.Portfolio, error)
+	GetPortfoliosByUser(userID string) ([]entities.Portfolio, error)
+	DeletePortfolio(userID string, id primitive.ObjectID) (bool, error)
+}
+
+type PortfolioRepository struct {
+	mongoClient *dbmongo.MongoClient
+	dbName      string
+	collection  string
+}
+
+func NewPortfolioRepository(mongoClient *dbmongo.MongoClient, dbName string) *PortfolioRepository {
+	return &PortfolioRepository{
+		mongoClient: mongoClient,
+		dbName:      dbName,
+		collection:  "portfolios",
+	}
+}
+
+// SavePortfolio inserts a new portfolio or replaces an existing one of the same user
+func (r *PortfolioRepository) SavePortfolio(portfolio *entities.Portfolio) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	portfolio.UpdatedAt = time.Now()
+	if portfolio.ID.IsZero() {
+		portfolio.ID = primitive.NewObjectID()
+	}
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"_id":     portfolio.ID,
+		"user_id": portfolio.UserID,
+	}
+
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := collection.ReplaceOne(ctx, filter, portfolio, opts)
+	return err
+}
+
+func (r *PortfolioRepository) GetPortfolio(userID string, id primitive.ObjectID) (*entities.Portfolio, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"_id":     id,
+		"user_id": userID,
+	}
+
+	var portfolio entities.Portfolio
+	err := collection.FindOne(ctx, filter).Decode(&portfolio)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &portfolio, nil
+}
+
+func (r *PortfolioRepository) GetPortfoliosByUser(userID string) ([]entities.Portfolio, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	portfolios := []entities.Portfolio{}
+	if err = cursor.All(ctx, &portfolios); err != nil {
+		return nil, err
+	}
+
+	return portfolios, nil
+}
+
+// DeletePortfolio removes a portfolio and reports whether it existed. The wallets stay tracked.
+func (r *PortfolioRepository) DeletePortfolio(userID string, id primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
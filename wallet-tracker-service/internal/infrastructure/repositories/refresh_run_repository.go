@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// refreshRunRetention is how long refresh run metrics are kept
+const refreshRunRetention = 30 * 24 * time.Hour
+
+type IRefreshRunRepository interface {
+	SaveRun(run *entities.RefreshRun) error
+	GetRecentRuns(limit int64) ([]entities.RefreshRun, error)
+}
+
+type RefreshRunRepository struct {
+	mongoClient *dbmongo.MongoClient
+	dbName      string
+	collection  string
+}
+
+func NewRefreshRunRepository(mongoClient *dbmongo.MongoClient, dbName string) *RefreshRunRepository {
+	return &RefreshRunRepository{
+		mongoClient: mongoClient,
+		dbName:      dbName,
+		collection:  "refresh_runs",
+	}
+}
+
+// EnsureIndexes creates the TTL index that expires old runs, which also serves the listing
+func (r *RefreshRunRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "startedAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(refreshRunRetention / time.Second)),
+	})
+	return err
+}
+
+// SaveRun inserts a run or replaces it with its latest counters
+func (r *RefreshRunRepository) SaveRun(run *entities.RefreshRun) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if run.ID.IsZero() {
+		run.ID = primitive.NewObjectID()
+	}
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": run.ID}, run, opts)
+	return err
+}
+
+// GetRecentRuns returns the latest runs of every replica, newest first
+func (r *RefreshRunRepository) GetRecentRuns(limit int64) ([]entities.RefreshRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "startedAt", Value: -1}}).SetLimit(limit)
+
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []entities.RefreshRun{}
+	if err = cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ISettingsRepository interface {
+	GetSettings(userID string) (*entities.UserSettings, error)
+	SaveSettings(settings *entities.UserSettings) error
+}
+
+// SettingsRepository stores one settings document per user
+type SettingsRepository struct {
+	mongoClient *dbmongo.MongoClient
+	dbName      string
+	collection  string
+}
+
+func NewSettingsRepository(mongoClient *dbmongo.MongoClient, dbName string) *SettingsRepository {
+	return &SettingsRepository{
+		mongoClient: mongoClient,
+		dbName:      dbName,
+		collection:  "user_settings",
+	}
+}
+
+func (r *SettingsRepository) GetSettings(userID string) (*entities.UserSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	var settings entities.UserSettings
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// SaveSettings creates or replaces the settings of the user
+func (r *SettingsRepository) SaveSettings(settings *entities.UserSettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	settings.UpdatedAt = time.Now()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	update := bson.M{"$set": bson.M{
+		"costBasisMethod": settings.CostBasisMethod,
+		"updatedAt":       settings.UpdatedAt,
+	}}
+	opts := options.Update().SetUpsert(true)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"user_id": settings.UserID}, update, opts)
+	return err
+}
@@ -0,0 +1,414 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ISnapshotRepository interface {
+	SaveSnapshot(snapshot *entities.BalanceSnapshot) error
+	GetSnapshotAt(blockchain, address string, at time.Time) (*entities.BalanceSnapshot, error)
+	GetSnapshots(blockchain, address string, from, to time.Time) ([]entities.BalanceSnapshot, error)
+	GetValueSeries(wallets []entities.WalletRef, from, to time.Time, bucket string) ([]entities.ValuePoint, error)
+	RollupSnapshots(fromResolution, toResolution string, before time.Time) (int, int64, error)
+}
+
+// Series bucket sizes, named after the $dateTrunc units
+const (
+	BucketHour = "hour"
+	BucketDay  = "day"
+	BucketWeek = "week"
+)
+
+// BucketStart truncates t to the start of its UTC bucket; weeks start on Monday like in the series query
+func BucketStart(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	switch bucket {
+	case BucketHour:
+		return t.Truncate(time.Hour)
+	case BucketWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// BucketNext returns the start of the bucket after the one starting at start
+func BucketNext(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketHour:
+		return start.Add(time.Hour)
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// SnapshotRepository stores the append-only history of wallet balances
+type SnapshotRepository struct {
+	mongoClient *dbmongo.MongoClient
+	dbName      string
+	collection  string
+}
+
+func NewSnapshotRepository(mongoClient *dbmongo.MongoClient, dbName string) *SnapshotRepository {
+	return &SnapshotRepository{
+		mongoClient: mongoClient,
+		dbName:      dbName,
+		collection:  "balance_snapshots",
+	}
+}
+
+// EnsureIndexes creates the chain+address+timestamp index used by point-in-time and range queries,
+// the resolution+timestamp index used by compaction, and the unique wallet+resolution+bucket index
+// rollups are upserted by. Duplicate rollups left by earlier concurrent runs are removed first.
+func (r *SnapshotRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	if err := removeDuplicateRollups(ctx, collection); err != nil {
+		return err
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{
+			{Key: "blockchain", Value: 1},
+			{Key: "address", Value: 1},
+			{Key: "timestamp", Value: -1},
+		}},
+		{Keys: bson.D{
+			{Key: "resolution", Value: 1},
+			{Key: "timestamp", Value: 1},
+		}},
+		{
+			Keys: bson.D{
+				{Key: "blockchain", Value: 1},
+				{Key: "address", Value: 1},
+				{Key: "resolution", Value: 1},
+				{Key: "bucket", Value: 1},
+			},
+			// Raw snapshots have no bucket and may share every other key
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"bucket": bson.M{"$exists": true}}),
+		},
+	})
+	return err
+}
+
+// removeDuplicateRollups keeps one rollup per wallet, resolution and bucket
+func removeDuplicateRollups(ctx context.Context, collection *mongo.Collection) error {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"bucket": bson.M{"$exists": true}}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"blockchain": "$blockchain",
+				"address":    "$address",
+				"resolution": "$resolution",
+				"bucket":     "$bucket",
+			},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		bson.D{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return fmt.Errorf("aggregate error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var group struct {
+			IDs []interface{} `bson:"ids"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return fmt.Errorf("cursor error: %w", err)
+		}
+		// Duplicates were written from the same sources, so any one of them can stay
+		if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": group.IDs[1:]}}); err != nil {
+			return fmt.Errorf("delete error: %w", err)
+		}
+	}
+	return cursor.Err()
+}
+
+// SaveSnapshot appends a snapshot; existing snapshots are never replaced
+func (r *SnapshotRepository) SaveSnapshot(snapshot *entities.BalanceSnapshot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now()
+	}
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	_, err := collection.InsertOne(ctx, snapshot)
+	return err
+}
+
+// GetSnapshotAt returns the latest snapshot taken at or before the given time
+func (r *SnapshotRepository) GetSnapshotAt(blockchain, address string, at time.Time) (*entities.BalanceSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"blockchain": blockchain,
+		"address":    address,
+		"timestamp":  bson.M{"$lte": at},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	var snapshot entities.BalanceSnapshot
+	err := collection.FindOne(ctx, filter, opts).Decode(&snapshot)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// GetSnapshots returns the wallet's snapshots taken between from and to, oldest first.
+// Compacted history comes back as one snapshot per hour or day.
+func (r *SnapshotRepository) GetSnapshots(blockchain, address string, from, to time.Time) ([]entities.BalanceSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"blockchain": blockchain,
+		"address":    address,
+		"timestamp":  bson.M{"$gte": from, "$lte": to},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	snapshots := []entities.BalanceSnapshot{}
+	if err = cursor.All(ctx, &snapshots); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// GetValueSeries returns the combined USD value of the wallets per bucket between from and to.
+// Each wallet contributes its last value in a bucket; empty buckets carry the wallet's last known
+// value forward, seeded with its latest snapshot before from. Requires MongoDB 5.3+ ($densify, $fill).
+func (r *SnapshotRepository) GetValueSeries(wallets []entities.WalletRef, from, to time.Time, bucket string) ([]entities.ValuePoint, error) {
+	if len(wallets) == 0 {
+		return []entities.ValuePoint{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	walletMatch := make(bson.A, 0, len(wallets))
+	for _, w := range wallets {
+		walletMatch = append(walletMatch, bson.M{"blockchain": w.Blockchain, "address": w.Address})
+	}
+	first := BucketStart(from, bucket)
+	end := BucketNext(BucketStart(to, bucket), bucket)
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{
+			"$or":       walletMatch,
+			"timestamp": bson.M{"$gte": from, "$lte": to},
+		}}},
+		// Value of each wallet when the range starts
+		bson.D{{Key: "$unionWith", Value: bson.M{
+			"coll": r.collection,
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$or": walletMatch, "timestamp": bson.M{"$lt": from}}},
+				bson.M{"$sort": bson.M{"timestamp": -1}},
+				bson.M{"$group": bson.M{
+					"_id":      bson.M{"blockchain": "$blockchain", "address": "$address"},
+					"totalUsd": bson.M{"$first": "$totalUsd"},
+				}},
+				bson.M{"$project": bson.M{
+					"_id":        0,
+					"blockchain": "$_id.blockchain",
+					"address":    "$_id.address",
+					"totalUsd":   1,
+					"timestamp":  from,
+				}},
+			},
+		}}},
+		bson.D{{Key: "$set", Value: bson.M{
+			"bucket": bson.M{"$dateTrunc": bson.M{"date": "$timestamp", "unit": bucket, "startOfWeek": "monday"}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.M{"timestamp": 1}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"wallet": bson.M{"$concat": bson.A{"$blockchain", ".", "$address"}},
+				"bucket": "$bucket",
+			},
+			"usdValue": bson.M{"$last": "$totalUsd"},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"wallet":   "$_id.wallet",
+			"bucket":   "$_id.bucket",
+			"usdValue": 1,
+		}}},
+		bson.D{{Key: "$densify", Value: bson.M{
+			"field":             "bucket",
+			"partitionByFields": bson.A{"wallet"},
+			"range":             bson.M{"step": 1, "unit": bucket, "bounds": bson.A{first, end}},
+		}}},
+		bson.D{{Key: "$fill", Value: bson.M{
+			"partitionByFields": bson.A{"wallet"},
+			"sortBy":            bson.M{"bucket": 1},
+			"output":            bson.M{"usdValue": bson.M{"method": "locf"}},
+		}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":      "$bucket",
+			"usdValue": bson.M{"$sum": "$usdValue"},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	points := []entities.ValuePoint{}
+	if err = cursor.All(ctx, &points); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return points, nil
+}
+
+// rollupBatchSize is the number of rollup upserts sent per bulk write
+const rollupBatchSize = 500
+
+// RollupSnapshots replaces the snapshots of fromResolution taken before the cutoff with one
+// snapshot per wallet and toResolution bucket ("hour" or "day"). before should be a bucket
+// boundary so no bucket is rolled up while it can still receive snapshots. Rollups are upserted
+// by wallet and bucket before the sources are deleted, so an interrupted run can be repeated.
+// Returns the number of rollups written and of source snapshots removed.
+func (r *SnapshotRepository) RollupSnapshots(fromResolution, toResolution string, before time.Time) (int, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	// Raw snapshots predate the resolution field, so match it missing as well
+	var resolutionFilter interface{} = fromResolution
+	if fromResolution == entities.ResolutionRaw {
+		resolutionFilter = bson.M{"$in": bson.A{nil, ""}}
+	}
+	sourceFilter := bson.M{
+		"resolution": resolutionFilter,
+		"timestamp":  bson.M{"$lt": before},
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: sourceFilter}},
+		bson.D{{Key: "$sort", Value: bson.M{"timestamp": 1}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"blockchain": "$blockchain",
+				"address":    "$address",
+				"bucket":     bson.M{"$dateTrunc": bson.M{"date": "$timestamp", "unit": toResolution}},
+			},
+			"timestamp": bson.M{"$last": "$timestamp"},
+			"totalUsd":  bson.M{"$last": "$totalUsd"},
+			"balances":  bson.M{"$last": "$balances"},
+			"minUsd":    bson.M{"$min": bson.M{"$ifNull": bson.A{"$minUsd", "$totalUsd"}}},
+			"maxUsd":    bson.M{"$max": bson.M{"$ifNull": bson.A{"$maxUsd", "$totalUsd"}}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"blockchain": "$_id.blockchain",
+			"address":    "$_id.address",
+			"bucket":     "$_id.bucket",
+			"resolution": toResolution,
+			"timestamp":  1,
+			"totalUsd":   1,
+			"balances":   1,
+			"minUsd":     1,
+			"maxUsd":     1,
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return 0, 0, fmt.Errorf("aggregate error: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	written := 0
+	var batch []mongo.WriteModel
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := collection.BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("rollup write error: %w", err)
+		}
+		written += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var rollup bson.M
+		if err := cursor.Decode(&rollup); err != nil {
+			return written, 0, fmt.Errorf("cursor error: %w", err)
+		}
+		filter := bson.M{
+			"blockchain": rollup["blockchain"],
+			"address":    rollup["address"],
+			"resolution": toResolution,
+			"bucket":     rollup["bucket"],
+		}
+		batch = append(batch, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(rollup).SetUpsert(true))
+		if len(batch) >= rollupBatchSize {
+			if err := flush(); err != nil {
+				return written, 0, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return written, 0, fmt.Errorf("cursor error: %w", err)
+	}
+	if err := flush(); err != nil {
+		return written, 0, err
+	}
+
+	res, err := collection.DeleteMany(ctx, sourceFilter)
+	if err != nil {
+		return written, 0, fmt.Errorf("delete error: %w", err)
+	}
+	return written, res.DeletedCount, nil
+}
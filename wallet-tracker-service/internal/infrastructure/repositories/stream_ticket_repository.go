@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IStreamTicketRepository interface {
+	SaveTicket(ticket *entities.StreamTicket) error
+	ConsumeTicket(id string, now time.Time) (*entities.StreamTicket, error)
+}
+
+type StreamTicketRepository struct {
+	mongoClient *dbmongo.MongoClient
+	dbName      string
+	collection  string
+}
+
+func NewStreamTicketRepository(mongoClient *dbmongo.MongoClient, dbName string) *StreamTicketRepository {
+	return &StreamTicketRepository{
+		mongoClient: mongoClient,
+		dbName:      dbName,
+		collection:  "stream_tickets",
+	}
+}
+
+// EnsureIndexes creates the TTL index that removes tickets nobody redeemed
+func (r *StreamTicketRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (r *StreamTicketRepository) SaveTicket(ticket *entities.StreamTicket) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	_, err := collection.InsertOne(ctx, ticket)
+	return err
+}
+
+// ConsumeTicket removes and returns the unexpired ticket with the given ID, or nil when there is none.
+// Removal and lookup are one operation, so a ticket opens at most one stream.
+func (r *StreamTicketRepository) ConsumeTicket(id string, now time.Time) (*entities.StreamTicket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"_id":       id,
+		"expiresAt": bson.M{"$gt": now},
+	}
+
+	var ticket entities.StreamTicket
+	err := collection.FindOneAndDelete(ctx, filter).Decode(&ticket)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &ticket, nil
+}
@@ -0,0 +1,395 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ITransactionRepository interface {
+	SaveTransactions(txs []entities.Transaction) (int64, error)
+	GetTransactions(wallets []entities.WalletRef, filter TransactionFilter) ([]entities.Transaction, error)
+	GetTransactionHistory(wallets []entities.WalletRef, to time.Time) ([]entities.Transaction, error)
+	GetUnlabeledTransactions(blockchain, address string, limit int) ([]entities.Transaction, error)
+	SetLabel(blockchain, address, hash, label, protocol string) error
+	GetCursor(blockchain, address string) (*entities.TransactionCursor, error)
+	SaveCursor(cursor *entities.TransactionCursor) error
+	GetManualLabels(userID string, wallets []entities.WalletRef, hashes []string) ([]entities.TransactionLabel, error)
+	SaveManualLabel(label *entities.TransactionLabel) error
+	DeleteManualLabel(userID, blockchain, address, hash string) error
+}
+
+// TransactionFilter selects a page of transactions, newest first.
+// Zero values leave a criterion out; After continues from the last transaction of the previous page.
+type TransactionFilter struct {
+	Token     string // symbol or contract address, case-insensitive
+	Direction string
+	From      time.Time
+	To        time.Time
+	After     *TransactionPageKey
+	Limit     int
+}
+
+// TransactionPageKey is the sort position of a transaction
+type TransactionPageKey struct {
+	Timestamp time.Time
+	ID        primitive.ObjectID
+}
+
+// TransactionRepository stores ingested transfers, the ingestion cursor of each wallet
+// and the labels users set on their transactions
+type TransactionRepository struct {
+	mongoClient      *dbmongo.MongoClient
+	dbName           string
+	collection       string
+	cursorCollection string
+	labelCollection  string
+}
+
+func NewTransactionRepository(mongoClient *dbmongo.MongoClient, dbName string) *TransactionRepository {
+	return &TransactionRepository{
+		mongoClient:      mongoClient,
+		dbName:           dbName,
+		collection:       "transactions",
+		cursorCollection: "transaction_cursors",
+		labelCollection:  "transaction_labels",
+	}
+}
+
+// EnsureIndexes creates the unique leg index that makes ingestion idempotent and the listing index
+func (r *TransactionRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "blockchain", Value: 1},
+				{Key: "address", Value: 1},
+				{Key: "hash", Value: 1},
+				{Key: "legId", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{
+			{Key: "blockchain", Value: 1},
+			{Key: "address", Value: 1},
+			{Key: "timestamp", Value: -1},
+			{Key: "_id", Value: -1},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	cursors := r.mongoClient.Client.Database(r.dbName).Collection(r.cursorCollection)
+	_, err = cursors.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "blockchain", Value: 1}, {Key: "address", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	labels := r.mongoClient.Client.Database(r.dbName).Collection(r.labelCollection)
+	_, err = labels.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "user_id", Value: 1},
+			{Key: "blockchain", Value: 1},
+			{Key: "address", Value: 1},
+			{Key: "hash", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// SaveTransactions upserts transfers by wallet, hash and leg, and returns how many were new
+func (r *TransactionRepository) SaveTransactions(txs []entities.Transaction) (int64, error) {
+	if len(txs) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	models := make([]mongo.WriteModel, 0, len(txs))
+	for i := range txs {
+		tx := txs[i]
+		tx.ID = primitive.NilObjectID
+		filter := bson.M{
+			"blockchain": tx.Blockchain,
+			"address":    tx.Address,
+			"hash":       tx.Hash,
+			"legId":      tx.LegID,
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(filter).
+			SetUpdate(bson.M{"$set": tx}).
+			SetUpsert(true))
+	}
+
+	res, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, err
+	}
+	return res.UpsertedCount, nil
+}
+
+// GetTransactions returns one page of the wallets' transactions, newest first
+func (r *TransactionRepository) GetTransactions(wallets []entities.WalletRef, filter TransactionFilter) ([]entities.Transaction, error) {
+	if len(wallets) == 0 {
+		return []entities.Transaction{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	walletMatch := make(bson.A, 0, len(wallets))
+	for _, w := range wallets {
+		walletMatch = append(walletMatch, bson.M{"blockchain": w.Blockchain, "address": w.Address})
+	}
+	conditions := bson.A{bson.M{"$or": walletMatch}}
+
+	if filter.Token != "" {
+		pattern := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(filter.Token) + "$", Options: "i"}
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"asset.symbol": pattern},
+			bson.M{"asset.address": pattern},
+		}})
+	}
+	if filter.Direction != "" {
+		conditions = append(conditions, bson.M{"direction": filter.Direction})
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, bson.M{"timestamp": bson.M{"$gte": filter.From}})
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, bson.M{"timestamp": bson.M{"$lte": filter.To}})
+	}
+	if filter.After != nil {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"timestamp": bson.M{"$lt": filter.After.Timestamp}},
+			bson.M{"timestamp": filter.After.Timestamp, "_id": bson.M{"$lt": filter.After.ID}},
+		}})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(filter.Limit))
+
+	cursor, err := collection.Find(ctx, bson.M{"$and": conditions}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	txs := []entities.Transaction{}
+	if err = cursor.All(ctx, &txs); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// GetTransactionHistory returns every transaction of the wallets before to, oldest first
+func (r *TransactionRepository) GetTransactionHistory(wallets []entities.WalletRef, to time.Time) ([]entities.Transaction, error) {
+	if len(wallets) == 0 {
+		return []entities.Transaction{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	walletMatch := make(bson.A, 0, len(wallets))
+	for _, w := range wallets {
+		walletMatch = append(walletMatch, bson.M{"blockchain": w.Blockchain, "address": w.Address})
+	}
+	filter := bson.M{
+		"$or":       walletMatch,
+		"timestamp": bson.M{"$lt": to},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	txs := []entities.Transaction{}
+	if err = cursor.All(ctx, &txs); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// GetUnlabeledTransactions returns every leg of up to limit transactions the classifier has not labeled yet
+func (r *TransactionRepository) GetUnlabeledTransactions(blockchain, address string, limit int) ([]entities.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	unlabeled := bson.M{"blockchain": blockchain, "address": address, "label": bson.M{"$exists": false}}
+	hashes, err := collection.Distinct(ctx, "hash", unlabeled)
+	if err != nil {
+		return nil, err
+	}
+	if len(hashes) == 0 {
+		return []entities.Transaction{}, nil
+	}
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+
+	filter := bson.M{"blockchain": blockchain, "address": address, "hash": bson.M{"$in": hashes}}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	txs := []entities.Transaction{}
+	if err = cursor.All(ctx, &txs); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// SetLabel sets the classifier's label on every leg of a transaction
+func (r *TransactionRepository) SetLabel(blockchain, address, hash, label, protocol string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{"blockchain": blockchain, "address": address, "hash": hash}
+	update := bson.M{"$set": bson.M{"label": label, "protocol": protocol}}
+	if protocol == "" {
+		update = bson.M{"$set": bson.M{"label": label}, "$unset": bson.M{"protocol": ""}}
+	}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func (r *TransactionRepository) GetCursor(blockchain, address string) (*entities.TransactionCursor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.cursorCollection)
+
+	var cursor entities.TransactionCursor
+	err := collection.FindOne(ctx, bson.M{"blockchain": blockchain, "address": address}).Decode(&cursor)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+func (r *TransactionRepository) SaveCursor(cursor *entities.TransactionCursor) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor.SyncedAt = time.Now()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.cursorCollection)
+
+	filter := bson.M{"blockchain": cursor.Blockchain, "address": cursor.Address}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := collection.ReplaceOne(ctx, filter, cursor, opts)
+	return err
+}
+
+// GetManualLabels returns the user's labels for the given transactions of their wallets
+func (r *TransactionRepository) GetManualLabels(userID string, wallets []entities.WalletRef, hashes []string) ([]entities.TransactionLabel, error) {
+	if len(wallets) == 0 || len(hashes) == 0 {
+		return []entities.TransactionLabel{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.labelCollection)
+
+	walletMatch := make(bson.A, 0, len(wallets))
+	for _, w := range wallets {
+		walletMatch = append(walletMatch, bson.M{"blockchain": w.Blockchain, "address": w.Address})
+	}
+	filter := bson.M{
+		"user_id": userID,
+		"hash":    bson.M{"$in": hashes},
+		"$or":     walletMatch,
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	labels := []entities.TransactionLabel{}
+	if err = cursor.All(ctx, &labels); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+func (r *TransactionRepository) SaveManualLabel(label *entities.TransactionLabel) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	label.UpdatedAt = time.Now()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.labelCollection)
+
+	filter := bson.M{
+		"user_id":    label.UserID,
+		"blockchain": label.Blockchain,
+		"address":    label.Address,
+		"hash":       label.Hash,
+	}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := collection.ReplaceOne(ctx, filter, label, opts)
+	return err
+}
+
+func (r *TransactionRepository) DeleteManualLabel(userID, blockchain, address, hash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.labelCollection)
+
+	_, err := collection.DeleteOne(ctx, bson.M{
+		"user_id":    userID,
+		"blockchain": blockchain,
+		"address":    address,
+		"hash":       hash,
+	})
+	return err
+}
@@ -14,10 +14,15 @@ import (
 
 type IWalletRepository interface {
 	SaveWallet(wallet *entities.Wallet) error
+	UpdateWalletBalances(wallet *entities.Wallet) (bool, error)
+	SetWalletVerified(userID, blockchain, address string, verifiedAt time.Time) (bool, error)
 	GetWallet(userID, blockchain, address string) (*entities.Wallet, error)
+	DeleteWallet(userID, blockchain, address string) (bool, error)
 	GetAllAddresses() ([]string, error)
 	GetAllAddressesByUser(userID string) ([]string, error)
 	GetAllWallets() ([]entities.Wallet, error)
+	GetWalletsByUser(userID string) ([]entities.Wallet, error)
+	GetUsersByWallet(blockchain, address string) ([]string, error)
 }
 
 type WalletRepository struct {
@@ -54,6 +59,56 @@ func (r *WalletRepository) SaveWallet(wallet *entities.Wallet) error {
 	return err
 }
 
+// UpdateWalletBalances stores refreshed balances on an existing wallet, leaving the fields the user
+// edits untouched. It reports false when the wallet no longer exists; it is never recreated.
+func (r *WalletRepository) UpdateWalletBalances(wallet *entities.Wallet) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	wallet.LastUpdated = time.Now()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"user_id":    wallet.UserID,
+		"blockchain": wallet.Blockchain,
+		"address":    wallet.Address,
+	}
+	update := bson.M{"$set": bson.M{
+		"balances":    wallet.Balances,
+		"balance":     wallet.Balance,
+		"lastUpdated": wallet.LastUpdated,
+	}}
+
+	res, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// SetWalletVerified marks a wallet as verified without touching its other fields.
+// It reports false when the wallet does not exist.
+func (r *WalletRepository) SetWalletVerified(userID, blockchain, address string, verifiedAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"user_id":    userID,
+		"blockchain": blockchain,
+		"address":    address,
+	}
+	update := bson.M{"$set": bson.M{"verified": true, "verifiedAt": verifiedAt}}
+
+	res, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
 func (r *WalletRepository) GetWallet(userID, blockchain, address string) (*entities.Wallet, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -78,6 +133,26 @@ func (r *WalletRepository) GetWallet(userID, blockchain, address string) (*entit
 	return &wallet, nil
 }
 
+// DeleteWallet removes a tracked wallet and reports whether it existed
+func (r *WalletRepository) DeleteWallet(userID, blockchain, address string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"user_id":    userID,
+		"blockchain": blockchain,
+		"address":    address,
+	}
+
+	res, err := collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
 func (r *WalletRepository) GetAllAddresses() ([]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -137,13 +212,15 @@ func (r *WalletRepository) GetAllAddressesByUser(userID string) ([]string, error
 	return results[0]["addresses"], nil
 }
 
+// GetAllWallets returns every tracked wallet, least recently updated first, so a run cut short
+// by its deadline picks up where the previous one stopped
 func (r *WalletRepository) GetAllWallets() ([]entities.Wallet, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
 
-	opts := options.Find().SetSort(bson.D{{Key: "lastUpdated", Value: -1}})
+	opts := options.Find().SetSort(bson.D{{Key: "lastUpdated", Value: 1}})
 
 	cursor, err := collection.Find(ctx, bson.M{}, opts)
 	if err != nil {
@@ -158,3 +235,46 @@ func (r *WalletRepository) GetAllWallets() ([]entities.Wallet, error) {
 
 	return wallets, nil
 }
+
+func (r *WalletRepository) GetWalletsByUser(userID string) ([]entities.Wallet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var wallets []entities.Wallet
+	if err = cursor.All(ctx, &wallets); err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+// GetUsersByWallet returns the IDs of the users tracking a wallet
+func (r *WalletRepository) GetUsersByWallet(blockchain, address string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	values, err := collection.Distinct(ctx, "user_id", bson.M{"blockchain": blockchain, "address": address})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]string, 0, len(values))
+	for _, v := range values {
+		if userID, ok := v.(string); ok {
+			users = append(users, userID)
+		}
+	}
+	return users, nil
+}
@@ -0,0 +1,372 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/database/dbmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// deliveredRetention is how long delivered webhooks are kept for inspection
+	deliveredRetention = 7 * 24 * time.Hour
+	// deadLetterRetention is how long dead deliveries can be replayed
+	deadLetterRetention = 30 * 24 * time.Hour
+)
+
+type IWebhookRepository interface {
+	SaveSubscription(sub *entities.WebhookSubscription) error
+	GetSubscription(userID string, id primitive.ObjectID) (*entities.WebhookSubscription, error)
+	GetSubscriptionByID(id primitive.ObjectID) (*entities.WebhookSubscription, error)
+	GetSubscriptionsByUser(userID string) ([]entities.WebhookSubscription, error)
+	GetEnabledSubscriptionsByUsers(userIDs []string) ([]entities.WebhookSubscription, error)
+	CountSubscriptionsByUser(userID string) (int64, error)
+	DeleteSubscription(userID string, id primitive.ObjectID) (bool, error)
+
+	EnqueueDeliveries(deliveries []entities.WebhookDelivery) error
+	ClaimDueDelivery(now time.Time, lease time.Duration) (*entities.WebhookDelivery, error)
+	MarkDelivered(id primitive.ObjectID, attempts, statusCode int, at time.Time) error
+	ScheduleRetry(id primitive.ObjectID, attempts, statusCode int, lastError string, next time.Time) error
+	DeleteDelivery(id primitive.ObjectID) error
+
+	MoveToDeadLetters(delivery *entities.WebhookDelivery) error
+	GetDeadLetters(userID string, limit int64) ([]entities.WebhookDelivery, error)
+	GetDeadLetter(userID string, id primitive.ObjectID) (*entities.WebhookDelivery, error)
+	DeleteDeadLetter(userID string, id primitive.ObjectID) (bool, error)
+}
+
+// WebhookRepository stores webhook subscriptions, the queue of pending deliveries and the
+// dead-letter store of deliveries that ran out of attempts
+type WebhookRepository struct {
+	mongoClient          *dbmongo.MongoClient
+	dbName               string
+	collection           string
+	deliveryCollection   string
+	deadLetterCollection string
+}
+
+func NewWebhookRepository(mongoClient *dbmongo.MongoClient, dbName string) *WebhookRepository {
+	return &WebhookRepository{
+		mongoClient:          mongoClient,
+		dbName:               dbName,
+		collection:           "webhook_subscriptions",
+		deliveryCollection:   "webhook_deliveries",
+		deadLetterCollection: "webhook_dead_letters",
+	}
+}
+
+// EnsureIndexes creates the subscription index, the queue index deliveries are claimed by and the
+// TTL indexes that expire delivered webhooks and old dead letters
+func (r *WebhookRepository) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := r.mongoClient.Client.Database(r.dbName)
+
+	_, err := db.Collection(r.collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "createdAt", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(r.deliveryCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "deliveredAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(deliveredRetention / time.Second)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(r.deadLetterCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "deadAt", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "deadAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(deadLetterRetention / time.Second)),
+		},
+	})
+	return err
+}
+
+// SaveSubscription inserts a new subscription or replaces an existing one of the same user
+func (r *WebhookRepository) SaveSubscription(sub *entities.WebhookSubscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sub.UpdatedAt = time.Now()
+	if sub.ID.IsZero() {
+		sub.ID = primitive.NewObjectID()
+	}
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	filter := bson.M{
+		"_id":     sub.ID,
+		"user_id": sub.UserID,
+	}
+
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := collection.ReplaceOne(ctx, filter, sub, opts)
+	return err
+}
+
+func (r *WebhookRepository) GetSubscription(userID string, id primitive.ObjectID) (*entities.WebhookSubscription, error) {
+	return r.findSubscription(bson.M{"_id": id, "user_id": userID})
+}
+
+// GetSubscriptionByID reads a subscription regardless of its owner, for the delivery worker
+func (r *WebhookRepository) GetSubscriptionByID(id primitive.ObjectID) (*entities.WebhookSubscription, error) {
+	return r.findSubscription(bson.M{"_id": id})
+}
+
+func (r *WebhookRepository) findSubscription(filter bson.M) (*entities.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	var sub entities.WebhookSubscription
+	err := collection.FindOne(ctx, filter).Decode(&sub)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+func (r *WebhookRepository) GetSubscriptionsByUser(userID string) ([]entities.WebhookSubscription, error) {
+	return r.findSubscriptions(bson.M{"user_id": userID})
+}
+
+// GetEnabledSubscriptionsByUsers returns the enabled subscriptions of any of the users
+func (r *WebhookRepository) GetEnabledSubscriptionsByUsers(userIDs []string) ([]entities.WebhookSubscription, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return r.findSubscriptions(bson.M{"user_id": bson.M{"$in": userIDs}, "enabled": true})
+}
+
+func (r *WebhookRepository) findSubscriptions(filter bson.M) ([]entities.WebhookSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	subs := []entities.WebhookSubscription{}
+	if err = cursor.All(ctx, &subs); err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+func (r *WebhookRepository) CountSubscriptionsByUser(userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+	return collection.CountDocuments(ctx, bson.M{"user_id": userID})
+}
+
+// DeleteSubscription removes a subscription and reports whether it existed. Its pending
+// deliveries are dropped by the worker when they come due.
+func (r *WebhookRepository) DeleteSubscription(userID string, id primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.collection)
+
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// EnqueueDeliveries adds deliveries to the queue
+func (r *WebhookRepository) EnqueueDeliveries(deliveries []entities.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deliveryCollection)
+
+	docs := make([]interface{}, len(deliveries))
+	for i := range deliveries {
+		if deliveries[i].ID.IsZero() {
+			deliveries[i].ID = primitive.NewObjectID()
+		}
+		docs[i] = deliveries[i]
+	}
+
+	_, err := collection.InsertMany(ctx, docs)
+	return err
+}
+
+// ClaimDueDelivery takes the oldest pending delivery that is due and pushes its next attempt back
+// by lease, so no other worker picks it up while it is being sent. It returns nil when none is due.
+func (r *WebhookRepository) ClaimDueDelivery(now time.Time, lease time.Duration) (*entities.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deliveryCollection)
+
+	filter := bson.M{
+		"status":        entities.DeliveryPending,
+		"nextAttemptAt": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"nextAttemptAt": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var delivery entities.WebhookDelivery
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&delivery)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(id primitive.ObjectID, attempts, statusCode int, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deliveryCollection)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"status":         entities.DeliveryDelivered,
+			"attempts":       attempts,
+			"lastStatusCode": statusCode,
+			"deliveredAt":    at,
+		},
+		"$unset": bson.M{"lastError": ""},
+	})
+	return err
+}
+
+func (r *WebhookRepository) ScheduleRetry(id primitive.ObjectID, attempts, statusCode int, lastError string, next time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deliveryCollection)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"attempts":       attempts,
+		"lastStatusCode": statusCode,
+		"lastError":      lastError,
+		"nextAttemptAt":  next,
+	}})
+	return err
+}
+
+func (r *WebhookRepository) DeleteDelivery(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deliveryCollection)
+
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// MoveToDeadLetters stores a delivery in the dead-letter store and removes it from the queue
+func (r *WebhookRepository) MoveToDeadLetters(delivery *entities.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	deadLetters := r.mongoClient.Client.Database(r.dbName).Collection(r.deadLetterCollection)
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := deadLetters.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery, opts); err != nil {
+		return err
+	}
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deliveryCollection)
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": delivery.ID})
+	return err
+}
+
+// GetDeadLetters returns the user's dead deliveries, most recent first
+func (r *WebhookRepository) GetDeadLetters(userID string, limit int64) ([]entities.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deadLetterCollection)
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deadAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []entities.WebhookDelivery{}
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) GetDeadLetter(userID string, id primitive.ObjectID) (*entities.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deadLetterCollection)
+
+	var delivery entities.WebhookDelivery
+	err := collection.FindOne(ctx, bson.M{"_id": id, "user_id": userID}).Decode(&delivery)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *WebhookRepository) DeleteDeadLetter(userID string, id primitive.ObjectID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := r.mongoClient.Client.Database(r.dbName).Collection(r.deadLetterCollection)
+
+	res, err := collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
@@ -0,0 +1,112 @@
+package secp256k1
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Curve parameters
+var (
+	P, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	N, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	Gx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	Gy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+
+	// HalfN bounds the s value of a canonical (low-s) signature
+	HalfN = new(big.Int).Rsh(N, 1)
+)
+
+// CompressPoint encodes an affine point as a 33-byte compressed public key
+func CompressPoint(x, y *big.Int) []byte {
+	out := make([]byte, 33)
+	out[0] = 0x02 + byte(y.Bit(0))
+	x.FillBytes(out[1:])
+	return out
+}
+
+// DecompressPoint decodes a 33-byte compressed public key
+func DecompressPoint(pub []byte) (*big.Int, *big.Int, error) {
+	if len(pub) != 33 || (pub[0] != 0x02 && pub[0] != 0x03) {
+		return nil, nil, fmt.Errorf("invalid compressed public key")
+	}
+	x := new(big.Int).SetBytes(pub[1:])
+	y, err := YFromX(x, uint(pub[0]&1))
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, y, nil
+}
+
+// YFromX returns the y coordinate with the given parity for x, or an error when x is not on the curve
+func YFromX(x *big.Int, odd uint) (*big.Int, error) {
+	if x.Sign() < 0 || x.Cmp(P) >= 0 {
+		return nil, fmt.Errorf("invalid x coordinate")
+	}
+
+	// y^2 = x^3 + 7; p = 3 mod 4 so sqrt is a single exponentiation
+	y2 := new(big.Int).Exp(x, big.NewInt(3), P)
+	y2.Add(y2, big.NewInt(7)).Mod(y2, P)
+	exp := new(big.Int).Add(P, big.NewInt(1))
+	exp.Rsh(exp, 2)
+	y := new(big.Int).Exp(y2, exp, P)
+	if new(big.Int).Exp(y, big.NewInt(2), P).Cmp(y2) != 0 {
+		return nil, fmt.Errorf("point is not on secp256k1")
+	}
+	if y.Bit(0) != odd {
+		y.Sub(P, y)
+	}
+	return y, nil
+}
+
+// Add adds two affine points; nil represents the point at infinity
+func Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if x1 == nil {
+		return x2, y2
+	}
+	if x2 == nil {
+		return x1, y1
+	}
+
+	var lambda *big.Int
+	if x1.Cmp(x2) == 0 {
+		if y1.Cmp(y2) != 0 || y1.Sign() == 0 {
+			return nil, nil
+		}
+		// lambda = 3x^2 / 2y
+		num := new(big.Int).Mul(x1, x1)
+		num.Mul(num, big.NewInt(3))
+		den := new(big.Int).Lsh(y1, 1)
+		lambda = num.Mul(num, den.ModInverse(den, P))
+	} else {
+		// lambda = (y2 - y1) / (x2 - x1)
+		num := new(big.Int).Sub(y2, y1)
+		den := new(big.Int).Sub(x2, x1)
+		den.Mod(den, P)
+		lambda = num.Mul(num, den.ModInverse(den, P))
+	}
+	lambda.Mod(lambda, P)
+
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, x1).Sub(x3, x2).Mod(x3, P)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, lambda).Sub(y3, y1).Mod(y3, P)
+	return x3, y3
+}
+
+// ScalarMult computes k*(x, y) with double-and-add
+func ScalarMult(x, y, k *big.Int) (*big.Int, *big.Int) {
+	var rx, ry *big.Int
+	ax, ay := new(big.Int).Set(x), new(big.Int).Set(y)
+	for i := 0; i < k.BitLen(); i++ {
+		if k.Bit(i) == 1 {
+			rx, ry = Add(rx, ry, ax, ay)
+		}
+		ax, ay = Add(ax, ay, ax, ay)
+	}
+	return rx, ry
+}
+
+// ScalarBaseMult computes k*G
+func ScalarBaseMult(k *big.Int) (*big.Int, *big.Int) {
+	return ScalarMult(Gx, Gy, k)
+}
@@ -0,0 +1,86 @@
+package secp256k1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func hexInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("bad hex " + s)
+	}
+	return n
+}
+
+func TestScalarBaseMult(t *testing.T) {
+	tests := []struct {
+		k    string
+		x, y string
+	}{
+		{"1", "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"},
+		{"2", "c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5", "1ae168fea63dc339a3c58419466ceaeef7f632653266d0e1236431a950cfe52a"},
+		{"3", "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9", "388f7b0f632de8140fe337e62a37f3566500a99934c2231b6cb9fd7584b8e672"},
+		// N-1 is -1, the negation of G
+		{"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140", "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "b7c52588d95c3b9aa25b0403f1eef75702e84bb7597aabe663b82f6f04ef2777"},
+	}
+	for _, tt := range tests {
+		x, y := ScalarBaseMult(hexInt(tt.k))
+		if x == nil || x.Cmp(hexInt(tt.x)) != 0 || y.Cmp(hexInt(tt.y)) != 0 {
+			t.Errorf("%s*G = (%x, %x)", tt.k, x, y)
+		}
+	}
+
+	if x, _ := ScalarBaseMult(N); x != nil {
+		t.Error("N*G should be the point at infinity")
+	}
+	if x, _ := Add(Gx, Gy, Gx, new(big.Int).Sub(P, Gy)); x != nil {
+		t.Error("G + (-G) should be the point at infinity")
+	}
+}
+
+func TestCompressPoint(t *testing.T) {
+	x, y := ScalarBaseMult(big.NewInt(2))
+	compressed := CompressPoint(x, y)
+	want, _ := hex.DecodeString("02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5")
+	if !bytes.Equal(compressed, want) {
+		t.Fatalf("CompressPoint(2G) = %x", compressed)
+	}
+
+	dx, dy, err := DecompressPoint(compressed)
+	if err != nil || dx.Cmp(x) != 0 || dy.Cmp(y) != 0 {
+		t.Errorf("DecompressPoint round trip = (%x, %x), %v", dx, dy, err)
+	}
+
+	bad := append([]byte{0x04}, compressed[1:]...)
+	if _, _, err := DecompressPoint(bad); err == nil {
+		t.Error("an uncompressed prefix should fail")
+	}
+	if _, _, err := DecompressPoint(compressed[:32]); err == nil {
+		t.Error("a truncated key should fail")
+	}
+}
+
+func TestYFromX(t *testing.T) {
+	if _, err := YFromX(P, 0); err == nil {
+		t.Error("x = P should fail")
+	}
+	// Roughly half of all x have no point on the curve
+	found := false
+	for x := int64(1); x < 20; x++ {
+		if _, err := YFromX(big.NewInt(x), 0); err != nil {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("no x below 20 was rejected as off the curve")
+	}
+
+	y, err := YFromX(Gx, 1)
+	if err != nil || y.Bit(0) != 1 || new(big.Int).Add(y, Gy).Cmp(P) != 0 {
+		t.Errorf("odd y of Gx = %x, %v", y, err)
+	}
+}
@@ -0,0 +1,28 @@
+package security
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewAdminMiddleware only lets through users whose address is among adminAddresses. It runs
+// after the JWT middleware, which sets the user; with no admin addresses every request is refused.
+func NewAdminMiddleware(adminAddresses []string) fiber.Handler {
+	admins := make(map[string]bool, len(adminAddresses))
+	for _, addr := range adminAddresses {
+		admins[strings.ToLower(addr)] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		userData, ok := c.Locals("user").(map[string]interface{})
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "user not found"})
+		}
+		userAddr, _ := userData["address"].(string)
+		if !admins[strings.ToLower(userAddr)] {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Admin access required"})
+		}
+		return c.Next()
+	}
+}
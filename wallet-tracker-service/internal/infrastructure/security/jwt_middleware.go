@@ -6,15 +6,45 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
 )
 
-// NewJWTMiddleware creates a middleware for JWT validation
-func NewJWTMiddleware(authServiceURL string) fiber.Handler {
+// StreamTicketRedeemer spends a single-use stream ticket, returning nil when it is not valid
+type StreamTicketRedeemer interface {
+	RedeemTicket(ticket string) (*entities.StreamTicket, error)
+}
+
+// NewJWTMiddleware creates a middleware for JWT validation. The user it sets carries the token's
+// expiry as "expiresAt" when the auth service reports one.
+func NewJWTMiddleware(authServiceURL string, tickets StreamTicketRedeemer) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get the Authorization header
 		authHeader := c.Get("Authorization")
+
+		// EventSource cannot set headers, so event streams authenticate with a stream ticket.
+		// Bearer tokens are never read from the URL, where proxies and access logs keep them.
+		if authHeader == "" && strings.Contains(c.Get("Accept"), "text/event-stream") && c.Query("ticket") != "" {
+			ticket, err := tickets.RedeemTicket(c.Query("ticket"))
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to check stream ticket",
+				})
+			}
+			if ticket == nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Invalid or expired stream ticket",
+				})
+			}
+			user := map[string]interface{}{"address": ticket.UserID}
+			if ticket.TokenExpiresAt != nil {
+				user["expiresAt"] = *ticket.TokenExpiresAt
+			}
+			c.Locals("user", user)
+			return c.Next()
+		}
 		
 		// Check if Authorization header exists and has the Bearer scheme
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
@@ -82,9 +112,13 @@ func NewJWTMiddleware(authServiceURL string) fiber.Handler {
 		// If the token is valid, set the user data in the context
 		// The address is in the payload
 		if address, ok := authResponse.Payload["address"].(string); ok {
-			c.Locals("user", map[string]interface{}{
+			user := map[string]interface{}{
 				"address": address,
-			})
+			}
+			if exp, ok := authResponse.Payload["exp"].(float64); ok {
+				user["expiresAt"] = time.Unix(int64(exp), 0).UTC()
+			}
+			c.Locals("user", user)
 		}
 		
 		// Continue to the next middleware/handler
@@ -0,0 +1,81 @@
+package security
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+)
+
+type fakeTickets map[string]*entities.StreamTicket
+
+func (f fakeTickets) RedeemTicket(ticket string) (*entities.StreamTicket, error) {
+	t := f[ticket]
+	delete(f, ticket)
+	return t, nil
+}
+
+func TestJWTMiddleware(t *testing.T) {
+	exp := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	validated := 0
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validated++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"isValid": true,
+			"payload": map[string]interface{}{"address": "0xuser", "exp": exp.Unix()},
+		})
+	}))
+	defer auth.Close()
+
+	tickets := fakeTickets{"t1": {UserID: "0xstreamer", TokenExpiresAt: &exp}}
+	app := fiber.New()
+	app.Use(NewJWTMiddleware(auth.URL, tickets))
+	app.Get("/stream", func(c *fiber.Ctx) error {
+		user := c.Locals("user").(map[string]interface{})
+		expiresAt, _ := user["expiresAt"].(time.Time)
+		return c.SendString(user["address"].(string) + " " + expiresAt.Format(time.RFC3339))
+	})
+
+	tests := []struct {
+		name          string
+		target        string
+		header        string
+		wantStatus    int
+		wantBody      string
+		wantValidated int
+	}{
+		{"bearer header", "/stream", "Bearer abc", http.StatusOK, "0xuser 2030-01-01T00:00:00Z", 1},
+		{"token in the query is refused", "/stream?access_token=abc", "", http.StatusUnauthorized, "", 0},
+		{"stream ticket", "/stream?ticket=t1", "", http.StatusOK, "0xstreamer 2030-01-01T00:00:00Z", 0},
+		{"spent stream ticket", "/stream?ticket=t1", "", http.StatusUnauthorized, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validated = 0
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Accept", "text/event-stream")
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", resp.StatusCode, body, tt.wantStatus)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("user = %s, want %s", body, tt.wantBody)
+			}
+			if validated != tt.wantValidated {
+				t.Errorf("auth service called %d times, want %d", validated, tt.wantValidated)
+			}
+		})
+	}
+}
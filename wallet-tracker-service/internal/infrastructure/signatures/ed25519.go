@@ -0,0 +1,23 @@
+package signatures
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+)
+
+// VerifyEd25519 checks a signature over message by a base58 ed25519 public key, such as a Solana address
+func VerifyEd25519(address, message string, signature []byte) error {
+	pub, err := addresses.Base58Decode(address)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid ed25519 public key: %s", address)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("signature must be %d bytes, got %d", ed25519.SignatureSize, len(signature))
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), []byte(message), signature) {
+		return fmt.Errorf("signature does not match %s", address)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package signatures
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+)
+
+// RFC 8032 section 7.1, test 2: a one-byte message signed by a key shown as a Solana address
+var (
+	testEd25519Public    = "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c"
+	testEd25519Message   = "\x72"
+	testEd25519Signature = "92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00"
+)
+
+func TestVerifyEd25519(t *testing.T) {
+	pub, _ := hex.DecodeString(testEd25519Public)
+	address := addresses.Base58Encode(pub)
+	sig, _ := hex.DecodeString(testEd25519Signature)
+
+	if err := VerifyEd25519(address, testEd25519Message, sig); err != nil {
+		t.Fatalf("VerifyEd25519 of the RFC 8032 vector: %v", err)
+	}
+	// Wallets often return the signature in base58
+	decoded, err := DecodeSignature(addresses.Base58Encode(sig))
+	if err != nil || VerifyEd25519(address, testEd25519Message, decoded) != nil {
+		t.Errorf("base58 signature did not verify: %v", err)
+	}
+
+	flipped := append([]byte(nil), sig...)
+	flipped[0] ^= 1
+	other := append([]byte(nil), pub...)
+	other[31] ^= 1
+
+	tests := []struct {
+		name    string
+		address string
+		message string
+		sig     []byte
+	}{
+		{"wrong message", address, "\x73", sig},
+		{"altered signature", address, testEd25519Message, flipped},
+		{"truncated signature", address, testEd25519Message, sig[:63]},
+		{"other key", addresses.Base58Encode(other), testEd25519Message, sig},
+		{"not a public key", "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", testEd25519Message, sig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyEd25519(tt.address, tt.message, tt.sig); err == nil {
+				t.Error("VerifyEd25519 accepted the signature")
+			}
+		})
+	}
+}
+
+func TestDecodeSignature(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantLen int
+		wantErr bool
+	}{
+		{in: testEVMSignature, wantLen: 65},
+		{in: " 0X" + testEVMSignature[2:] + " ", wantLen: 65},
+		{in: "AQID", wantLen: 3}, // base64
+		{in: "", wantErr: true},
+		{in: "0xzz", wantErr: true},
+		{in: "not a signature!", wantErr: true},
+	}
+	for _, tt := range tests {
+		sig, err := DecodeSignature(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("DecodeSignature(%q) should fail", tt.in)
+			}
+			continue
+		}
+		if err != nil || len(sig) != tt.wantLen {
+			t.Errorf("DecodeSignature(%q) = %d bytes, %v; want %d bytes", tt.in, len(sig), err, tt.wantLen)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package signatures
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+)
+
+// DecodeSignature accepts the encodings wallets commonly return:
+// 0x-prefixed hex (EVM), base58 (Solana) or base64
+func DecodeSignature(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty signature")
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		sig, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex signature: %w", err)
+		}
+		return sig, nil
+	}
+	// A base64 string can also be valid base58, so only accept base58 of a signature's length
+	if sig, err := addresses.Base58Decode(s); err == nil && (len(sig) == 64 || len(sig) == 65) {
+		return sig, nil
+	}
+	if sig, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("signature must be 0x-hex, base58 or base64")
+}
@@ -0,0 +1,83 @@
+package signatures
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/addresses"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/secp256k1"
+	"golang.org/x/crypto/sha3"
+)
+
+// EIP191Hash hashes a message the way personal_sign does:
+// keccak256("\x19Ethereum Signed Message:\n" + len(message) + message)
+func EIP191Hash(message string) []byte {
+	h := sha3.NewLegacyKeccak256()
+	fmt.Fprintf(h, "\x19Ethereum Signed Message:\n%d%s", len(message), message)
+	return h.Sum(nil)
+}
+
+// RecoverEIP191Address returns the EIP-55 address that produced a 65-byte personal_sign signature
+func RecoverEIP191Address(message string, signature []byte) (string, error) {
+	if len(signature) != 65 {
+		return "", fmt.Errorf("signature must be 65 bytes, got %d", len(signature))
+	}
+
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	v := signature[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return "", fmt.Errorf("invalid signature recovery id")
+	}
+	if r.Sign() == 0 || r.Cmp(secp256k1.N) >= 0 || s.Sign() == 0 || s.Cmp(secp256k1.N) >= 0 {
+		return "", fmt.Errorf("invalid signature values")
+	}
+	// (r, N-s) with the flipped recovery id recovers the same address, so only the low s of EIP-2 is accepted
+	if s.Cmp(secp256k1.HalfN) > 0 {
+		return "", fmt.Errorf("invalid signature: s is in the upper half of the curve order")
+	}
+
+	// R is the nonce point; its y parity is the recovery id
+	ry, err := secp256k1.YFromX(r, uint(v))
+	if err != nil {
+		return "", fmt.Errorf("invalid signature: %w", err)
+	}
+
+	// Q = r^-1 (s*R - e*G) = (-e/r)*G + (s/r)*R
+	e := new(big.Int).SetBytes(EIP191Hash(message))
+	rInv := new(big.Int).ModInverse(r, secp256k1.N)
+	u1 := new(big.Int).Neg(e)
+	u1.Mul(u1, rInv).Mod(u1, secp256k1.N)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, secp256k1.N)
+
+	x1, y1 := secp256k1.ScalarBaseMult(u1)
+	x2, y2 := secp256k1.ScalarMult(r, ry, u2)
+	qx, qy := secp256k1.Add(x1, y1, x2, y2)
+	if qx == nil {
+		return "", fmt.Errorf("invalid signature: recovered point at infinity")
+	}
+
+	pub := make([]byte, 64)
+	qx.FillBytes(pub[:32])
+	qy.FillBytes(pub[32:])
+	h := sha3.NewLegacyKeccak256()
+	h.Write(pub)
+	return addresses.ToChecksumAddress(fmt.Sprintf("%x", h.Sum(nil)[12:])), nil
+}
+
+// VerifyEIP191 checks that signature is a personal_sign of message by address
+func VerifyEIP191(address, message string, signature []byte) error {
+	recovered, err := RecoverEIP191Address(message, signature)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(recovered, address) {
+		return fmt.Errorf("signature was made by %s, not %s", recovered, address)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package signatures
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/secp256k1"
+)
+
+// The web3.js accounts.sign("Some data") example, signed with key 0x4c0883a6...f362318
+const (
+	testEVMMessage   = "Some data"
+	testEVMAddress   = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	testEVMSignature = "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+)
+
+func testEVMSig(t *testing.T) []byte {
+	t.Helper()
+	sig, err := DecodeSignature(testEVMSignature)
+	if err != nil {
+		t.Fatalf("DecodeSignature: %v", err)
+	}
+	return sig
+}
+
+func TestEIP191Hash(t *testing.T) {
+	want := "1da44b586eb0729ff70a73c326926f6ed5a25f5b056e7f47fbc6e58d86871655"
+	if got := hex.EncodeToString(EIP191Hash(testEVMMessage)); got != want {
+		t.Errorf("EIP191Hash = %s, want %s", got, want)
+	}
+}
+
+func TestRecoverEIP191Address(t *testing.T) {
+	got, err := RecoverEIP191Address(testEVMMessage, testEVMSig(t))
+	if err != nil {
+		t.Fatalf("RecoverEIP191Address: %v", err)
+	}
+	if got != testEVMAddress {
+		t.Errorf("recovered %s, want %s", got, testEVMAddress)
+	}
+
+	// v may come as 0/1 instead of 27/28
+	sig := testEVMSig(t)
+	sig[64] -= 27
+	if got, err := RecoverEIP191Address(testEVMMessage, sig); err != nil || got != testEVMAddress {
+		t.Errorf("with v=%d recovered %s, %v", sig[64], got, err)
+	}
+}
+
+func TestVerifyEIP191(t *testing.T) {
+	if err := VerifyEIP191(strings.ToLower(testEVMAddress), testEVMMessage, testEVMSig(t)); err != nil {
+		t.Errorf("VerifyEIP191 of the valid signature: %v", err)
+	}
+
+	// s' = N - s with the other recovery id recovers the same key
+	highS := testEVMSig(t)
+	s := new(big.Int).SetBytes(highS[32:64])
+	new(big.Int).Sub(secp256k1.N, s).FillBytes(highS[32:64])
+	highS[64] = 27 + ((highS[64] - 27) ^ 1)
+
+	tests := []struct {
+		name    string
+		message string
+		sig     func() []byte
+	}{
+		{"wrong message", "Some data!", testEVMSigFunc(t, nil)},
+		{"other recovery id", testEVMMessage, testEVMSigFunc(t, func(sig []byte) []byte { sig[64] = 27 + ((sig[64] - 27) ^ 1); return sig })},
+		{"invalid recovery id", testEVMMessage, testEVMSigFunc(t, func(sig []byte) []byte { sig[64] = 29; return sig })},
+		{"truncated", testEVMMessage, testEVMSigFunc(t, func(sig []byte) []byte { return sig[:64] })},
+		{"zero r", testEVMMessage, testEVMSigFunc(t, func(sig []byte) []byte { copy(sig[:32], make([]byte, 32)); return sig })},
+		{"s not below N", testEVMMessage, testEVMSigFunc(t, func(sig []byte) []byte { secp256k1.N.FillBytes(sig[32:64]); return sig })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyEIP191(testEVMAddress, tt.message, tt.sig()); err == nil {
+				t.Error("VerifyEIP191 accepted the signature")
+			}
+		})
+	}
+
+	_, err := RecoverEIP191Address(testEVMMessage, highS)
+	if err == nil || !strings.Contains(err.Error(), "upper half") {
+		t.Errorf("high-s signature gave %v, want it rejected as high s", err)
+	}
+}
+
+func testEVMSigFunc(t *testing.T, change func([]byte) []byte) func() []byte {
+	return func() []byte {
+		sig := testEVMSig(t)
+		if change != nil {
+			sig = change(sig)
+		}
+		return sig
+	}
+}
@@ -0,0 +1,120 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/events"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+// clientBuffer is how many updates a client may lag behind before it is disconnected
+const clientBuffer = 64
+
+// EventTypes are the events streamed to clients: every stored refresh and the changes it found
+var EventTypes = []string{
+	entities.EventBalancesRefreshed,
+	entities.EventValueChanged,
+	entities.EventTokenAdded,
+	entities.EventTokenRemoved,
+	entities.EventBalanceIncreased,
+	entities.EventBalanceDecreased,
+}
+
+// Client is one open stream of a user, receiving the updates of a fixed set of wallets
+type Client struct {
+	UserID  string
+	Wallets []entities.WalletRef
+	// Updates is closed when the hub drops the client for falling behind
+	Updates chan events.Envelope
+}
+
+// Hub routes updates to the clients streaming the wallet they concern
+type Hub struct {
+	mu       sync.RWMutex
+	byWallet map[string]map[*Client]struct{}
+	logger   *logs.Logger
+}
+
+func NewHub(logger *logs.Logger) *Hub {
+	return &Hub{
+		byWallet: make(map[string]map[*Client]struct{}),
+		logger:   logger,
+	}
+}
+
+func walletKey(blockchain, address string) string {
+	return blockchain + "." + address
+}
+
+// Register opens a client for the wallets; the caller has checked the user tracks them
+func (h *Hub) Register(userID string, wallets []entities.WalletRef) *Client {
+	client := &Client{
+		UserID:  userID,
+		Wallets: wallets,
+		Updates: make(chan events.Envelope, clientBuffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, w := range wallets {
+		key := walletKey(w.Blockchain, w.Address)
+		if h.byWallet[key] == nil {
+			h.byWallet[key] = make(map[*Client]struct{})
+		}
+		h.byWallet[key][client] = struct{}{}
+	}
+	return client
+}
+
+// Unregister removes a client; it is safe to call for a client the hub already dropped
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(client)
+}
+
+func (h *Hub) remove(client *Client) bool {
+	removed := false
+	for _, w := range client.Wallets {
+		key := walletKey(w.Blockchain, w.Address)
+		if _, ok := h.byWallet[key][client]; ok {
+			delete(h.byWallet[key], client)
+			removed = true
+		}
+		if len(h.byWallet[key]) == 0 {
+			delete(h.byWallet, key)
+		}
+	}
+	return removed
+}
+
+// Dispatch hands an update to the clients of its wallet without blocking. A client whose buffer
+// is full is dropped, and reconnects to resume from the current state.
+func (h *Hub) Dispatch(envelope events.Envelope) {
+	key := walletKey(envelope.Blockchain, envelope.Address)
+
+	var lagging []*Client
+	h.mu.RLock()
+	for client := range h.byWallet[key] {
+		select {
+		case client.Updates <- envelope:
+		default:
+			lagging = append(lagging, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	if len(lagging) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, client := range lagging {
+		// Another dispatch may have dropped it in between
+		if h.remove(client) {
+			h.logger.Warnf("Stream client of user %s is %d updates behind, disconnecting it", client.UserID, clientBuffer)
+			close(client.Updates)
+		}
+	}
+}
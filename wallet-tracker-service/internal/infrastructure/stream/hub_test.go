@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/panoramablock/wallet-tracker-service/internal/domain/entities"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/events"
+	"github.com/panoramablock/wallet-tracker-service/internal/infrastructure/logs"
+)
+
+var (
+	walletA = entities.WalletRef{Blockchain: "ETH", Address: "0xaaa"}
+	walletB = entities.WalletRef{Blockchain: "BSC", Address: "0xbbb"}
+)
+
+func update(w entities.WalletRef) events.Envelope {
+	return events.Envelope{Type: entities.EventBalancesRefreshed, Blockchain: w.Blockchain, Address: w.Address, Data: []byte("{}")}
+}
+
+func TestHubRoutesByWallet(t *testing.T) {
+	hub := NewHub(logs.NewLogger())
+	both := hub.Register("user1", []entities.WalletRef{walletA, walletB})
+	onlyB := hub.Register("user2", []entities.WalletRef{walletB})
+
+	hub.Dispatch(update(walletA))
+	hub.Dispatch(update(walletB))
+	hub.Dispatch(update(entities.WalletRef{Blockchain: "ETH", Address: "0xccc"}))
+
+	if n := len(both.Updates); n != 2 {
+		t.Errorf("client of both wallets has %d updates, want 2", n)
+	}
+	if n := len(onlyB.Updates); n != 1 {
+		t.Errorf("client of one wallet has %d updates, want 1", n)
+	}
+}
+
+func TestHubUnregister(t *testing.T) {
+	hub := NewHub(logs.NewLogger())
+	client := hub.Register("user1", []entities.WalletRef{walletA, walletB})
+	other := hub.Register("user2", []entities.WalletRef{walletB})
+
+	hub.Unregister(client)
+	hub.Unregister(client)
+	hub.Dispatch(update(walletA))
+	hub.Dispatch(update(walletB))
+
+	if n := len(client.Updates); n != 0 {
+		t.Errorf("unregistered client received %d updates", n)
+	}
+	if n := len(other.Updates); n != 1 {
+		t.Errorf("remaining client has %d updates, want 1", n)
+	}
+	if _, ok := hub.byWallet[walletKey(walletA.Blockchain, walletA.Address)]; ok {
+		t.Error("a wallet without clients is still indexed")
+	}
+}
+
+func TestHubDropsLaggingClient(t *testing.T) {
+	hub := NewHub(logs.NewLogger())
+	slow := hub.Register("user1", []entities.WalletRef{walletA, walletB})
+	fast := hub.Register("user2", []entities.WalletRef{walletA})
+
+	for i := 0; i < clientBuffer; i++ {
+		hub.Dispatch(update(walletA))
+		<-fast.Updates
+	}
+	// The slow client's buffer is full: the next update drops it, on every wallet it streams
+	hub.Dispatch(update(walletA))
+	<-fast.Updates
+
+	n := 0
+	for range slow.Updates {
+		n++
+	}
+	if n != clientBuffer {
+		t.Errorf("dropped client drained %d updates before its channel closed, want %d", n, clientBuffer)
+	}
+	if _, ok := hub.byWallet[walletKey(walletB.Blockchain, walletB.Address)]; ok {
+		t.Error("dropped client is still registered on its other wallet")
+	}
+
+	// Later updates skip it, and the stream's own Unregister after the drop is harmless
+	hub.Dispatch(update(walletA))
+	hub.Unregister(slow)
+	if n := len(fast.Updates); n != 1 {
+		t.Errorf("remaining client has %d updates, want 1", n)
+	}
+}
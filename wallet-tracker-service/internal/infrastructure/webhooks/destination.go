@@ -0,0 +1,119 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+// destinationLookupTimeout bounds the DNS lookup of a webhook host when a subscription is saved
+const destinationLookupTimeout = 5 * time.Second
+
+// ErrForbiddenDestination is returned for webhook hosts that resolve to an address on our own
+// network, so subscriptions cannot be used to reach internal services
+var ErrForbiddenDestination = errors.New("webhook host resolves to a non-public address")
+
+// reservedNets are the non-public ranges the net.IP predicates do not cover
+var reservedNets = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",       // "this" network
+		"100.64.0.0/10",   // carrier-grade NAT
+		"192.0.0.0/24",    // IETF protocol assignments
+		"198.18.0.0/15",   // benchmarking
+		"240.0.0.0/4",     // reserved, and broadcast
+		"64:ff9b::/96",    // NAT64, which can reach private IPv4 ranges
+		"64:ff9b:1::/48",  // local-use NAT64
+		"2001:db8::/32",   // documentation
+		"100::/64",        // discard-only
+		"2002::/16",       // 6to4, which embeds any IPv4 address
+		"2001::/32",       // Teredo, likewise
+		"fec0::/10",       // deprecated site-local
+		"::ffff:0:0:0/96", // IPv4-translated
+	}
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// PublicIP reports whether ip may receive webhooks: loopback, private, link-local, multicast
+// and other reserved addresses may not
+func PublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolvePublic looks host up and fails unless every address it resolves to is public
+func resolvePublic(ctx context.Context, host string) ([]net.IP, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve webhook host '%s': %w", host, err)
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return nil, fmt.Errorf("%w: %s is %s", ErrForbiddenDestination, host, addr.IP)
+		}
+		ips = append(ips, addr.IP)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("webhook host '%s' has no address", host)
+	}
+	return ips, nil
+}
+
+// CheckDestination resolves the host of a webhook URL and fails unless it only has public
+// addresses. Senders check again when they connect, as DNS answers can change in between.
+func CheckDestination(endpoint *url.URL) error {
+	ctx, cancel := context.WithTimeout(context.Background(), destinationLookupTimeout)
+	defer cancel()
+	_, err := resolvePublic(ctx, endpoint.Hostname())
+	return err
+}
+
+// publicDialer connects only to public addresses. It resolves the host itself and dials the
+// address it checked, so a DNS answer that changes after the check cannot redirect the connection.
+type publicDialer struct {
+	dialer  *net.Dialer
+	resolve func(ctx context.Context, host string) ([]net.IP, error)
+}
+
+func (d *publicDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := d.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of a delivery. Receivers verify it by recomputing
+// HMAC-SHA256(secret, timestamp + "." + body) and comparing it with the signature after "sha256=",
+// and should reject timestamps too far from their clock to stop replays.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the signature header value of a body sent at timestamp (Unix seconds)
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ISender delivers webhook bodies to receivers
+type ISender interface {
+	Send(ctx context.Context, url, secret, deliveryID, eventType string, body []byte) (int, error)
+}
+
+// Sender posts signed webhook bodies over HTTP
+type Sender struct {
+	httpClient *http.Client
+}
+
+func NewSender() *Sender {
+	return newSender(resolvePublic)
+}
+
+// newSender builds a Sender that only connects to the addresses resolve returns
+func newSender(resolve func(ctx context.Context, host string) ([]net.IP, error)) *Sender {
+	dialer := &publicDialer{dialer: &net.Dialer{Timeout: 5 * time.Second}, resolve: resolve}
+	return &Sender{
+		httpClient: &http.Client{
+			// No proxy: it would connect on our behalf and skip the address check
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				ForceAttemptHTTP2:   true,
+				TLSHandshakeTimeout: 5 * time.Second,
+				MaxIdleConns:        20,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			Timeout: 10 * time.Second,
+			// A redirect would turn the POST into a GET; count it as a failed delivery instead
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts body to url, signed with secret at the current time. It returns the response
+// status, and an error unless the receiver answered 2xx. Only https URLs on public addresses
+// are delivered to.
+func (s *Sender) Send(ctx context.Context, url, secret, deliveryID, eventType string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	if req.URL.Scheme != "https" {
+		return 0, fmt.Errorf("webhook url must use https, got '%s'", req.URL.Scheme)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wallet-tracker-webhooks/1.0")
+	req.Header.Set(HeaderID, deliveryID)
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,112 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false}, // cloud metadata
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"255.255.255.255", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:93.184.216.34", true},
+		{"64:ff9b::a00:1", false},
+	}
+	for _, tt := range tests {
+		if got := PublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("PublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDestinationRejectsInternalHosts(t *testing.T) {
+	for _, raw := range []string{"https://127.0.0.1/hook", "https://[::1]:8443/hook", "https://localhost/hook"} {
+		u, _ := url.Parse(raw)
+		if err := CheckDestination(u); !errors.Is(err, ErrForbiddenDestination) {
+			t.Errorf("CheckDestination(%s) = %v, want ErrForbiddenDestination", raw, err)
+		}
+	}
+}
+
+func TestSenderRefusesInternalAddresses(t *testing.T) {
+	hit := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	_, err := NewSender().Send(context.Background(), server.URL, "secret", "d1", "transaction", []byte("{}"))
+	if !errors.Is(err, ErrForbiddenDestination) {
+		t.Errorf("Send to %s = %v, want ErrForbiddenDestination", server.URL, err)
+	}
+	if _, err := NewSender().Send(context.Background(), "http://93.184.216.34/hook", "secret", "d1", "transaction", []byte("{}")); err == nil {
+		t.Error("Send over plain http should fail")
+	}
+	if hit {
+		t.Error("the internal server received a delivery")
+	}
+}
+
+func TestSenderDialsResolvedAddress(t *testing.T) {
+	body := []byte(`{"event":"transaction"}`)
+	var got *http.Request
+	var gotBody []byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	// The resolver stands in for DNS and the address check: the host only reaches the server
+	// through the address it returns
+	var resolved []string
+	s := newSender(func(ctx context.Context, host string) ([]net.IP, error) {
+		resolved = append(resolved, host)
+		return []net.IP{net.ParseIP("127.0.0.1")}, nil
+	})
+	s.httpClient.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	status, err := s.Send(context.Background(), "https://example.com:"+port+"/hook", "secret", "d1", "transaction", body)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("Send = %d, %v", status, err)
+	}
+	if len(resolved) != 1 || resolved[0] != "example.com" {
+		t.Errorf("resolved %v, want [example.com]", resolved)
+	}
+	if got.Host != "example.com:"+port || got.Header.Get(HeaderID) != "d1" || got.Header.Get(HeaderEvent) != "transaction" {
+		t.Errorf("request host %s, headers %v", got.Host, got.Header)
+	}
+	if string(gotBody) != string(body) {
+		t.Errorf("body = %s", gotBody)
+	}
+	ts, err := strconv.ParseInt(got.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp: %v", err)
+	}
+	if want := Sign("secret", ts, body); got.Header.Get(HeaderSignature) != want {
+		t.Errorf("signature = %s, want %s", got.Header.Get(HeaderSignature), want)
+	}
+}